@@ -27,8 +27,8 @@ func main() {
 			OrderNumber:       "ORD-SEED-001",
 			CustomerEmail:     "mario.rossi@example.com",
 			CustomerName:      "Mario Rossi",
-			Subtotal:          150.00,
-			Total:             150.00,
+			Subtotal:          models.NewMoney(15000, "EUR"),
+			Total:             models.NewMoney(15000, "EUR"),
 			PaymentStatus:     models.PaymentStatusPaid,
 			FulfillmentStatus: models.FulfillmentStatusFulfilled,
 			CreatedAt:         time.Now().Add(-48 * time.Hour),
@@ -36,14 +36,14 @@ func main() {
 				{
 					ProductName: "Quadro Leon",
 					Quantity:    1,
-					UnitPrice:   100.00,
-					TotalPrice:  100.00,
+					UnitPrice:   models.NewMoney(10000, "EUR"),
+					TotalPrice:  models.NewMoney(10000, "EUR"),
 				},
 				{
 					ProductName: "Stampa Giullare",
 					Quantity:    2,
-					UnitPrice:   25.00,
-					TotalPrice:  50.00,
+					UnitPrice:   models.NewMoney(2500, "EUR"),
+					TotalPrice:  models.NewMoney(5000, "EUR"),
 				},
 			},
 		},
@@ -51,8 +51,8 @@ func main() {
 			OrderNumber:       "ORD-SEED-002",
 			CustomerEmail:     "lucia.bianchi@example.com",
 			CustomerName:      "Lucia Bianchi",
-			Subtotal:          89.99,
-			Total:             89.99,
+			Subtotal:          models.NewMoney(8999, "EUR"),
+			Total:             models.NewMoney(8999, "EUR"),
 			PaymentStatus:     models.PaymentStatusPending,
 			FulfillmentStatus: models.FulfillmentStatusUnfulfilled,
 			CreatedAt:         time.Now().Add(-24 * time.Hour),
@@ -60,8 +60,8 @@ func main() {
 				{
 					ProductName: "Poster Ribelle",
 					Quantity:    1,
-					UnitPrice:   89.99,
-					TotalPrice:  89.99,
+					UnitPrice:   models.NewMoney(8999, "EUR"),
+					TotalPrice:  models.NewMoney(8999, "EUR"),
 				},
 			},
 		},
@@ -69,8 +69,8 @@ func main() {
 			OrderNumber:       "ORD-SEED-003",
 			CustomerEmail:     "giovanni.verdi@example.com",
 			CustomerName:      "Giovanni Verdi",
-			Subtotal:          275.50,
-			Total:             275.50,
+			Subtotal:          models.NewMoney(27550, "EUR"),
+			Total:             models.NewMoney(27550, "EUR"),
 			PaymentStatus:     models.PaymentStatusPaid,
 			FulfillmentStatus: models.FulfillmentStatusUnfulfilled,
 			CreatedAt:         time.Now().Add(-12 * time.Hour),
@@ -78,14 +78,14 @@ func main() {
 				{
 					ProductName: "Opera Polemico",
 					Quantity:    1,
-					UnitPrice:   200.00,
-					TotalPrice:  200.00,
+					UnitPrice:   models.NewMoney(20000, "EUR"),
+					TotalPrice:  models.NewMoney(20000, "EUR"),
 				},
 				{
 					ProductName: "Stampa Leon",
 					Quantity:    3,
-					UnitPrice:   25.17,
-					TotalPrice:  75.51,
+					UnitPrice:   models.NewMoney(2517, "EUR"),
+					TotalPrice:  models.NewMoney(7551, "EUR"),
 				},
 			},
 		},
@@ -93,8 +93,8 @@ func main() {
 			OrderNumber:       "ORD-SEED-004",
 			CustomerEmail:     "anna.neri@example.com",
 			CustomerName:      "Anna Neri",
-			Subtotal:          450.00,
-			Total:             450.00,
+			Subtotal:          models.NewMoney(45000, "EUR"),
+			Total:             models.NewMoney(45000, "EUR"),
 			PaymentStatus:     models.PaymentStatusPaid,
 			FulfillmentStatus: models.FulfillmentStatusFulfilled,
 			CreatedAt:         time.Now().Add(-72 * time.Hour),
@@ -102,8 +102,8 @@ func main() {
 				{
 					ProductName: "Collezione Completa",
 					Quantity:    1,
-					UnitPrice:   450.00,
-					TotalPrice:  450.00,
+					UnitPrice:   models.NewMoney(45000, "EUR"),
+					TotalPrice:  models.NewMoney(45000, "EUR"),
 				},
 			},
 		},
@@ -111,8 +111,8 @@ func main() {
 			OrderNumber:       "ORD-SEED-005",
 			CustomerEmail:     "paolo.gialli@example.com",
 			CustomerName:      "Paolo Gialli",
-			Subtotal:          125.00,
-			Total:             125.00,
+			Subtotal:          models.NewMoney(12500, "EUR"),
+			Total:             models.NewMoney(12500, "EUR"),
 			PaymentStatus:     models.PaymentStatusFailed,
 			FulfillmentStatus: models.FulfillmentStatusUnfulfilled,
 			CreatedAt:         time.Now().Add(-96 * time.Hour),
@@ -120,8 +120,8 @@ func main() {
 				{
 					ProductName: "Quadro Giullare",
 					Quantity:    1,
-					UnitPrice:   125.00,
-					TotalPrice:  125.00,
+					UnitPrice:   models.NewMoney(12500, "EUR"),
+					TotalPrice:  models.NewMoney(12500, "EUR"),
 				},
 			},
 		},
@@ -83,7 +83,7 @@ func main() {
 			Title:            "Maglietta Ribelle Classic",
 			ShortDescription: "Maglietta con il design originale di Ribelle il Pigro",
 			LongDescription:  "Maglietta in cotone biologico al 100% con stampa di alta qualità. Il design presenta Ribelle il Pigro in uno dei suoi momenti iconici. Perfetta per gli amanti dell'arte e dei fumetti.",
-			BasePrice:        29.99,
+			BasePrice:        models.NewMoney(2999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "TS-RIB-001",
 			CharacterValue:   "Ribelle il Pigro",
@@ -111,7 +111,7 @@ func main() {
 			Title:            "Felpa Giullare Color",
 			ShortDescription: "Felpa con cappuccio del Giullare in edizione colorata",
 			LongDescription:  "Felpa in pile morbido con cappuccio. Il design vivace del Giullare su sfondo colorato. Perfetta per mantenere lo spirito allegro anche nelle giornate più fredde.",
-			BasePrice:        49.99,
+			BasePrice:        models.NewMoney(4999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "HS-GIU-001",
 			CharacterValue:   "Il Giullare",
@@ -136,7 +136,7 @@ func main() {
 			Title:            "Stampa Artistica Leon Banner",
 			ShortDescription: "Stampa artistica in alta qualità di Leon in formato banner",
 			LongDescription:  "Stampa professionale su carta fotografica lucida in formato A2. Il design epico di Leon cattura tutta la forza del personaggio. Perfetta per decorare la casa o lo studio.",
-			BasePrice:        39.99,
+			BasePrice:        models.NewMoney(3999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "PRT-LEO-001",
 			CharacterValue:   "Leon",
@@ -151,10 +151,10 @@ func main() {
 				{URL: "/products/stampa-leon/frame-example.jpg", AltText: "Esempio con cornice", Position: 1},
 			},
 			Variants: []models.ProductVariant{
-				{SKU: "PRT-LEO-001-A4", Name: "A4", Attributes: createAttributes(map[string]string{"size": "A4"}), PriceAdjustment: -15.00, Stock: 20},
-				{SKU: "PRT-LEO-001-A3", Name: "A3", Attributes: createAttributes(map[string]string{"size": "A3"}), PriceAdjustment: -5.00, Stock: 15},
+				{SKU: "PRT-LEO-001-A4", Name: "A4", Attributes: createAttributes(map[string]string{"size": "A4"}), PriceAdjustment: models.NewMoney(-1500, "EUR"), Stock: 20},
+				{SKU: "PRT-LEO-001-A3", Name: "A3", Attributes: createAttributes(map[string]string{"size": "A3"}), PriceAdjustment: models.NewMoney(-500, "EUR"), Stock: 15},
 				{SKU: "PRT-LEO-001-A2", Name: "A2", Attributes: createAttributes(map[string]string{"size": "A2"}), Stock: 10},
-				{SKU: "PRT-LEO-001-A1", Name: "A1", Attributes: createAttributes(map[string]string{"size": "A1"}), PriceAdjustment: 25.00, Stock: 5},
+				{SKU: "PRT-LEO-001-A1", Name: "A1", Attributes: createAttributes(map[string]string{"size": "A1"}), PriceAdjustment: models.NewMoney(2500, "EUR"), Stock: 5},
 			},
 		},
 		{
@@ -162,7 +162,7 @@ func main() {
 			Title:            "Poster Il Polemico",
 			ShortDescription: "Poster provocatorio con Il Polemico",
 			LongDescription:  "Poster in formato grande con il design di Il Polemico. Perfetto per chi ama l'arte provocatoria e i messaggi forti. Stampe su carta spessa e resistente.",
-			BasePrice:        24.99,
+			BasePrice:        models.NewMoney(2499, "EUR"),
 			Currency:         "EUR",
 			SKU:              "PRT-POL-001",
 			CharacterValue:   "Il Polemico",
@@ -184,7 +184,7 @@ func main() {
 			Title:            "Tazza Team Quattro",
 			ShortDescription: "Tazza con tutti e quattro i personaggi insieme",
 			LongDescription:  "Tazza in ceramica con il design esclusivo che riunisce tutti e quattro i personaggi: Ribelle, Il Giullare, Leon e Il Polemico. Perfetta per la colazione o per il tè.",
-			BasePrice:        19.99,
+			BasePrice:        models.NewMoney(1999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "MUG-TEAM-001",
 			CharacterValue:   "Saggio Antico",
@@ -200,9 +200,9 @@ func main() {
 				{URL: "/products/tazza-team/top.jpg", AltText: "Tazza Team vista dall'alto", Position: 2},
 			},
 			Variants: []models.ProductVariant{
-				{SKU: "MUG-TEAM-001-SM", Name: "Small (350ml)", Attributes: createAttributes(map[string]string{"size": "small"}), PriceAdjustment: -3.00, Stock: 20},
+				{SKU: "MUG-TEAM-001-SM", Name: "Small (350ml)", Attributes: createAttributes(map[string]string{"size": "small"}), PriceAdjustment: models.NewMoney(-300, "EUR"), Stock: 20},
 				{SKU: "MUG-TEAM-001-MD", Name: "Medium (500ml)", Attributes: createAttributes(map[string]string{"size": "medium"}), Stock: 18},
-				{SKU: "MUG-TEAM-001-LG", Name: "Large (600ml)", Attributes: createAttributes(map[string]string{"size": "large"}), PriceAdjustment: 3.00, Stock: 15},
+				{SKU: "MUG-TEAM-001-LG", Name: "Large (600ml)", Attributes: createAttributes(map[string]string{"size": "large"}), PriceAdjustment: models.NewMoney(300, "EUR"), Stock: 15},
 			},
 		},
 		{
@@ -210,7 +210,7 @@ func main() {
 			Title:            "Borraccia Ribelle Sport",
 			ShortDescription: "Borraccia sportiva con design Ribelle",
 			LongDescription:  "Borraccia in acciaio inossidabile con design di Ribelle il Pigro. Isolamento termico, perfetta per portare acqua fresca in palestra o in ufficio.",
-			BasePrice:        22.99,
+			BasePrice:        models.NewMoney(2299, "EUR"),
 			Currency:         "EUR",
 			SKU:              "BTL-RIB-001",
 			CharacterValue:   "Ribelle il Pigro",
@@ -234,7 +234,7 @@ func main() {
 			Title:            "Zaino Leon Adventure",
 			ShortDescription: "Zaino sportivo con stampa Leon",
 			LongDescription:  "Zaino robusto e capiente con il design epico di Leon. Perfetto per le avventure quotidiane o i viaggi. Con tasche laterali per la borraccia.",
-			BasePrice:        59.99,
+			BasePrice:        models.NewMoney(5999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "BAG-LEO-001",
 			CharacterValue:   "Leon",
@@ -258,7 +258,7 @@ func main() {
 			Title:            "Set Stampe Collezione Completa",
 			ShortDescription: "Set di 4 stampe artistiche con tutti i personaggi",
 			LongDescription:  "Collezione completa delle stampe artistiche di tutti e quattro i personaggi. Ideale per decorare un'intera parete. Include Ribelle, Il Giullare, Leon e Il Polemico.",
-			BasePrice:        99.99,
+			BasePrice:        models.NewMoney(9999, "EUR"),
 			Currency:         "EUR",
 			SKU:              "SET-STAMPE-001",
 			CharacterValue:   "Saggio Antico",
@@ -272,7 +272,7 @@ func main() {
 			},
 			Variants: []models.ProductVariant{
 				{SKU: "SET-STAMPE-001-A4", Name: "Set A4", Attributes: createAttributes(map[string]string{"size": "A4", "count": "4"}), Stock: 15},
-				{SKU: "SET-STAMPE-001-A3", Name: "Set A3", Attributes: createAttributes(map[string]string{"size": "A3", "count": "4"}), PriceAdjustment: 40.00, Stock: 8},
+				{SKU: "SET-STAMPE-001-A3", Name: "Set A3", Attributes: createAttributes(map[string]string{"size": "A3", "count": "4"}), PriceAdjustment: models.NewMoney(4000, "EUR"), Stock: 8},
 			},
 		},
 	}
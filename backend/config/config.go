@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,6 +11,15 @@ import (
 type Config struct {
 	Server    ServerConfig
 	Database  DatabaseConfig
+	Auth      AuthConfig
+	Payment   PaymentConfig
+	Webhook   WebhookConfig
+	Orders    OrderConfig
+	Cart      CartConfig
+	Tax       TaxConfig
+	Invoice   InvoiceConfig
+	Customers CustomerConfig
+	Mail      MailConfig
 	Etsy      EtsyConfig
 	Scheduler SchedulerConfig
 	RateLimit RateLimitConfig
@@ -18,8 +28,9 @@ type Config struct {
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port        string
-	Environment string
+	Port           string
+	Environment    string
+	TrustedProxies []string // IPs or CIDR networks whose X-Forwarded-For is believed
 }
 
 // DatabaseConfig holds database connection configuration
@@ -32,6 +43,89 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// AuthConfig holds admin authentication configuration
+type AuthConfig struct {
+	JWTSecret         string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	BootstrapUsername string
+	BootstrapPassword string
+}
+
+// PaymentConfig holds payment provider configuration
+type PaymentConfig struct {
+	Provider               string
+	StripeAPIKey           string
+	StripeWebhookSecret    string
+	StripeBaseURL          string
+	StripeWebhookTolerance time.Duration
+	PayPalClientID         string
+	PayPalClientSecret     string
+	PayPalWebhookID        string
+	PayPalBaseURL          string
+}
+
+// WebhookConfig holds payment webhook processing configuration
+type WebhookConfig struct {
+	RetryInterval time.Duration
+	MaxAttempts   int
+}
+
+// OrderConfig holds order lifecycle configuration
+type OrderConfig struct {
+	PendingTTL         time.Duration
+	ExpiryInterval     time.Duration
+	NumberFormat       string // e.g. AMT-{YYYY}-{SEQ:6}
+	RefundNumberFormat string
+}
+
+// CartConfig holds cart stock reservation configuration
+type CartConfig struct {
+	ReservationTTL  time.Duration // How long cart lines hold their stock; 0 turns holds off
+	ReleaseInterval time.Duration // How often expired holds are released
+}
+
+// TaxConfig holds VAT calculation configuration
+type TaxConfig struct {
+	PricesIncludeTax bool
+	OriginCountry    string
+}
+
+// InvoiceConfig holds the seller details and numbering of invoices
+type InvoiceConfig struct {
+	SellerName    string
+	SellerAddress []string
+	SellerVATID   string
+	SellerEmail   string
+	NumberFormat  string
+
+	// Fiscal data of the seller for FatturaPA electronic invoices
+	SellerTaxCode   string
+	SellerTaxRegime string
+	SellerStreet    string
+	SellerZipCode   string
+	SellerCity      string
+	SellerProvince  string
+	SellerCountry   string
+}
+
+// CustomerConfig holds customer account configuration
+type CustomerConfig struct {
+	ShopURL         string // Storefront base URL for the links in account emails
+	RefreshTokenTTL time.Duration
+	VerifyTokenTTL  time.Duration
+	ResetTokenTTL   time.Duration
+}
+
+// MailConfig holds the SMTP server used to send emails
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
 // EtsyConfig holds Etsy API integration configuration
 type EtsyConfig struct {
 	APIKey                string
@@ -71,8 +165,9 @@ type LoggingConfig struct {
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port:        getEnv("PORT", "8080"),
-			Environment: getEnv("ENVIRONMENT", "development"),
+			Port:           getEnv("PORT", "8080"),
+			Environment:    getEnv("ENVIRONMENT", "development"),
+			TrustedProxies: getEnvList("TRUSTED_PROXIES", ","),
 		},
 	Database: DatabaseConfig{
 		Host:     getEnv("DB_HOST", "localhost"),
@@ -82,6 +177,70 @@ func Load() *Config {
 		Name:     getEnv("DB_NAME", "artmanagement"),
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
 	},
+		Auth: AuthConfig{
+			JWTSecret:         getEnv("JWT_SECRET", ""),
+			AccessTokenTTL:    time.Duration(getEnvInt("JWT_ACCESS_TTL_MINUTES", 15)) * time.Minute,
+			RefreshTokenTTL:   time.Duration(getEnvInt("JWT_REFRESH_TTL_HOURS", 168)) * time.Hour,
+			BootstrapUsername: getEnv("ADMIN_USERNAME", ""),
+			BootstrapPassword: getEnv("ADMIN_PASSWORD", ""),
+		},
+		Payment: PaymentConfig{
+			Provider:               getEnv("PAYMENT_PROVIDER", "mock"),
+			StripeAPIKey:           getEnv("STRIPE_API_KEY", ""),
+			StripeWebhookSecret:    getEnv("STRIPE_WEBHOOK_SECRET", ""),
+			StripeBaseURL:          getEnv("STRIPE_API_BASE_URL", "https://api.stripe.com"),
+			StripeWebhookTolerance: time.Duration(getEnvInt("STRIPE_WEBHOOK_TOLERANCE_SECONDS", 300)) * time.Second,
+			PayPalClientID:         getEnv("PAYPAL_CLIENT_ID", ""),
+			PayPalClientSecret:     getEnv("PAYPAL_CLIENT_SECRET", ""),
+			PayPalWebhookID:        getEnv("PAYPAL_WEBHOOK_ID", ""),
+			PayPalBaseURL:          getEnv("PAYPAL_API_BASE_URL", "https://api-m.sandbox.paypal.com"),
+		},
+		Webhook: WebhookConfig{
+			RetryInterval: time.Duration(getEnvInt("WEBHOOK_RETRY_INTERVAL_SECONDS", 60)) * time.Second,
+			MaxAttempts:   getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		},
+		Invoice: InvoiceConfig{
+			SellerName:    getEnv("INVOICE_SELLER_NAME", "Art Management Tool"),
+			SellerAddress: getEnvList("INVOICE_SELLER_ADDRESS", ";"),
+			SellerVATID:   getEnv("INVOICE_SELLER_VAT_ID", ""),
+			SellerEmail:   getEnv("INVOICE_SELLER_EMAIL", ""),
+			NumberFormat:  getEnv("INVOICE_NUMBER_FORMAT", "INV-{YYYY}-{SEQ:6}"),
+
+			SellerTaxCode:   getEnv("INVOICE_SELLER_TAX_CODE", ""),
+			SellerTaxRegime: getEnv("INVOICE_SELLER_TAX_REGIME", "RF01"),
+			SellerStreet:    getEnv("INVOICE_SELLER_STREET", ""),
+			SellerZipCode:   getEnv("INVOICE_SELLER_ZIP_CODE", ""),
+			SellerCity:      getEnv("INVOICE_SELLER_CITY", ""),
+			SellerProvince:  getEnv("INVOICE_SELLER_PROVINCE", ""),
+			SellerCountry:   getEnv("INVOICE_SELLER_COUNTRY", "IT"),
+		},
+		Customers: CustomerConfig{
+			ShopURL:         getEnv("SHOP_URL", "http://localhost:3000"),
+			RefreshTokenTTL: time.Duration(getEnvInt("CUSTOMER_REFRESH_TTL_HOURS", 720)) * time.Hour,
+			VerifyTokenTTL:  time.Duration(getEnvInt("CUSTOMER_VERIFY_TTL_HOURS", 48)) * time.Hour,
+			ResetTokenTTL:   time.Duration(getEnvInt("CUSTOMER_RESET_TTL_MINUTES", 60)) * time.Minute,
+		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", ""),
+		},
+		Orders: OrderConfig{
+			PendingTTL:         time.Duration(getEnvInt("ORDER_PENDING_TTL_MINUTES", 60)) * time.Minute,
+			ExpiryInterval:     time.Duration(getEnvInt("ORDER_EXPIRY_INTERVAL_SECONDS", 300)) * time.Second,
+			NumberFormat:       getEnv("ORDER_NUMBER_FORMAT", "ORD-{YYYY}-{SEQ:6}"),
+			RefundNumberFormat: getEnv("REFUND_NUMBER_FORMAT", "REF-{YYYY}-{SEQ:6}"),
+		},
+		Cart: CartConfig{
+			ReservationTTL:  time.Duration(getEnvInt("CART_RESERVATION_TTL_MINUTES", 0)) * time.Minute,
+			ReleaseInterval: time.Duration(getEnvInt("CART_RESERVATION_RELEASE_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+		Tax: TaxConfig{
+			PricesIncludeTax: getEnvBool("TAX_PRICES_INCLUDE_TAX", true),
+			OriginCountry:    getEnv("TAX_ORIGIN_COUNTRY", "IT"),
+		},
 		Etsy: EtsyConfig{
 			APIKey:                getEnv("ETSY_API_KEY", ""),
 			APISecret:             getEnv("ETSY_API_SECRET", ""),
@@ -121,6 +280,11 @@ func (c *Config) IsEtsyEnabled() bool {
 		c.Etsy.SyncEnabled
 }
 
+// IsPayPalEnabled checks if PayPal credentials are configured
+func (c *Config) IsPayPalEnabled() bool {
+	return c.Payment.PayPalClientID != "" && c.Payment.PayPalClientSecret != ""
+}
+
 // IsProduction checks if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
@@ -160,6 +324,17 @@ func getEnvInt(key string, defaultValue int) int {
 	return value
 }
 
+// getEnvList splits an environment variable into its trimmed, non-empty parts
+func getEnvList(key, separator string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), separator) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // getEnvBool gets a boolean environment variable with a default value
 func getEnvBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
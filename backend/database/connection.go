@@ -85,7 +85,24 @@ func AutoMigrate() error {
 	log.Println("Running database migrations...")
 
 	// Prima esegui AutoMigrate per creare le tabelle
-	err := DB.AutoMigrate(
+	err := DB.AutoMigrate(Models()...)
+
+	if err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	// Poi esegui le migrazioni personalizzate per gestire i dati esistenti
+	if err := runCustomMigrations(); err != nil {
+		return fmt.Errorf("failed to run custom migrations: %w", err)
+	}
+
+	log.Println("Database migrations completed successfully")
+	return nil
+}
+
+// Models elenca i modelli di cui AutoMigrate crea le tabelle
+func Models() []interface{} {
+	return []interface{}{
 		&models.Personaggio{},
 		&models.Fumetto{},
 		// E-commerce models
@@ -95,31 +112,43 @@ func AutoMigrate() error {
 		&models.ProductVariant{},
 		&models.Cart{},
 		&models.CartItem{},
+		&models.StockReservation{},
 		&models.Order{},
 		&models.OrderItem{},
 		&models.Notification{},
 		&models.AuditLog{},
 		&models.DiscountCode{},
+		&models.TaxRate{},
+		&models.ShippingZone{},
+		&models.ShippingMethod{},
+		&models.ShippingRate{},
+		&models.Shipment{},
+		&models.ShipmentItem{},
+		&models.OrderEvent{},
+		&models.Refund{},
+		&models.RefundItem{},
+		&models.ReturnRequest{},
+		&models.ReturnItem{},
+		&models.Invoice{},
+		&models.NumberSequence{},
 		&models.ShopifyLink{},
+		&models.WebhookEvent{},
+		// Admin authentication
+		&models.AdminUser{},
+		&models.AdminUserRole{},
+		&models.AdminSession{},
+		// Customer accounts
+		&models.Customer{},
+		&models.CustomerSession{},
+		&models.CustomerToken{},
+		&models.CustomerAddress{},
 		// Etsy Integration models
 		&etsy.OAuthToken{},
 		&models.EtsySyncConfig{},
 		&models.EtsyProduct{},
 		&models.EtsyInventorySyncLog{},
 		&models.EtsyReceipt{},
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	// Poi esegui le migrazioni personalizzate per gestire i dati esistenti
-	if err := runCustomMigrations(); err != nil {
-		return fmt.Errorf("failed to run custom migrations: %w", err)
 	}
-
-	log.Println("Database migrations completed successfully")
-	return nil
 }
 
 // runCustomMigrations gestisce migrazioni personalizzate per dati esistenti
@@ -131,6 +160,84 @@ func runCustomMigrations() error {
 		return fmt.Errorf("failed to migrate order_number: %w", err)
 	}
 
+	// Migrazione per i ruoli admin: gli admin esistenti senza ruoli diventano owner
+	if err := migrateAdminRoles(); err != nil {
+		return fmt.Errorf("failed to migrate admin roles: %w", err)
+	}
+
+	// Migrazione per payment_provider: gli ordini esistenti usano il provider di default
+	if err := migrateOrderPaymentProvider(); err != nil {
+		return fmt.Errorf("failed to migrate payment_provider: %w", err)
+	}
+
+	// Migrazione dei contatori delle fatture nella tabella generica number_sequences
+	if err := migrateInvoiceSequences(); err != nil {
+		return fmt.Errorf("failed to migrate invoice sequences: %w", err)
+	}
+
+	return nil
+}
+
+// migrateInvoiceSequences sposta i contatori di invoice_sequences in number_sequences,
+// così la numerazione delle fatture prosegue senza ripartire da 1
+func migrateInvoiceSequences() error {
+	if !DB.Migrator().HasTable("invoice_sequences") {
+		return nil
+	}
+
+	result := DB.Exec(`
+		INSERT INTO number_sequences (name, period, last_number)
+		SELECT 'invoice', year, last_number FROM invoice_sequences
+		ON CONFLICT (name, period) DO UPDATE
+		SET last_number = GREATEST(number_sequences.last_number, EXCLUDED.last_number)
+	`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Moved %d invoice sequences to number_sequences", result.RowsAffected)
+	}
+
+	return DB.Migrator().DropTable("invoice_sequences")
+}
+
+// migrateOrderPaymentProvider valorizza payment_provider sugli ordini creati prima del registry.
+// Una stringa vuota indica il provider di default; gli ordini Etsy restano su Etsy.
+func migrateOrderPaymentProvider() error {
+	result := DB.Exec(`
+		UPDATE orders
+		SET payment_provider = CASE WHEN payment_method = 'etsy' THEN 'etsy' ELSE '' END
+		WHERE payment_provider IS NULL
+	`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Set payment_provider on %d existing orders", result.RowsAffected)
+	}
+
+	return nil
+}
+
+// migrateAdminRoles assegna il ruolo owner agli admin creati prima dell'RBAC
+func migrateAdminRoles() error {
+	result := DB.Exec(`
+		INSERT INTO admin_user_roles (admin_user_id, role, created_at)
+		SELECT u.id, 'owner', NOW()
+		FROM admin_users u
+		WHERE NOT EXISTS (
+			SELECT 1 FROM admin_user_roles r WHERE r.admin_user_id = u.id
+		)
+	`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Granted owner role to %d admin users without roles", result.RowsAffected)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,69 @@
+package testdb
+
+import (
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// CreateVariant creates a published product with a single variant in stock, priced 25 EUR
+func CreateVariant(t *testing.T, db *gorm.DB, sku string, stock int) *models.ProductVariant {
+	t.Helper()
+	product := models.EnhancedProduct{Slug: sku, Title: sku, BasePrice: models.EUR(2500), Status: models.ProductStatusPublished}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	variant := models.ProductVariant{ProductID: product.ID, SKU: sku, Name: sku, Attributes: "{}", Stock: stock}
+	if err := db.Create(&variant).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &variant
+}
+
+// CreateOrder creates the order with one piece of each variant at 25 EUR, taking their
+// stock as checkout does. Customer, totals, statuses and addresses left empty are filled
+// in; the order is pending unless it says otherwise.
+func CreateOrder(t *testing.T, db *gorm.DB, order models.Order, variants ...*models.ProductVariant) *models.Order {
+	t.Helper()
+	if order.CustomerEmail == "" {
+		order.CustomerEmail, order.CustomerName = "mario@example.com", "Mario Rossi"
+	}
+	if order.PaymentStatus == "" {
+		order.PaymentStatus = models.PaymentStatusPending
+	}
+	if order.FulfillmentStatus == "" {
+		order.FulfillmentStatus = models.FulfillmentStatusUnfulfilled
+	}
+	if order.ShippingAddress == "" {
+		order.ShippingAddress = `{"street":"Via Roma 1","city":"Milano","zip_code":"20100","country":"IT"}`
+	}
+	if order.BillingAddress == "" {
+		order.BillingAddress = order.ShippingAddress
+	}
+	order.Currency = "EUR"
+	order.Subtotal = models.EUR(2500 * int64(len(variants)))
+	order.Total = order.Subtotal
+	for _, variant := range variants {
+		order.Items = append(order.Items, models.OrderItem{
+			ProductID:   &variant.ProductID,
+			VariantID:   &variant.ID,
+			ProductName: variant.Name,
+			SKU:         variant.SKU,
+			Quantity:    1,
+			UnitPrice:   models.EUR(2500),
+			TotalPrice:  models.EUR(2500),
+		})
+		if err := db.Model(variant).Update("stock", gorm.Expr("stock - 1")).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.Create(&order).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Preload("Items").First(&order, order.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &order
+}
@@ -0,0 +1,78 @@
+// Package testdb gives integration tests their own Postgres schema, created with the
+// application models and dropped when the test ends. The tests are skipped unless
+// TEST_DATABASE_URL points at a Postgres server, e.g. the one of docker-compose.dev.yml:
+//
+//	TEST_DATABASE_URL="host=localhost user=artuser password=artpassword dbname=artmanagement sslmode=disable" go test -tags=integration ./...
+package testdb
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open connects to the test database in a new schema with the tables of every model
+func Open(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	admin := open(t, dsn)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		closeDB(admin)
+	})
+
+	db := open(t, withSearchPath(dsn, schema))
+	t.Cleanup(func() { closeDB(db) })
+	if err := db.AutoMigrate(database.Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// open connects without logging every query
+func open(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	return db
+}
+
+// withSearchPath sets the schema of every connection, for both URL and key=value DSNs
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return dsn + separator + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
+
+// closeDB closes the connection pool of a test database
+func closeDB(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
@@ -0,0 +1,96 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+)
+
+// AuditHandler handles admin audit log operations
+type AuditHandler struct {
+	auditService *audit.Service
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *audit.Service) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs handles GET /api/admin/audit
+func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	filters := audit.DefaultFilters()
+
+	query := r.URL.Query()
+
+	if entityType := query.Get("entity_type"); entityType != "" {
+		filters.EntityType = entityType
+	}
+
+	if entityID := query.Get("entity_id"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid entity ID", http.StatusBadRequest)
+			return
+		}
+		filters.EntityID = uint(id)
+	}
+
+	if actor := query.Get("actor"); actor != "" {
+		filters.Actor = actor
+	}
+
+	if action := query.Get("action"); action != "" {
+		filters.Action = audit.Action(action)
+	}
+
+	if startDate := query.Get("start_date"); startDate != "" {
+		t, err := time.Parse(time.RFC3339, startDate)
+		if err != nil {
+			http.Error(w, "Invalid start_date, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+		filters.StartDate = t
+	}
+
+	if endDate := query.Get("end_date"); endDate != "" {
+		t, err := time.Parse(time.RFC3339, endDate)
+		if err != nil {
+			http.Error(w, "Invalid end_date, expected RFC3339", http.StatusBadRequest)
+			return
+		}
+		filters.EndDate = t
+	}
+
+	if page := query.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil && p > 0 {
+			filters.Page = p
+		}
+	}
+
+	if perPage := query.Get("per_page"); perPage != "" {
+		if pp, err := strconv.Atoi(perPage); err == nil && pp > 0 && pp <= 100 {
+			filters.PerPage = pp
+		}
+	}
+
+	entries, total, err := h.auditService.List(filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"entries":  entries,
+		"total":    total,
+		"page":     filters.Page,
+		"per_page": filters.PerPage,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -5,19 +5,22 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 // CategoryHandler handles admin category operations
 type CategoryHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *audit.Service
 }
 
 // NewCategoryHandler creates a new category handler
-func NewCategoryHandler(db *gorm.DB) *CategoryHandler {
-	return &CategoryHandler{db: db}
+func NewCategoryHandler(db *gorm.DB, auditService *audit.Service) *CategoryHandler {
+	return &CategoryHandler{db: db, auditService: auditService}
 }
 
 // CategoryInput represents the input for creating/updating a category
@@ -147,6 +150,8 @@ func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityCategory, category.ID, audit.ActionCreate, nil, category)
+	
 	// Reload with relationships
 	h.db.Preload("Parent").First(&category, category.ID)
 	
@@ -180,6 +185,8 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	before := category
+	
 	// Validate parent exists if provided and prevent circular reference
 	if input.ParentID != nil {
 		if *input.ParentID == uint(id) {
@@ -232,6 +239,8 @@ func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityCategory, category.ID, audit.ActionUpdate, before, category)
+	
 	// Reload with relationships
 	h.db.Preload("Parent").Preload("Children").First(&category, category.ID)
 	
@@ -286,6 +295,8 @@ func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityCategory, category.ID, audit.ActionDelete, category, nil)
+	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Category deleted successfully",
@@ -6,31 +6,34 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 // DiscountHandler handles admin discount code operations
 type DiscountHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *audit.Service
 }
 
 // NewDiscountHandler creates a new discount handler
-func NewDiscountHandler(db *gorm.DB) *DiscountHandler {
-	return &DiscountHandler{db: db}
+func NewDiscountHandler(db *gorm.DB, auditService *audit.Service) *DiscountHandler {
+	return &DiscountHandler{db: db, auditService: auditService}
 }
 
 // DiscountInput represents the input for creating/updating a discount code
 type DiscountInput struct {
-	Code        string     `json:"code"`
-	Type        string     `json:"type"` // percentage, fixed_amount
-	Value       float64    `json:"value"`
-	MinPurchase float64    `json:"min_purchase"`
-	MaxUses     *int       `json:"max_uses"`
-	StartsAt    *time.Time `json:"starts_at"`
-	ExpiresAt   *time.Time `json:"expires_at"`
-	Active      bool       `json:"active"`
+	Code        string       `json:"code"`
+	Type        string       `json:"type"` // percentage, fixed_amount
+	Value       float64      `json:"value"`
+	MinPurchase models.Money `json:"min_purchase"`
+	MaxUses     *int         `json:"max_uses"`
+	StartsAt    *time.Time   `json:"starts_at"`
+	ExpiresAt   *time.Time   `json:"expires_at"`
+	Active      bool         `json:"active"`
 }
 
 // ListDiscounts handles GET /api/admin/discounts
@@ -159,7 +162,7 @@ func (h *DiscountHandler) CreateDiscount(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Percentage value cannot exceed 100", http.StatusBadRequest)
 		return
 	}
-	if input.MinPurchase < 0 {
+	if input.MinPurchase.IsNegative() {
 		http.Error(w, "Minimum purchase cannot be negative", http.StatusBadRequest)
 		return
 	}
@@ -202,6 +205,8 @@ func (h *DiscountHandler) CreateDiscount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityDiscount, discount.ID, audit.ActionCreate, nil, discount)
+	
 	response := map[string]interface{}{
 		"discount": discount,
 		"is_valid": discount.IsValid(),
@@ -237,6 +242,8 @@ func (h *DiscountHandler) UpdateDiscount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	before := discount
+	
 	// Validate type and value if provided
 	if input.Type != "" {
 		if input.Type != "percentage" && input.Type != "fixed_amount" {
@@ -252,7 +259,7 @@ func (h *DiscountHandler) UpdateDiscount(w http.ResponseWriter, r *http.Request)
 		}
 		discount.Value = input.Value
 	}
-	if input.MinPurchase >= 0 {
+	if !input.MinPurchase.IsNegative() {
 		discount.MinPurchase = input.MinPurchase
 	}
 	if input.MaxUses != nil {
@@ -301,6 +308,8 @@ func (h *DiscountHandler) UpdateDiscount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityDiscount, discount.ID, audit.ActionUpdate, before, discount)
+	
 	response := map[string]interface{}{
 		"discount": discount,
 		"is_valid": discount.IsValid(),
@@ -332,11 +341,13 @@ func (h *DiscountHandler) DeleteDiscount(w http.ResponseWriter, r *http.Request)
 	// Check if discount has been used
 	if discount.UsedCount > 0 {
 		// Instead of deleting, deactivate it to preserve historical data
+		before := discount
 		discount.Active = false
 		if err := h.db.Save(&discount).Error; err != nil {
 			http.Error(w, "Failed to deactivate discount: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityDiscount, discount.ID, audit.ActionUpdate, before, discount)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"message":  "Discount has been used and was deactivated instead of deleted",
@@ -352,6 +363,8 @@ func (h *DiscountHandler) DeleteDiscount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityDiscount, discount.ID, audit.ActionDelete, discount, nil)
+	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Discount deleted successfully",
@@ -7,19 +7,23 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/Naim0996/art-management-tool/backend/services/etsy"
 	"github.com/gorilla/mux"
 )
 
 // EtsyHandler handles Etsy integration endpoints
 type EtsyHandler struct {
-	service *etsy.Service
+	service      *etsy.Service
+	auditService *audit.Service
 }
 
 // NewEtsyHandler creates a new Etsy handler
-func NewEtsyHandler(service *etsy.Service) *EtsyHandler {
+func NewEtsyHandler(service *etsy.Service, auditService *audit.Service) *EtsyHandler {
 	return &EtsyHandler{
-		service: service,
+		service:      service,
+		auditService: auditService,
 	}
 }
 
@@ -238,12 +242,15 @@ func (h *EtsyHandler) LinkProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update link
+	before := *product
 	product.LocalProductID = &req.LocalProductID
 	if err := h.service.UpdateEtsyProduct(product); err != nil {
 		http.Error(w, "Failed to link product", http.StatusInternalServerError)
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityEtsyProduct, product.ID, audit.ActionLink, before, product)
+
 	// Sync images from Etsy to local product
 	if err := h.service.SyncProductImagesForListing(product.EtsyListingID, req.LocalProductID); err != nil {
 		// Log warning but don't fail the link operation
@@ -282,12 +289,15 @@ func (h *EtsyHandler) UnlinkProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Remove link
+	before := *product
 	product.LocalProductID = nil
 	if err := h.service.UpdateEtsyProduct(product); err != nil {
 		http.Error(w, "Failed to unlink product", http.StatusInternalServerError)
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityEtsyProduct, product.ID, audit.ActionUnlink, before, product)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Product unlinked successfully",
@@ -509,11 +519,21 @@ func (h *EtsyHandler) LinkReceiptToOrder(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	before, err := h.service.GetReceipt(receiptID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
 	if err := h.service.LinkReceiptToOrder(receiptID, req.LocalOrderID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if after, err := h.service.GetReceipt(receiptID); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityEtsyReceipt, before.ID, audit.ActionLink, before, after)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Receipt linked to order successfully",
@@ -537,11 +557,21 @@ func (h *EtsyHandler) UnlinkReceiptFromOrder(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	before, err := h.service.GetReceipt(receiptID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
 	if err := h.service.UnlinkReceiptFromOrder(receiptID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if after, err := h.service.GetReceipt(receiptID); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityEtsyReceipt, before.ID, audit.ActionUnlink, before, after)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Receipt unlinked from order successfully",
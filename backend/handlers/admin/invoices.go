@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/invoice"
+	"github.com/gorilla/mux"
+)
+
+// InvoiceHandler handles order document downloads
+type InvoiceHandler struct {
+	invoiceService *invoice.Service
+	auditService   *audit.Service
+}
+
+// NewInvoiceHandler creates a new invoice handler
+func NewInvoiceHandler(invoiceService *invoice.Service, auditService *audit.Service) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+		auditService:   auditService,
+	}
+}
+
+// GetInvoice handles GET /api/admin/shop/orders/{id}/invoice
+func (h *InvoiceHandler) GetInvoice(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	inv, issued, err := h.invoiceService.Issue(uint(orderID))
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	if issued {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityInvoice, inv.ID, audit.ActionCreate, nil, inv)
+	}
+
+	writeDownload(w, "application/pdf", fmt.Sprintf("invoice-%s.pdf", inv.Number), inv.Document)
+}
+
+// GetPackingSlip handles GET /api/admin/shop/orders/{id}/packing-slip
+func (h *InvoiceHandler) GetPackingSlip(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	document, order, err := h.invoiceService.PackingSlip(uint(orderID))
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeDownload(w, "application/pdf", fmt.Sprintf("packing-slip-%s.pdf", order.OrderNumber), document)
+}
+
+// GetEInvoice handles GET /api/admin/shop/orders/{id}/fatturapa
+func (h *InvoiceHandler) GetEInvoice(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	inv, issued, err := h.invoiceService.EInvoice(uint(orderID))
+	if issued {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityInvoice, inv.ID, audit.ActionCreate, nil, inv)
+	}
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeDownload(w, "application/xml", h.invoiceService.EInvoiceFileName(inv), inv.EInvoice)
+}
+
+// ExportEInvoices handles GET /api/admin/shop/invoices/fatturapa?month=2025-03
+func (h *InvoiceHandler) ExportEInvoices(w http.ResponseWriter, r *http.Request) {
+	month, err := time.Parse("2006-01", r.URL.Query().Get("month"))
+	if err != nil {
+		http.Error(w, "month must be YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	archive, _, err := h.invoiceService.ExportMonth(month.Year(), month.Month())
+	if err != nil {
+		writeInvoiceError(w, err)
+		return
+	}
+
+	writeDownload(w, "application/zip", fmt.Sprintf("fatturapa-%s.zip", month.Format("2006-01")), archive)
+}
+
+// writeDownload sends a document as a file attachment
+func writeDownload(w http.ResponseWriter, contentType, filename string, document []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(document)))
+	w.Write(document)
+}
+
+// writeInvoiceError maps invoice service errors to HTTP status codes
+func writeInvoiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, invoice.ErrOrderNotFound), errors.Is(err, invoice.ErrInvoiceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, invoice.ErrNotInvoiceable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, invoice.ErrInvalidEInvoice):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
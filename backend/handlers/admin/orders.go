@@ -2,11 +2,14 @@ package admin
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/Naim0996/art-management-tool/backend/services/order"
 	"github.com/gorilla/mux"
 )
@@ -14,12 +17,14 @@ import (
 // OrderHandler handles admin order operations
 type OrderHandler struct {
 	orderService *order.Service
+	auditService *audit.Service
 }
 
 // NewOrderHandler creates a new order handler
-func NewOrderHandler(orderService *order.Service) *OrderHandler {
+func NewOrderHandler(orderService *order.Service, auditService *audit.Service) *OrderHandler {
 	return &OrderHandler{
 		orderService: orderService,
+		auditService: auditService,
 	}
 }
 
@@ -119,11 +124,21 @@ func (h *OrderHandler) UpdateFulfillmentStatus(w http.ResponseWriter, r *http.Re
 		return
 	}
 	
-	if err := h.orderService.UpdateFulfillmentStatus(uint(id), req.Status); err != nil {
+	before, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	if err := h.orderService.UpdateFulfillmentStatus(uint(id), req.Status); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	
+	if after, err := h.orderService.GetOrder(uint(id)); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityOrder, uint(id), audit.ActionFulfill, before, after)
+	}
+	
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -137,7 +152,12 @@ func (h *OrderHandler) RefundOrder(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	var req struct {
-		Amount *float64 `json:"amount"` // partial refund if specified
+		Amount *models.Money `json:"amount"` // defaults to the value of the items, or to everything left
+		Items  []struct {
+			OrderItemID uint `json:"order_item_id"`
+			Quantity    int  `json:"quantity"`
+		} `json:"items"` // items to restock
+		Reason string `json:"reason"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -145,10 +165,106 @@ func (h *OrderHandler) RefundOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	if err := h.orderService.RefundOrder(uint(id), req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	before, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
+		writeOrderError(w, err)
 		return
 	}
 	
-	w.WriteHeader(http.StatusNoContent)
+	refundReq := &order.RefundRequest{Amount: req.Amount, Reason: req.Reason}
+	for _, item := range req.Items {
+		refundReq.Items = append(refundReq.Items, models.RefundItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	
+	refund, err := h.orderService.RefundOrder(uint(id), refundReq)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	
+	if after, err := h.orderService.GetOrder(uint(id)); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityOrder, uint(id), audit.ActionRefund, before, after)
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(refund)
+}
+
+// ListRefunds handles GET /api/admin/shop/orders/{id}/refunds
+func (h *OrderHandler) ListRefunds(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+	
+	refunds, err := h.orderService.ListRefunds(uint(id))
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"refunds": refunds})
+}
+
+// CancelOrder handles POST /api/admin/orders/{id}/cancel
+func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+	
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	
+	before, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	
+	if err := h.orderService.CancelOrder(uint(id), req.Reason); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	
+	after, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityOrder, uint(id), audit.ActionCancel, before, after)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(after)
+}
+
+// writeOrderError maps order service errors to HTTP status codes
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, order.ErrOrderNotFound), errors.Is(err, order.ErrShipmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, order.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, order.ErrCancelFailed), errors.Is(err, order.ErrRefundFailed):
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	case errors.Is(err, order.ErrInvalidShipment), errors.Is(err, order.ErrInvalidRefund), errors.Is(err, order.ErrUnknownStatus):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/privacy"
+)
+
+// PrivacyHandler handles customer data export and erasure requests
+type PrivacyHandler struct {
+	privacyService *privacy.Service
+	auditService   *audit.Service
+}
+
+// NewPrivacyHandler creates a new privacy handler
+func NewPrivacyHandler(privacyService *privacy.Service, auditService *audit.Service) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		auditService:   auditService,
+	}
+}
+
+// privacyRequest is the body of privacy requests. The email is sent in the body so that
+// it stays out of URLs and access logs.
+type privacyRequest struct {
+	Email  string `json:"email"`
+	Format string `json:"format"` // "json" (default) or "zip", for exports
+}
+
+// ExportData handles POST /api/admin/privacy/export
+func (h *PrivacyHandler) ExportData(w http.ResponseWriter, r *http.Request) {
+	var req privacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Format != "" && req.Format != "json" && req.Format != "zip" {
+		http.Error(w, "format must be json or zip", http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.privacyService.Export(req.Email)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+
+	if req.Format == "zip" {
+		archive, err := export.Archive()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.record(r, audit.ActionExport, export.Summary())
+		writeDownload(w, "application/zip", fmt.Sprintf("customer-data-%s.zip", export.ExportedAt.Format("20060102-150405")), archive)
+		return
+	}
+
+	h.record(r, audit.ActionExport, export.Summary())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(export)
+}
+
+// AnonymizeData handles POST /api/admin/privacy/anonymize
+func (h *PrivacyHandler) AnonymizeData(w http.ResponseWriter, r *http.Request) {
+	var req privacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.privacyService.Anonymize(req.Email)
+	if err != nil {
+		writePrivacyError(w, err)
+		return
+	}
+
+	h.record(r, audit.ActionAnonymize, summary)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// record writes a data request to the audit log under the customer account, if any
+func (h *PrivacyHandler) record(r *http.Request, action audit.Action, summary *privacy.Summary) {
+	var entityID uint
+	if summary.CustomerID != nil {
+		entityID = *summary.CustomerID
+	}
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityCustomerData, entityID, action, nil, summary)
+}
+
+// writePrivacyError maps privacy service errors to HTTP status codes
+func writePrivacyError(w http.ResponseWriter, err error) {
+	var validation models.ValidationErrors
+	switch {
+	case errors.As(err, &validation):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, privacy.ErrOpenOrders), errors.Is(err, privacy.ErrInvoicePending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
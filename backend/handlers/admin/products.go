@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/Naim0996/art-management-tool/backend/services/product"
 	"github.com/gorilla/mux"
 )
@@ -13,12 +15,14 @@ import (
 // ProductHandler handles admin product operations
 type ProductHandler struct {
 	productService *product.Service
+	auditService   *audit.Service
 }
 
 // NewProductHandler creates a new product handler
-func NewProductHandler(productService *product.Service) *ProductHandler {
+func NewProductHandler(productService *product.Service, auditService *audit.Service) *ProductHandler {
 	return &ProductHandler{
 		productService: productService,
+		auditService:   auditService,
 	}
 }
 
@@ -110,6 +114,8 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProduct, product.ID, audit.ActionCreate, nil, product)
+	
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
@@ -136,11 +142,21 @@ func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	before, err := h.productService.GetProduct(uint(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
 	if err := h.productService.UpdateProduct(uint(id), &updates); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	if after, err := h.productService.GetProduct(uint(id)); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProduct, uint(id), audit.ActionUpdate, before, after)
+	}
+	
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -153,11 +169,19 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	before, err := h.productService.GetProduct(uint(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
 	if err := h.productService.DeleteProduct(uint(id)); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProduct, uint(id), audit.ActionDelete, before, nil)
+	
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -187,6 +211,8 @@ func (h *ProductHandler) AddVariant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityVariant, variant.ID, audit.ActionCreate, nil, variant)
+	
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(variant)
@@ -207,11 +233,21 @@ func (h *ProductHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	before, err := h.productService.GetVariant(uint(id))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
 	if err := h.productService.UpdateVariant(uint(id), &updates); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	if after, err := h.productService.GetVariant(uint(id)); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityVariant, uint(id), audit.ActionUpdate, before, after)
+	}
+	
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -234,10 +270,20 @@ func (h *ProductHandler) UpdateInventory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
+	before, err := h.productService.GetVariant(req.VariantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	
 	if err := h.productService.UpdateInventory(req.VariantID, req.Quantity, req.Operation); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
+	if after, err := h.productService.GetVariant(req.VariantID); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityVariant, req.VariantID, audit.ActionUpdate, before, after)
+	}
+	
 	w.WriteHeader(http.StatusNoContent)
 }
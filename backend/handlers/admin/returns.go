@@ -0,0 +1,261 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/returns"
+	"github.com/gorilla/mux"
+)
+
+// ReturnHandler handles admin return (RMA) operations
+type ReturnHandler struct {
+	returnService *returns.Service
+	auditService  *audit.Service
+}
+
+// NewReturnHandler creates a new admin return handler
+func NewReturnHandler(returnService *returns.Service, auditService *audit.Service) *ReturnHandler {
+	return &ReturnHandler{
+		returnService: returnService,
+		auditService:  auditService,
+	}
+}
+
+// ReturnItemInput is an order item quantity in a return request
+type ReturnItemInput struct {
+	OrderItemID uint `json:"order_item_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+// returnItems converts the requested items to return items
+func returnItems(input []ReturnItemInput) []models.ReturnItem {
+	items := make([]models.ReturnItem, 0, len(input))
+	for _, item := range input {
+		items = append(items, models.ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	return items
+}
+
+// ListReturns handles GET /api/admin/shop/returns
+func (h *ReturnHandler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	filters := returns.Filters{Status: models.ReturnStatus(r.URL.Query().Get("status"))}
+	if orderID := r.URL.Query().Get("order_id"); orderID != "" {
+		id, err := strconv.ParseUint(orderID, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid order ID", http.StatusBadRequest)
+			return
+		}
+		filters.OrderID = uint(id)
+	}
+
+	list, err := h.returnService.List(filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"returns": list})
+}
+
+// GetReturn handles GET /api/admin/shop/returns/{id}
+func (h *ReturnHandler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseReturnID(w, r)
+	if !ok {
+		return
+	}
+
+	ret, err := h.returnService.Get(id)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// CreateReturn handles POST /api/admin/shop/orders/{id}/returns
+func (h *ReturnHandler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Items  []ReturnItemInput `json:"items"`
+		Reason string            `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	actor := middleware.Actor(r.Context())
+	ret, err := h.returnService.Open(uint(orderID), returnItems(req.Items), req.Reason, actor)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	h.auditService.Record(actor, audit.EntityReturn, ret.ID, audit.ActionCreate, nil, ret)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ApproveReturn handles POST /api/admin/shop/returns/{id}/approve
+func (h *ReturnHandler) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, audit.ActionApprove, h.returnService.Approve)
+}
+
+// RejectReturn handles POST /api/admin/shop/returns/{id}/reject
+func (h *ReturnHandler) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, audit.ActionReject, h.returnService.Reject)
+}
+
+// decide approves or rejects a return with an optional {note} body
+func (h *ReturnHandler) decide(w http.ResponseWriter, r *http.Request, action audit.Action, apply func(uint, string) (*models.ReturnRequest, error)) {
+	id, ok := parseReturnID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	before, err := h.returnService.Get(id)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	ret, err := apply(id, req.Note)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityReturn, id, action, before, ret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// ReceiveReturn handles POST /api/admin/shop/returns/{id}/receive
+func (h *ReturnHandler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseReturnID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []struct {
+			OrderItemID uint                     `json:"order_item_id"`
+			Disposition models.ReturnDisposition `json:"disposition"`
+		} `json:"items"` // Items left out are restocked
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dispositions := make(map[uint]models.ReturnDisposition, len(req.Items))
+	for _, item := range req.Items {
+		dispositions[item.OrderItemID] = item.Disposition
+	}
+
+	before, err := h.returnService.Get(id)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	ret, err := h.returnService.Receive(id, dispositions)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityReturn, id, audit.ActionReceive, before, ret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// RefundReturn handles POST /api/admin/shop/returns/{id}/refund
+func (h *ReturnHandler) RefundReturn(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseReturnID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Amount *models.Money `json:"amount"` // defaults to the value of the returned items
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	before, err := h.returnService.Get(id)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	ret, err := h.returnService.Refund(id, req.Amount)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityReturn, id, audit.ActionRefund, before, ret)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ret)
+}
+
+// parseReturnID reads the {id} route variable, writing a 400 when invalid
+func parseReturnID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid return ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeReturnError maps returns service errors to HTTP status codes; order errors,
+// e.g. from the refund, are mapped as for orders
+func writeReturnError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, returns.ErrReturnNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, returns.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, returns.ErrInvalidReturn):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeOrderError(w, err)
+	}
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/gorilla/mux"
+)
+
+// ShipmentInput represents the input for creating/updating a shipment
+type ShipmentInput struct {
+	Carrier        *string    `json:"carrier"`
+	TrackingNumber *string    `json:"tracking_number"`
+	TrackingURL    *string    `json:"tracking_url"`
+	ShippedAt      *time.Time `json:"shipped_at"`
+	Items          []struct {
+		OrderItemID uint `json:"order_item_id"`
+		Quantity    int  `json:"quantity"`
+	} `json:"items"` // Omit to ship everything not shipped yet; ignored on update
+}
+
+// apply copies the tracking details present in the input onto the shipment
+func (input *ShipmentInput) apply(shipment *models.Shipment) {
+	if input.Carrier != nil {
+		shipment.Carrier = *input.Carrier
+	}
+	if input.TrackingNumber != nil {
+		shipment.TrackingNumber = *input.TrackingNumber
+	}
+	if input.TrackingURL != nil {
+		shipment.TrackingURL = *input.TrackingURL
+	}
+	if input.ShippedAt != nil {
+		shipment.ShippedAt = *input.ShippedAt
+	}
+}
+
+// ListShipments handles GET /api/admin/shop/orders/{id}/shipments
+func (h *OrderHandler) ListShipments(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	shipments, err := h.orderService.ListShipments(uint(orderID))
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"shipments": shipments})
+}
+
+// CreateShipment handles POST /api/admin/shop/orders/{id}/shipments
+func (h *OrderHandler) CreateShipment(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return
+	}
+
+	var input ShipmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.orderService.GetOrder(uint(orderID))
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	var shipment models.Shipment
+	input.apply(&shipment)
+	for _, item := range input.Items {
+		shipment.Items = append(shipment.Items, models.ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+
+	if err := h.orderService.CreateShipment(uint(orderID), &shipment); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	if after, err := h.orderService.GetOrder(uint(orderID)); err == nil {
+		h.auditService.Record(middleware.Actor(r.Context()), audit.EntityOrder, uint(orderID), audit.ActionFulfill, before, after)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// UpdateShipment handles PATCH /api/admin/shop/orders/{id}/shipments/{shipmentId}
+func (h *OrderHandler) UpdateShipment(w http.ResponseWriter, r *http.Request) {
+	orderID, shipmentID, ok := parseShipmentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var input ShipmentInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	shipment, err := h.orderService.GetShipment(orderID, shipmentID)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	before := *shipment
+	input.apply(shipment)
+
+	if err := h.orderService.UpdateShipment(shipment); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShipment, shipment.ID, audit.ActionUpdate, before, shipment)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shipment)
+}
+
+// DeleteShipment handles DELETE /api/admin/shop/orders/{id}/shipments/{shipmentId}
+func (h *OrderHandler) DeleteShipment(w http.ResponseWriter, r *http.Request) {
+	orderID, shipmentID, ok := parseShipmentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	shipment, err := h.orderService.GetShipment(orderID, shipmentID)
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	if err := h.orderService.DeleteShipment(orderID, shipmentID); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShipment, shipment.ID, audit.ActionDelete, shipment, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseShipmentIDs reads the {id} and {shipmentId} route variables, writing a 400 when invalid
+func parseShipmentIDs(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	vars := mux.Vars(r)
+	orderID, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	shipmentID, err := strconv.ParseUint(vars["shipmentId"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid shipment ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return uint(orderID), uint(shipmentID), true
+}
@@ -0,0 +1,296 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/shipping"
+	"github.com/gorilla/mux"
+)
+
+// ShippingHandler handles admin shipping zone and method operations
+type ShippingHandler struct {
+	shippingService *shipping.Service
+	auditService    *audit.Service
+}
+
+// NewShippingHandler creates a new admin shipping handler
+func NewShippingHandler(shippingService *shipping.Service, auditService *audit.Service) *ShippingHandler {
+	return &ShippingHandler{
+		shippingService: shippingService,
+		auditService:    auditService,
+	}
+}
+
+// ShippingZoneInput represents the input for creating/updating a shipping zone
+type ShippingZoneInput struct {
+	Name      *string  `json:"name"`
+	Countries []string `json:"countries"`
+	Fallback  *bool    `json:"fallback"`
+}
+
+// ShippingMethodInput represents the input for creating/updating a shipping method
+type ShippingMethodInput struct {
+	ZoneID                uint                      `json:"zone_id"`
+	Code                  *string                   `json:"code"`
+	Name                  *string                   `json:"name"`
+	Description           *string                   `json:"description"`
+	RateBasis             *models.ShippingRateBasis `json:"rate_basis"` // weight, items, subtotal
+	FreeShippingThreshold *models.Money             `json:"free_shipping_threshold"`
+	Currency              *string                   `json:"currency"`
+	Active                *bool                     `json:"active"`
+	Position              *int                      `json:"position"`
+	Rates                 []models.ShippingRate     `json:"rates"` // Replaces all brackets when present
+}
+
+// ListZones handles GET /api/admin/shipping/zones
+func (h *ShippingHandler) ListZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := h.shippingService.ListZones()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"zones":      zones,
+		"rate_bases": []models.ShippingRateBasis{models.ShippingRateByWeight, models.ShippingRateByItems, models.ShippingRateBySubtotal},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateZone handles POST /api/admin/shipping/zones
+func (h *ShippingHandler) CreateZone(w http.ResponseWriter, r *http.Request) {
+	var input ShippingZoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	zone := models.ShippingZone{Countries: input.Countries}
+	if input.Name != nil {
+		zone.Name = *input.Name
+	}
+	if input.Fallback != nil {
+		zone.Fallback = *input.Fallback
+	}
+
+	if err := h.shippingService.SaveZone(&zone); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingZone, zone.ID, audit.ActionCreate, nil, zone)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(zone)
+}
+
+// UpdateZone handles PATCH /api/admin/shipping/zones/{id}
+func (h *ShippingHandler) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseShippingID(w, r, "Invalid shipping zone ID")
+	if !ok {
+		return
+	}
+
+	var input ShippingZoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	zone, err := h.shippingService.GetZone(id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	before := *zone
+
+	if input.Name != nil {
+		zone.Name = *input.Name
+	}
+	if input.Countries != nil {
+		zone.Countries = input.Countries
+	}
+	if input.Fallback != nil {
+		zone.Fallback = *input.Fallback
+	}
+
+	if err := h.shippingService.SaveZone(zone); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingZone, zone.ID, audit.ActionUpdate, before, zone)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(zone)
+}
+
+// DeleteZone handles DELETE /api/admin/shipping/zones/{id}
+func (h *ShippingHandler) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseShippingID(w, r, "Invalid shipping zone ID")
+	if !ok {
+		return
+	}
+
+	zone, err := h.shippingService.GetZone(id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.shippingService.DeleteZone(id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingZone, zone.ID, audit.ActionDelete, zone, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateMethod handles POST /api/admin/shipping/methods
+func (h *ShippingHandler) CreateMethod(w http.ResponseWriter, r *http.Request) {
+	var input ShippingMethodInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	method := models.ShippingMethod{Active: true}
+	input.apply(&method)
+
+	if err := h.shippingService.SaveMethod(&method); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingMethod, method.ID, audit.ActionCreate, nil, method)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(method)
+}
+
+// UpdateMethod handles PATCH /api/admin/shipping/methods/{id}
+func (h *ShippingHandler) UpdateMethod(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseShippingID(w, r, "Invalid shipping method ID")
+	if !ok {
+		return
+	}
+
+	var input ShippingMethodInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	method, err := h.shippingService.GetMethod(id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	before := *method
+	before.Rates = append([]models.ShippingRate(nil), method.Rates...)
+
+	input.apply(method)
+
+	if err := h.shippingService.SaveMethod(method); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingMethod, method.ID, audit.ActionUpdate, before, method)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(method)
+}
+
+// DeleteMethod handles DELETE /api/admin/shipping/methods/{id}
+func (h *ShippingHandler) DeleteMethod(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseShippingID(w, r, "Invalid shipping method ID")
+	if !ok {
+		return
+	}
+
+	method, err := h.shippingService.GetMethod(id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.shippingService.DeleteMethod(id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityShippingMethod, method.ID, audit.ActionDelete, method, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply copies the fields present in the input onto the method
+func (input *ShippingMethodInput) apply(method *models.ShippingMethod) {
+	if input.ZoneID != 0 {
+		method.ZoneID = input.ZoneID
+	}
+	if input.Code != nil {
+		method.Code = *input.Code
+	}
+	if input.Name != nil {
+		method.Name = *input.Name
+	}
+	if input.Description != nil {
+		method.Description = *input.Description
+	}
+	if input.RateBasis != nil {
+		method.RateBasis = *input.RateBasis
+	}
+	if input.FreeShippingThreshold != nil {
+		method.FreeShippingThreshold = *input.FreeShippingThreshold
+	}
+	if input.Currency != nil {
+		method.Currency = *input.Currency
+	}
+	if input.Active != nil {
+		method.Active = *input.Active
+	}
+	if input.Position != nil {
+		method.Position = *input.Position
+	}
+	if input.Rates != nil {
+		method.Rates = input.Rates
+	}
+}
+
+// parseShippingID reads the {id} route variable, writing a 400 when it is invalid
+func parseShippingID(w http.ResponseWriter, r *http.Request, message string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, message, http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeError maps shipping service errors to HTTP status codes
+func (h *ShippingHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, shipping.ErrZoneNotFound), errors.Is(err, shipping.ErrMethodNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, shipping.ErrInvalidZone), errors.Is(err, shipping.ErrInvalidMethod):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
+	"github.com/gorilla/mux"
+)
+
+// TaxHandler handles admin tax rate operations
+type TaxHandler struct {
+	taxService   *tax.Service
+	auditService *audit.Service
+}
+
+// NewTaxHandler creates a new admin tax handler
+func NewTaxHandler(taxService *tax.Service, auditService *audit.Service) *TaxHandler {
+	return &TaxHandler{
+		taxService:   taxService,
+		auditService: auditService,
+	}
+}
+
+// TaxRateInput represents the input for creating/updating a tax rate
+type TaxRateInput struct {
+	Country  string          `json:"country"`
+	TaxClass models.TaxClass `json:"tax_class"`
+	Rate     *int64          `json:"rate"` // Basis points: 2200 = 22%
+	Name     *string         `json:"name"`
+}
+
+// ListRates handles GET /api/admin/tax/rates
+func (h *TaxHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.taxService.ListRates(r.URL.Query().Get("country"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"rates":              rates,
+		"tax_classes":        models.TaxClasses,
+		"prices_include_tax": h.taxService.PricesIncludeTax(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// CreateRate handles POST /api/admin/tax/rates
+func (h *TaxHandler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	var input TaxRateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if input.Rate == nil {
+		http.Error(w, "Rate is required", http.StatusBadRequest)
+		return
+	}
+
+	rate := models.TaxRate{
+		Country:  input.Country,
+		TaxClass: input.TaxClass,
+		Rate:     *input.Rate,
+	}
+	if input.Name != nil {
+		rate.Name = *input.Name
+	}
+
+	if err := h.taxService.CreateRate(&rate); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityTaxRate, rate.ID, audit.ActionCreate, nil, rate)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rate)
+}
+
+// UpdateRate handles PATCH /api/admin/tax/rates/{id}
+func (h *TaxHandler) UpdateRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid tax rate ID", http.StatusBadRequest)
+		return
+	}
+
+	var input TaxRateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rate, err := h.taxService.GetRate(uint(id))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	before := *rate
+
+	if input.Country != "" {
+		rate.Country = input.Country
+	}
+	if input.TaxClass != "" {
+		rate.TaxClass = input.TaxClass
+	}
+	if input.Rate != nil {
+		rate.Rate = *input.Rate
+	}
+	if input.Name != nil {
+		rate.Name = *input.Name
+	}
+
+	if err := h.taxService.UpdateRate(rate); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityTaxRate, rate.ID, audit.ActionUpdate, before, rate)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+}
+
+// DeleteRate handles DELETE /api/admin/tax/rates/{id}
+func (h *TaxHandler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid tax rate ID", http.StatusBadRequest)
+		return
+	}
+
+	rate, err := h.taxService.GetRate(uint(id))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	if err := h.taxService.DeleteRate(uint(id)); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityTaxRate, rate.ID, audit.ActionDelete, rate, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError maps tax service errors to HTTP status codes
+func (h *TaxHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, tax.ErrRateNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, tax.ErrDuplicateRate):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, tax.ErrInvalidCountry), errors.Is(err, tax.ErrInvalidTaxClass), errors.Is(err, tax.ErrInvalidRate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
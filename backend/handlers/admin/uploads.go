@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/Naim0996/art-management-tool/backend/services/upload"
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
@@ -16,13 +18,15 @@ import (
 type UploadHandler struct {
 	db            *gorm.DB
 	uploadService *upload.Service
+	auditService  *audit.Service
 }
 
 // NewUploadHandler creates a new upload handler
-func NewUploadHandler(db *gorm.DB) *UploadHandler {
+func NewUploadHandler(db *gorm.DB, auditService *audit.Service) *UploadHandler {
 	return &UploadHandler{
 		db:            db,
 		uploadService: upload.NewService(nil), // Use default config
+		auditService:  auditService,
 	}
 }
 
@@ -94,6 +98,8 @@ func (h *UploadHandler) UploadProductImage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProductImage, productImage.ID, audit.ActionCreate, nil, productImage)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -136,6 +142,8 @@ func (h *UploadHandler) DeleteProductImage(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProductImage, image.ID, audit.ActionDelete, image, nil)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Image deleted successfully",
@@ -167,6 +175,12 @@ func (h *UploadHandler) UpdateProductImagePosition(w http.ResponseWriter, r *htt
 		return
 	}
 
+	var image models.ProductImage
+	if err := h.db.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+
 	// Update image record
 	result := h.db.Model(&models.ProductImage{}).
 		Where("id = ? AND product_id = ?", imageID, productID).
@@ -185,6 +199,11 @@ func (h *UploadHandler) UpdateProductImagePosition(w http.ResponseWriter, r *htt
 		return
 	}
 
+	updated := image
+	updated.Position = req.Position
+	updated.AltText = req.AltText
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityProductImage, image.ID, audit.ActionUpdate, image, updated)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Image updated successfully",
@@ -0,0 +1,250 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
+	"github.com/gorilla/mux"
+)
+
+// UserHandler handles admin user and role management
+type UserHandler struct {
+	authService  *auth.Service
+	auditService *audit.Service
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(authService *auth.Service, auditService *audit.Service) *UserHandler {
+	return &UserHandler{
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// AdminUserResponse is the JSON representation of an admin user
+type AdminUserResponse struct {
+	ID          uint              `json:"id"`
+	Username    string            `json:"username"`
+	Email       string            `json:"email,omitempty"`
+	Active      bool              `json:"active"`
+	Roles       []string          `json:"roles"`
+	Permissions []auth.Permission `json:"permissions"`
+	LastLoginAt *time.Time        `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// toAdminUserResponse converts an admin user to its response format
+func toAdminUserResponse(user *models.AdminUser) AdminUserResponse {
+	names := user.RoleNames()
+	roles := make([]auth.Role, len(names))
+	for i, name := range names {
+		roles[i] = auth.Role(name)
+	}
+
+	return AdminUserResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Active:      user.Active,
+		Roles:       names,
+		Permissions: auth.PermissionsFor(roles),
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+// ListUsers handles GET /api/admin/users
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.authService.ListAdmins()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	responses := make([]AdminUserResponse, len(users))
+	for i := range users {
+		responses[i] = toAdminUserResponse(&users[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users": responses,
+		"total": len(responses),
+	})
+}
+
+// GetUser handles GET /api/admin/users/{id}
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authService.GetAdmin(uint(id))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// CreateUser handles POST /api/admin/users
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string      `json:"username"`
+		Email    string      `json:"email"`
+		Password string      `json:"password"`
+		Roles    []auth.Role `json:"roles"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authService.CreateAdmin(req.Username, req.Email, req.Password, req.Roles)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityAdminUser, user.ID, audit.ActionCreate, nil, toAdminUserResponse(user))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// UpdateUser handles PATCH /api/admin/users/{id}
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Email    *string `json:"email"`
+		Password *string `json:"password"`
+		Active   *bool   `json:"active"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.authService.GetAdmin(uint(id))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	user, err := h.authService.UpdateAdmin(uint(id), &auth.UpdateAdminRequest{
+		Email:    req.Email,
+		Password: req.Password,
+		Active:   req.Active,
+	})
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	after := toAdminUserResponse(user)
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityAdminUser, user.ID, audit.ActionUpdate, toAdminUserResponse(before), after)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(after)
+}
+
+// SetUserRoles handles PUT /api/admin/users/{id}/roles
+func (h *UserHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Roles []auth.Role `json:"roles"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.authService.GetAdmin(uint(id))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	user, err := h.authService.SetRoles(uint(id), req.Roles)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityAdminUser, user.ID, audit.ActionUpdate, toAdminUserResponse(before), toAdminUserResponse(user))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdminUserResponse(user))
+}
+
+// DeleteUser handles DELETE /api/admin/users/{id}
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.authService.GetAdmin(uint(id))
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	if err := h.authService.DeleteAdmin(uint(id)); err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityAdminUser, before.ID, audit.ActionDelete, toAdminUserResponse(before), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRoles handles GET /api/admin/roles
+func (h *UserHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"roles": auth.RoleRegistry(),
+	})
+}
+
+// writeUserError maps auth service errors to HTTP responses
+func writeUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, auth.ErrUsernameTaken), errors.Is(err, auth.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, auth.ErrInvalidRole), errors.Is(err, auth.ErrNoRoles),
+		errors.Is(err, auth.ErrWeakPassword), errors.Is(err, auth.ErrUsernameRequired):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
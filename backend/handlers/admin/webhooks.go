@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/webhook"
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler handles admin webhook event operations
+type WebhookHandler struct {
+	webhookService *webhook.Service
+}
+
+// NewWebhookHandler creates a new admin webhook handler
+func NewWebhookHandler(webhookService *webhook.Service) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListEvents handles GET /api/admin/webhooks/events
+func (h *WebhookHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	filters := webhook.DefaultFilters()
+
+	query := r.URL.Query()
+
+	if provider := query.Get("provider"); provider != "" {
+		filters.Provider = provider
+	}
+
+	if status := query.Get("status"); status != "" {
+		filters.Status = models.WebhookEventStatus(status)
+	}
+
+	if eventType := query.Get("event_type"); eventType != "" {
+		filters.EventType = eventType
+	}
+
+	if page := query.Get("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil && p > 0 {
+			filters.Page = p
+		}
+	}
+
+	if perPage := query.Get("per_page"); perPage != "" {
+		if pp, err := strconv.Atoi(perPage); err == nil && pp > 0 && pp <= 100 {
+			filters.PerPage = pp
+		}
+	}
+
+	events, total, err := h.webhookService.List(filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"events":   events,
+		"total":    total,
+		"page":     filters.Page,
+		"per_page": filters.PerPage,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetEvent handles GET /api/admin/webhooks/events/{id}
+func (h *WebhookHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.webhookService.GetEvent(uint(id))
+	if err != nil {
+		if errors.Is(err, webhook.ErrEventNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
+
+// ReplayEvent handles POST /api/admin/webhooks/events/{id}/replay
+func (h *WebhookHandler) ReplayEvent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseUint(vars["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.webhookService.Replay(uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, webhook.ErrEventNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case errors.Is(err, webhook.ErrEventBusy):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case event == nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Processing failed: the event records the error, report it to the caller
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
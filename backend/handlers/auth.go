@@ -2,7 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
 )
 
 type LoginRequest struct {
@@ -11,28 +16,106 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  string `json:"user"`
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             string    `json:"user"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AuthHandler handles admin authentication
+type AuthHandler struct {
+	authService *auth.Service
 }
 
-// Login handles admin authentication
-func Login(w http.ResponseWriter, r *http.Request) {
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authService *auth.Service) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// Login handles POST /api/auth/login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// In a real application, validate credentials against a database
-	// For demo purposes, we'll accept a simple combination
-	if req.Username == "artadmin" && req.Password == "ArtM@nag3r2025!" {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(LoginResponse{
-			Token: "demo-token-12345",
-			User:  req.Username,
-		})
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Username and password are required", http.StatusBadRequest)
 		return
 	}
 
-	http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+	pair, user, err := h.authService.Login(req.Username, req.Password, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrUserInactive) {
+			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, pair, user.Username)
+}
+
+// Refresh handles POST /api/auth/refresh
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	pair, user, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	writeTokenResponse(w, pair, user.Username)
+}
+
+// Logout handles POST /api/auth/logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	identity, ok := middleware.GetIdentity(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.Logout(identity.SessionID); err != nil {
+		http.Error(w, "Logout failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me handles GET /api/auth/me
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	identity, ok := middleware.GetIdentity(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(identity)
+}
+
+// writeTokenResponse writes a token pair as a login response
+func writeTokenResponse(w http.ResponseWriter, pair *auth.TokenPair, username string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LoginResponse{
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.ExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		User:             username,
+	})
 }
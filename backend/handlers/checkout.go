@@ -40,11 +40,12 @@ func Checkout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate total
-	var total float64
+	total := models.NewMoney(0, models.DefaultCurrency)
 	productsMu.RLock()
 	for _, item := range cart.Items {
 		if product, exists := products[item.ProductID]; exists {
-			total += product.Price * float64(item.Quantity)
+			price := models.MoneyFromFloat(product.Price, models.DefaultCurrency)
+			total = total.Add(price.Mul(int64(item.Quantity)))
 		}
 	}
 	productsMu.RUnlock()
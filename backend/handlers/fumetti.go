@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
@@ -54,12 +56,13 @@ func toFumettoResponse(f models.Fumetto) FumettoResponse {
 
 // FumettiHandler gestisce le operazioni CRUD sui fumetti
 type FumettiHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *audit.Service
 }
 
 // NewFumettiHandler crea un nuovo handler per i fumetti
-func NewFumettiHandler(db *gorm.DB) *FumettiHandler {
-	return &FumettiHandler{db: db}
+func NewFumettiHandler(db *gorm.DB, auditService *audit.Service) *FumettiHandler {
+	return &FumettiHandler{db: db, auditService: auditService}
 }
 
 // GetFumetti restituisce tutti i fumetti non cancellati
@@ -144,6 +147,8 @@ func (h *FumettiHandler) CreateFumetto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionCreate, nil, fumetto)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(toFumettoResponse(fumetto))
@@ -179,6 +184,8 @@ func (h *FumettiHandler) UpdateFumetto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := fumetto
+
 	fumetto.Title = input.Title
 	fumetto.Description = input.Description
 	fumetto.CoverImage = input.CoverImage
@@ -192,6 +199,8 @@ func (h *FumettiHandler) UpdateFumetto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionUpdate, before, fumetto)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(toFumettoResponse(fumetto))
 }
@@ -214,6 +223,8 @@ func (h *FumettiHandler) DeleteFumetto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := fumetto
+
 	now := time.Now()
 	fumetto.DeletedAt = &now
 
@@ -224,6 +235,8 @@ func (h *FumettiHandler) DeleteFumetto(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionDelete, before, nil)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Fumetto deleted successfully",
@@ -254,6 +267,8 @@ func (h *FumettiHandler) RestoreFumetto(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	before := fumetto
+
 	fumetto.DeletedAt = nil
 
 	result = h.db.Save(&fumetto)
@@ -263,6 +278,8 @@ func (h *FumettiHandler) RestoreFumetto(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionRestore, before, fumetto)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(toFumettoResponse(fumetto))
 }
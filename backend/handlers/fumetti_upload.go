@@ -9,7 +9,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -82,6 +84,8 @@ func (h *FumettiHandler) UploadPage(w http.ResponseWriter, r *http.Request) {
 	// Genera URL pubblico
 	publicURL := fmt.Sprintf("/uploads/fumetti/%d/%s", fumetto.ID, filename)
 
+	before := fumetto
+
 	// Aggiorna il fumetto
 	if uploadType == "cover" {
 		fumetto.CoverImage = publicURL
@@ -99,6 +103,8 @@ func (h *FumettiHandler) UploadPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionUpdate, before, fumetto)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Page uploaded successfully",
@@ -129,6 +135,8 @@ func (h *FumettiHandler) DeletePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before := fumetto
+
 	if req.Type == "cover" {
 		// Elimina file fisico se esiste
 		if fumetto.CoverImage != "" && strings.HasPrefix(fumetto.CoverImage, "/uploads/") {
@@ -161,6 +169,8 @@ func (h *FumettiHandler) DeletePage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityFumetto, fumetto.ID, audit.ActionUpdate, before, fumetto)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Page deleted successfully",
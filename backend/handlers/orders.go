@@ -2,20 +2,23 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
 	"github.com/gorilla/mux"
 	"gorm.io/gorm"
 )
 
 type OrdersHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	orderService *order.Service
 }
 
-func NewOrdersHandler(db *gorm.DB) *OrdersHandler {
-	return &OrdersHandler{db: db}
+func NewOrdersHandler(db *gorm.DB, orderService *order.Service) *OrdersHandler {
+	return &OrdersHandler{db: db, orderService: orderService}
 }
 
 // GetOrders returns all orders with optional filters
@@ -79,37 +82,29 @@ func (h *OrdersHandler) UpdateOrderStatus(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Validate status
-	validStatuses := map[string]bool{
-		"pending":    true,
-		"processing": true,
-		"completed":  true,
-		"cancelled":  true,
-	}
-
-	if !validStatuses[input.Status] {
-		http.Error(w, "Invalid status", http.StatusBadRequest)
-		return
-	}
-
-	var order models.Order
-	if err := h.db.First(&order, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	// The order state machine validates the status and the transition
+	if err := h.orderService.UpdatePaymentStatus(uint(id), models.PaymentStatus(input.Status)); err != nil {
+		switch {
+		case errors.Is(err, order.ErrOrderNotFound):
 			http.Error(w, "Order not found", http.StatusNotFound)
-			return
+		case errors.Is(err, order.ErrUnknownStatus):
+			http.Error(w, "Invalid status", http.StatusBadRequest)
+		case errors.Is(err, order.ErrInvalidTransition):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	order.PaymentStatus = models.PaymentStatus(input.Status)
-	if err := h.db.Save(&order).Error; err != nil {
+	updated, err := h.orderService.GetOrder(uint(id))
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(order)
+	json.NewEncoder(w).Encode(updated)
 }
 
 // GetOrderStats returns statistics about orders
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/gorilla/mux"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -72,12 +74,13 @@ func toResponse(p models.Personaggio) PersonaggioResponse {
 
 // PersonaggiHandler gestisce le operazioni CRUD sui personaggi
 type PersonaggiHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *audit.Service
 }
 
 // NewPersonaggiHandler crea un nuovo handler per i personaggi
-func NewPersonaggiHandler(db *gorm.DB) *PersonaggiHandler {
-	return &PersonaggiHandler{db: db}
+func NewPersonaggiHandler(db *gorm.DB, auditService *audit.Service) *PersonaggiHandler {
+	return &PersonaggiHandler{db: db, auditService: auditService}
 }
 
 // GetPersonaggi restituisce tutti i personaggi non cancellati
@@ -172,6 +175,8 @@ func (h *PersonaggiHandler) CreatePersonaggio(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionCreate, nil, personaggio)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(toResponse(personaggio))
@@ -207,6 +212,8 @@ func (h *PersonaggiHandler) UpdatePersonaggio(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	before := personaggio
+
 	personaggio.Name = input.Name
 	personaggio.Description = input.Description
 	personaggio.Icon = input.Icon
@@ -224,6 +231,8 @@ func (h *PersonaggiHandler) UpdatePersonaggio(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionUpdate, before, personaggio)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(toResponse(personaggio))
 }
@@ -246,6 +255,8 @@ func (h *PersonaggiHandler) DeletePersonaggio(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	before := personaggio
+
 	now := time.Now()
 	personaggio.DeletedAt = &now
 
@@ -256,6 +267,8 @@ func (h *PersonaggiHandler) DeletePersonaggio(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionDelete, before, nil)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Personaggio deleted successfully",
@@ -286,6 +299,8 @@ func (h *PersonaggiHandler) RestorePersonaggio(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	before := personaggio
+
 	personaggio.DeletedAt = nil
 
 	result = h.db.Save(&personaggio)
@@ -295,6 +310,8 @@ func (h *PersonaggiHandler) RestorePersonaggio(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionRestore, before, personaggio)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(toResponse(personaggio))
 }
@@ -9,7 +9,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -82,6 +84,8 @@ func (h *PersonaggiHandler) UploadImage(w http.ResponseWriter, r *http.Request)
 	// Genera URL pubblico
 	publicURL := fmt.Sprintf("/uploads/personaggi/%d/%s", personaggio.ID, filename)
 
+	before := personaggio
+
 	// Aggiorna il personaggio
 	if uploadType == "icon" {
 		personaggio.Icon = publicURL
@@ -101,6 +105,8 @@ func (h *PersonaggiHandler) UploadImage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionUpdate, before, personaggio)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Image uploaded successfully",
@@ -131,6 +137,8 @@ func (h *PersonaggiHandler) DeleteImage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	before := personaggio
+
 	if req.Type == "icon" {
 		// Elimina file fisico se esiste
 		if personaggio.Icon != "" && strings.HasPrefix(personaggio.Icon, "/uploads/") {
@@ -163,6 +171,8 @@ func (h *PersonaggiHandler) DeleteImage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	h.auditService.Record(middleware.Actor(r.Context()), audit.EntityPersonaggio, personaggio.ID, audit.ActionUpdate, before, personaggio)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"message": "Image deleted successfully",
@@ -0,0 +1,431 @@
+package shop
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
+	"github.com/Naim0996/art-management-tool/backend/services/cart"
+	"github.com/Naim0996/art-management-tool/backend/services/customer"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/ratelimit"
+	"github.com/gorilla/mux"
+)
+
+// AccountHandler handles customer accounts and their order history
+type AccountHandler struct {
+	customerService *customer.Service
+	orderService    *order.Service
+	cartService     *cart.Service
+	access          *order.AccessSigner
+	attempts        *ratelimit.Manager // Logins and emails sent per client IP
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(customerService *customer.Service, orderService *order.Service, cartService *cart.Service, access *order.AccessSigner) *AccountHandler {
+	return &AccountHandler{
+		customerService: customerService,
+		orderService:    orderService,
+		cartService:     cartService,
+		access:          access,
+		attempts:        ratelimit.NewManager(10, time.Minute),
+	}
+}
+
+// CustomerTokenResponse is returned on customer login and refresh
+type CustomerTokenResponse struct {
+	Token            string           `json:"token"`
+	ExpiresAt        time.Time        `json:"expires_at"`
+	RefreshToken     string           `json:"refresh_token"`
+	RefreshExpiresAt time.Time        `json:"refresh_expires_at"`
+	Customer         *models.Customer `json:"customer"`
+}
+
+// Register handles POST /api/shop/account/register
+func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if !h.allow(w, r) {
+		return
+	}
+
+	var req customer.RegisterInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.customerService.Register(req); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Verify handles POST /api/shop/account/verify
+func (h *AccountHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	account, claimed, err := h.customerService.Verify(req.Token)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"customer":       account,
+		"claimed_orders": claimed,
+	})
+}
+
+// ResendVerification handles POST /api/shop/account/verify/resend
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	h.sendEmail(w, r, h.customerService.ResendVerification)
+}
+
+// ForgotPassword handles POST /api/shop/account/password/forgot
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	h.sendEmail(w, r, h.customerService.RequestPasswordReset)
+}
+
+// ResetPassword handles POST /api/shop/account/password/reset
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "token and password are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.customerService.ResetPassword(req.Token, req.Password); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Login handles POST /api/shop/account/login
+func (h *AccountHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if !h.allow(w, r) {
+		return
+	}
+
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		http.Error(w, "Email and password are required", http.StatusBadRequest)
+		return
+	}
+
+	pair, account, err := h.customerService.Login(req.Email, req.Password, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	h.mergeCart(w, r, account.ID)
+	writeCustomerTokens(w, pair, account)
+}
+
+// mergeCart merges the guest cart of the request into the customer's cart and points the
+// cart cookie at it. A failed merge does not fail the login: the guest cart is kept and
+// merged on the next cart request.
+func (h *AccountHandler) mergeCart(w http.ResponseWriter, r *http.Request, customerID uint) {
+	sessionToken := r.Header.Get("X-Cart-Session")
+	if cookie, err := r.Cookie("cart_session"); err == nil {
+		sessionToken = cookie.Value
+	}
+
+	merged, err := h.cartService.MergeGuestCart(sessionToken, customerID)
+	if err != nil {
+		if !errors.Is(err, cart.ErrCartNotFound) {
+			log.Printf("Failed to merge cart of customer %d: %v", customerID, err)
+		}
+		return
+	}
+	setCartCookie(w, merged.SessionToken)
+}
+
+// Refresh handles POST /api/shop/account/refresh
+func (h *AccountHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	pair, account, err := h.customerService.Refresh(req.RefreshToken)
+	if err != nil {
+		http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	writeCustomerTokens(w, pair, account)
+}
+
+// Logout handles POST /api/shop/account/logout
+func (h *AccountHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	if err := h.customerService.Logout(identity.SessionID); err != nil {
+		http.Error(w, "Logout failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProfile handles GET /api/shop/me
+func (h *AccountHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	account, err := h.customerService.Get(identity.CustomerID)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// UpdateProfile handles PATCH /api/shop/me
+func (h *AccountHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	var req struct {
+		Name  string `json:"name"`
+		Phone string `json:"phone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	account, err := h.customerService.UpdateProfile(identity.CustomerID, req.Name, req.Phone)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// ChangePassword handles POST /api/shop/me/password
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := h.customerService.ChangePassword(identity.CustomerID, identity.SessionID, req.CurrentPassword, req.NewPassword)
+	if errors.Is(err, customer.ErrInvalidCredentials) {
+		http.Error(w, "Current password is incorrect", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListOrders handles GET /api/shop/me/orders?page=&per_page=
+func (h *AccountHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+
+	page, perPage := 1, 20
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	if pp, err := strconv.Atoi(r.URL.Query().Get("per_page")); err == nil && pp > 0 && pp <= 100 {
+		perPage = pp
+	}
+
+	orders, total, err := h.orderService.ListForCustomer(identity.CustomerID, page, perPage)
+	if err != nil {
+		http.Error(w, "Failed to load orders", http.StatusInternalServerError)
+		return
+	}
+
+	views := make([]*order.CustomerView, len(orders))
+	for i := range orders {
+		views[i] = order.NewCustomerView(&orders[i])
+		views[i].AccessToken = h.access.Token(orders[i].OrderNumber)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"orders":   views,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
+
+// ListAddresses handles GET /api/shop/me/addresses
+func (h *AccountHandler) ListAddresses(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	addresses, err := h.customerService.ListAddresses(identity.CustomerID)
+	if err != nil {
+		http.Error(w, "Failed to load addresses", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"addresses": addresses,
+	})
+}
+
+// CreateAddress handles POST /api/shop/me/addresses
+func (h *AccountHandler) CreateAddress(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	var req customer.AddressInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	address, err := h.customerService.CreateAddress(identity.CustomerID, req)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(address)
+}
+
+// UpdateAddress handles PATCH /api/shop/me/addresses/{id}
+func (h *AccountHandler) UpdateAddress(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid address ID", http.StatusBadRequest)
+		return
+	}
+
+	var req customer.AddressInput
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	address, err := h.customerService.UpdateAddress(identity.CustomerID, uint(id), req)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(address)
+}
+
+// DeleteAddress handles DELETE /api/shop/me/addresses/{id}
+func (h *AccountHandler) DeleteAddress(w http.ResponseWriter, r *http.Request) {
+	identity, _ := middleware.GetCustomer(r.Context())
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid address ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.customerService.DeleteAddress(identity.CustomerID, uint(id)); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendEmail reads an email address and runs an action that may email it. The answer
+// is the same whether or not the address has an account.
+func (h *AccountHandler) sendEmail(w http.ResponseWriter, r *http.Request, action func(email string) error) {
+	if !h.allow(w, r) {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := action(req.Email); err != nil {
+		http.Error(w, "Failed to send email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// allow applies the per-IP limit on logins and emails sent
+func (h *AccountHandler) allow(w http.ResponseWriter, r *http.Request) bool {
+	if !h.attempts.Allow(middleware.ClientIP(r)) {
+		http.Error(w, "Too many attempts, try again later", http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+// writeCustomerTokens writes a token pair as a login response
+func writeCustomerTokens(w http.ResponseWriter, pair *auth.TokenPair, account *models.Customer) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CustomerTokenResponse{
+		Token:            pair.AccessToken,
+		ExpiresAt:        pair.ExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		Customer:         account,
+	})
+}
+
+// writeAccountError maps customer service errors to HTTP responses
+func writeAccountError(w http.ResponseWriter, err error) {
+	var validation models.ValidationErrors
+	switch {
+	case errors.As(err, &validation), errors.Is(err, auth.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, customer.ErrInvalidToken):
+		http.Error(w, "Invalid or expired link", http.StatusBadRequest)
+	case errors.Is(err, customer.ErrInvalidCredentials), errors.Is(err, customer.ErrCustomerInactive):
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+	case errors.Is(err, customer.ErrEmailNotVerified):
+		http.Error(w, "Email address not verified", http.StatusForbidden)
+	case errors.Is(err, customer.ErrCustomerNotFound), errors.Is(err, customer.ErrAddressNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "Account request failed", http.StatusInternalServerError)
+	}
+}
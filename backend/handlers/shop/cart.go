@@ -2,11 +2,15 @@ package shop
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
 	"github.com/Naim0996/art-management-tool/backend/services/cart"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
 	"github.com/gorilla/mux"
 )
 
@@ -28,26 +32,37 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	_, cookieErr := r.Cookie("cart_session")
 	log.Printf("📦 GetCart - Session Token: %s, Cookie exists: %v", sessionToken, cookieErr == nil)
 
-	// Set session cookie to ensure it's always available
-	h.setSessionCookie(w, sessionToken)
-
-	cart, err := h.cartService.GetOrCreateCart(sessionToken, nil)
+	cart, err := h.cartService.GetOrCreateCart(sessionToken, middleware.CustomerID(r.Context()))
 	if err != nil {
 		log.Printf("❌ GetCart - Error getting cart: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	// Set session cookie to ensure it's always available; signed-in customers get
+	// their account cart's token
+	h.setSessionCookie(w, cart.SessionToken)
 	log.Printf("✅ GetCart - Cart ID: %d, Items: %d", cart.ID, len(cart.Items))
 
 	// Calculate totals
-	subtotal, tax, discount, total := h.cartService.CalculateTotal(cart)
+	totals, err := h.cartService.CalculateTotal(cart, r.URL.Query().Get("country"), models.Money{})
+	if err != nil {
+		if errors.Is(err, tax.ErrInvalidCountry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	response := map[string]interface{}{
-		"cart":     cart,
-		"subtotal": subtotal,
-		"tax":      tax,
-		"discount": discount,
-		"total":    total,
+		"cart":               cart,
+		"subtotal":           totals.Subtotal,
+		"tax":                totals.Tax,
+		"discount":           totals.Discount,
+		"total":              totals.Total,
+		"tax_country":        totals.Country,
+		"prices_include_tax": totals.PricesIncludeTax,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -77,27 +92,37 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🛒 AddItem - Session Token: %s, Cookie exists: %v, ProductID: %d, Quantity: %d",
 		sessionToken, cookieErr == nil, req.ProductID, req.Quantity)
 
-	// Set session cookie if not exists
-	h.setSessionCookie(w, sessionToken)
-	log.Printf("🍪 AddItem - Setting cookie with token: %s", sessionToken)
-
-	cart, err := h.cartService.AddItem(sessionToken, req.ProductID, req.VariantID, req.Quantity)
+	cart, err := h.cartService.AddItem(sessionToken, middleware.CustomerID(r.Context()), req.ProductID, req.VariantID, req.Quantity)
 	if err != nil {
 		log.Printf("❌ AddItem - Error adding item: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+
+	// Set session cookie; signed-in customers get their account cart's token
+	h.setSessionCookie(w, cart.SessionToken)
+	log.Printf("🍪 AddItem - Setting cookie with token: %s", cart.SessionToken)
 	log.Printf("✅ AddItem - Cart ID: %d, Total items: %d", cart.ID, len(cart.Items))
 
 	// Calculate totals like GetCart does
-	subtotal, tax, discount, total := h.cartService.CalculateTotal(cart)
+	totals, err := h.cartService.CalculateTotal(cart, r.URL.Query().Get("country"), models.Money{})
+	if err != nil {
+		if errors.Is(err, tax.ErrInvalidCountry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	response := map[string]interface{}{
-		"cart":     cart,
-		"subtotal": subtotal,
-		"tax":      tax,
-		"discount": discount,
-		"total":    total,
+		"cart":               cart,
+		"subtotal":           totals.Subtotal,
+		"tax":                totals.Tax,
+		"discount":           totals.Discount,
+		"total":              totals.Total,
+		"tax_country":        totals.Country,
+		"prices_include_tax": totals.PricesIncludeTax,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -130,21 +155,32 @@ func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
 
 	sessionToken := h.getSessionToken(r)
 
-	cart, err := h.cartService.UpdateItemQuantity(sessionToken, uint(itemID), req.Quantity)
+	cart, err := h.cartService.UpdateItemQuantity(sessionToken, middleware.CustomerID(r.Context()), uint(itemID), req.Quantity)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	h.setSessionCookie(w, cart.SessionToken)
 
 	// Calculate totals like GetCart does
-	subtotal, tax, discount, total := h.cartService.CalculateTotal(cart)
+	totals, err := h.cartService.CalculateTotal(cart, r.URL.Query().Get("country"), models.Money{})
+	if err != nil {
+		if errors.Is(err, tax.ErrInvalidCountry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	response := map[string]interface{}{
-		"cart":     cart,
-		"subtotal": subtotal,
-		"tax":      tax,
-		"discount": discount,
-		"total":    total,
+		"cart":               cart,
+		"subtotal":           totals.Subtotal,
+		"tax":                totals.Tax,
+		"discount":           totals.Discount,
+		"total":              totals.Total,
+		"tax_country":        totals.Country,
+		"prices_include_tax": totals.PricesIncludeTax,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -162,11 +198,12 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 
 	sessionToken := h.getSessionToken(r)
 
-	_, err = h.cartService.RemoveItem(sessionToken, uint(itemID))
+	cart, err := h.cartService.RemoveItem(sessionToken, middleware.CustomerID(r.Context()), uint(itemID))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	h.setSessionCookie(w, cart.SessionToken)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -175,7 +212,7 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	sessionToken := h.getSessionToken(r)
 
-	if err := h.cartService.ClearCart(sessionToken); err != nil {
+	if err := h.cartService.ClearCart(sessionToken, middleware.CustomerID(r.Context())); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -207,6 +244,11 @@ func (h *CartHandler) getSessionToken(r *http.Request) string {
 
 // setSessionCookie sets the cart session cookie
 func (h *CartHandler) setSessionCookie(w http.ResponseWriter, sessionToken string) {
+	setCartCookie(w, sessionToken)
+}
+
+// setCartCookie sets the cart session cookie
+func setCartCookie(w http.ResponseWriter, sessionToken string) {
 	cookie := &http.Cookie{
 		Name:     "cart_session",
 		Value:    sessionToken,
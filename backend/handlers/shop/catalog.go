@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/cart"
 	"github.com/Naim0996/art-management-tool/backend/services/product"
 	"github.com/gorilla/mux"
 )
@@ -13,12 +14,14 @@ import (
 // CatalogHandler handles public catalog operations
 type CatalogHandler struct {
 	productService *product.Service
+	cartService    *cart.Service // Subtracts the stock held in carts
 }
 
 // NewCatalogHandler creates a new catalog handler
-func NewCatalogHandler(productService *product.Service) *CatalogHandler {
+func NewCatalogHandler(productService *product.Service, cartService *cart.Service) *CatalogHandler {
 	return &CatalogHandler{
 		productService: productService,
+		cartService:    cartService,
 	}
 }
 
@@ -52,13 +55,13 @@ func (h *CatalogHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if minPrice := query.Get("min_price"); minPrice != "" {
-		if price, err := strconv.ParseFloat(minPrice, 64); err == nil {
+		if price, err := models.ParseMoney(minPrice, models.DefaultCurrency); err == nil {
 			filters.MinPrice = price
 		}
 	}
 
 	if maxPrice := query.Get("max_price"); maxPrice != "" {
-		if price, err := strconv.ParseFloat(maxPrice, 64); err == nil {
+		if price, err := models.ParseMoney(maxPrice, models.DefaultCurrency); err == nil {
 			filters.MaxPrice = price
 		}
 	}
@@ -96,6 +99,10 @@ func (h *CatalogHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := h.cartService.ApplyReservations(products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	response := map[string]interface{}{
 		"products": products,
@@ -125,6 +132,12 @@ func (h *CatalogHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	products := []models.EnhancedProduct{*product}
+	if err := h.cartService.ApplyReservations(products); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(products[0])
 }
@@ -2,33 +2,42 @@ package shop
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/Naim0996/art-management-tool/backend/middleware"
 	"github.com/Naim0996/art-management-tool/backend/models"
 	"github.com/Naim0996/art-management-tool/backend/services/cart"
+	"github.com/Naim0996/art-management-tool/backend/services/customer"
 	"github.com/Naim0996/art-management-tool/backend/services/order"
 	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/shipping"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
 	"gorm.io/gorm"
 )
 
 // CheckoutHandler handles checkout operations
 type CheckoutHandler struct {
-	db                 *gorm.DB
-	cartService        *cart.Service
-	orderService       *order.Service
-	paymentProvider    payment.Provider
-	etsyPaymentProvider payment.Provider
+	db              *gorm.DB
+	cartService     *cart.Service
+	orderService    *order.Service
+	shippingService *shipping.Service
+	payments        *payment.Registry
+	access          *order.AccessSigner
+	customerService *customer.Service
 }
 
 // NewCheckoutHandler creates a new checkout handler
-func NewCheckoutHandler(db *gorm.DB, cartService *cart.Service, orderService *order.Service, paymentProvider payment.Provider, etsyPaymentProvider payment.Provider) *CheckoutHandler {
+func NewCheckoutHandler(db *gorm.DB, cartService *cart.Service, orderService *order.Service, shippingService *shipping.Service, payments *payment.Registry, access *order.AccessSigner, customerService *customer.Service) *CheckoutHandler {
 	return &CheckoutHandler{
-		db:                 db,
-		cartService:        cartService,
-		orderService:       orderService,
-		paymentProvider:    paymentProvider,
-		etsyPaymentProvider: etsyPaymentProvider,
+		db:              db,
+		cartService:     cartService,
+		orderService:    orderService,
+		shippingService: shippingService,
+		payments:        payments,
+		access:          access,
+		customerService: customerService,
 	}
 }
 
@@ -40,6 +49,23 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
+	// Signed-in customers can pick saved addresses instead of typing them
+	customerID := middleware.CustomerID(r.Context())
+	if customerID == nil && (req.AddressID != nil || req.BillingAddressID != nil) {
+		http.Error(w, "Sign in to use saved addresses", http.StatusUnauthorized)
+		return
+	}
+	if customerID != nil {
+		if err := h.applySavedAddresses(*customerID, &req); err != nil {
+			if errors.Is(err, customer.ErrAddressNotFound) {
+				http.Error(w, "Address not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	
 	// Validate request
 	if req.Email == "" || req.Name == "" {
 		http.Error(w, "Email and name are required", http.StatusBadRequest)
@@ -62,8 +88,8 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 		sessionToken = cookie.Value
 	}
 	
-	// Get cart
-	cart, err := h.cartService.GetOrCreateCart(sessionToken, nil)
+	// Get cart; the order belongs to the signed-in customer, or to nobody for guests
+	cart, err := h.cartService.GetOrCreateCart(sessionToken, customerID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -79,6 +105,7 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	cart.UserID = customerID
 	
 	// Validate discount code if provided
 	var discountCode *models.DiscountCode
@@ -97,26 +124,39 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 		}
 	}
 	
+	// Select shipping method, the cheapest available when none was chosen
+	shippingQuote, err := h.shippingService.Select(cart, req.ShippingAddress.Country, req.ShippingMethodID)
+	if err != nil {
+		if errors.Is(err, shipping.ErrMethodUnavailable) || errors.Is(err, shipping.ErrNoShippingAvailable) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
 	// Select payment provider based on payment method
-	selectedProvider := h.paymentProvider
-	if req.PaymentMethod == models.PaymentMethodEtsy && h.etsyPaymentProvider != nil {
-		selectedProvider = h.etsyPaymentProvider
+	provider, err := h.payments.ForMethod(req.PaymentMethod)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 	
-	// Temporarily switch provider in order service for this request
-	originalProvider := h.orderService.GetPaymentProvider()
-	h.orderService.SetPaymentProvider(selectedProvider)
-	defer h.orderService.SetPaymentProvider(originalProvider)
+	// Hold the cart's stock again, so that no other cart's hold is sold
+	if err := h.cartService.ReserveCart(cart); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	
 	// Create order with payment
-	order, paymentIntent, err := h.orderService.CreateOrder(cart, &req, discountCode)
+	order, paymentIntent, err := h.orderService.CreateOrder(cart, &req, discountCode, shippingQuote, provider)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	
 	// Clear cart after successful order creation
-	h.cartService.ClearCart(sessionToken)
+	h.cartService.ClearCart(cart.SessionToken, nil)
 	
 	// Return response
 	response := models.CheckoutResponse{
@@ -126,6 +166,7 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 		ClientSecret:    paymentIntent.ClientSecret,
 		Total:           order.Total,
 		Status:          string(order.PaymentStatus),
+		AccessToken:     h.access.Token(order.OrderNumber),
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -136,7 +177,8 @@ func (h *CheckoutHandler) ProcessCheckout(w http.ResponseWriter, r *http.Request
 // ApplyDiscount handles POST /api/shop/cart/discount
 func (h *CheckoutHandler) ApplyDiscount(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Code string `json:"code"`
+		Code    string `json:"code"`
+		Country string `json:"country,omitempty"` // Shipping country for VAT, defaults to the shop's
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -163,30 +205,45 @@ func (h *CheckoutHandler) ApplyDiscount(w http.ResponseWriter, r *http.Request)
 	
 	// Get cart
 	sessionToken := h.getSessionToken(r)
-	cart, err := h.cartService.GetOrCreateCart(sessionToken, nil)
+	cart, err := h.cartService.GetOrCreateCart(sessionToken, middleware.CustomerID(r.Context()))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	
 	// Calculate totals
-	subtotal, tax, _, total := h.cartService.CalculateTotal(cart)
-	discountAmount := discount.CalculateDiscount(subtotal)
+	before, err := h.cartService.CalculateTotal(cart, req.Country, models.Money{})
+	if err != nil {
+		if errors.Is(err, tax.ErrInvalidCountry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	discountAmount := discount.CalculateDiscount(before.Subtotal)
 	
-	if discountAmount == 0 {
+	if discountAmount.IsZero() {
 		http.Error(w, "Discount code cannot be applied to this order", http.StatusBadRequest)
 		return
 	}
 	
+	after, err := h.cartService.CalculateTotal(cart, req.Country, discountAmount)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	
 	response := map[string]interface{}{
-		"discount_code":   discount.Code,
-		"discount_type":   discount.Type,
-		"discount_value":  discount.Value,
-		"discount_amount": discountAmount,
-		"subtotal":        subtotal,
-		"tax":             tax,
-		"total_before":    total,
-		"total_after":     total - discountAmount,
+		"discount_code":      discount.Code,
+		"discount_type":      discount.Type,
+		"discount_value":     discount.Value,
+		"discount_amount":    discountAmount,
+		"subtotal":           before.Subtotal,
+		"tax":                after.Tax,
+		"total_before":       before.Total,
+		"total_after":        after.Total,
+		"prices_include_tax": after.PricesIncludeTax,
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -202,3 +259,42 @@ func (h *CheckoutHandler) getSessionToken(r *http.Request) string {
 	
 	return cart.GenerateSessionToken()
 }
+
+// applySavedAddresses fills the checkout addresses from the customer's address book:
+// the picked address IDs, or the default addresses when none was typed
+func (h *CheckoutHandler) applySavedAddresses(customerID uint, req *models.CheckoutRequest) error {
+	shipping, err := h.savedAddress(customerID, req.AddressID, req.ShippingAddress, false)
+	if err != nil {
+		return err
+	}
+	if shipping != nil {
+		req.ShippingAddress = *shipping
+	}
+
+	billing, err := h.savedAddress(customerID, req.BillingAddressID, req.BillingAddress, true)
+	if err != nil {
+		return err
+	}
+	if billing != nil {
+		req.BillingAddress = *billing
+	}
+	return nil
+}
+
+// savedAddress returns the saved address to use in place of a typed one, if any
+func (h *CheckoutHandler) savedAddress(customerID uint, id *uint, typed models.Address, billing bool) (*models.Address, error) {
+	var saved *models.CustomerAddress
+	var err error
+	switch {
+	case id != nil:
+		saved, err = h.customerService.GetAddress(customerID, *id)
+	case typed.Street == "":
+		saved, err = h.customerService.DefaultAddress(customerID, billing)
+	}
+	if err != nil || saved == nil {
+		return nil, err
+	}
+
+	address := saved.Address()
+	return &address, nil
+}
@@ -0,0 +1,77 @@
+package shop
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/ratelimit"
+	"github.com/gorilla/mux"
+)
+
+// OrderHandler lets customers look up their orders without an account
+type OrderHandler struct {
+	orderService *order.Service
+	access       *order.AccessSigner
+	lookups      *ratelimit.Manager // Email verifications per client IP
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *order.Service, access *order.AccessSigner) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		access:       access,
+		lookups:      ratelimit.NewManager(10, time.Minute),
+	}
+}
+
+// GetOrder handles GET /api/shop/orders/{order_number}?token=... or ?email=...
+// The access token can also be sent in the X-Order-Token header.
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	orderNumber := mux.Vars(r)["order_number"]
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.Header.Get("X-Order-Token")
+	}
+	email := r.URL.Query().Get("email")
+
+	var o *models.Order
+	var err error
+	switch {
+	case token != "":
+		if !h.access.Verify(orderNumber, token) {
+			http.Error(w, "Order not found", http.StatusNotFound)
+			return
+		}
+		o, err = h.orderService.GetByNumber(orderNumber)
+	case email != "":
+		if !h.lookups.Allow(middleware.ClientIP(r)) {
+			http.Error(w, "Too many lookups, try again later", http.StatusTooManyRequests)
+			return
+		}
+		o, err = h.orderService.FindForCustomer(orderNumber, email)
+	default:
+		http.Error(w, "token or email is required", http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, order.ErrOrderNotFound) {
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load order", http.StatusInternalServerError)
+		return
+	}
+
+	// Customers who verified by email get the token for later visits
+	view := order.NewCustomerView(o)
+	view.AccessToken = h.access.Token(o.OrderNumber)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(view)
+}
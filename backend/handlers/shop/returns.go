@@ -0,0 +1,70 @@
+package shop
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/returns"
+)
+
+// ReturnHandler handles return requests from customers
+type ReturnHandler struct {
+	returnService *returns.Service
+}
+
+// NewReturnHandler creates a new return handler
+func NewReturnHandler(returnService *returns.Service) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+// OpenReturn handles POST /api/shop/returns
+func (h *ReturnHandler) OpenReturn(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		OrderNumber string `json:"order_number"`
+		Email       string `json:"email"`
+		Items       []struct {
+			OrderItemID uint `json:"order_item_id"`
+			Quantity    int  `json:"quantity"`
+		} `json:"items"`
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.OrderNumber == "" || req.Email == "" {
+		http.Error(w, "order_number and email are required", http.StatusBadRequest)
+		return
+	}
+
+	items := make([]models.ReturnItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, models.ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+
+	ret, err := h.returnService.OpenForCustomer(req.OrderNumber, req.Email, items, req.Reason)
+	switch {
+	case errors.Is(err, order.ErrOrderNotFound):
+		http.Error(w, "Order not found", http.StatusNotFound)
+		return
+	case errors.Is(err, returns.ErrInvalidReturn):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to open return", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"rma_number": ret.RMANumber,
+		"status":     ret.Status,
+		"items":      ret.Items,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,63 @@
+package shop
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/services/cart"
+	"github.com/Naim0996/art-management-tool/backend/services/shipping"
+)
+
+// ShippingHandler handles shipping quotes for the shop
+type ShippingHandler struct {
+	cartService     *cart.Service
+	shippingService *shipping.Service
+}
+
+// NewShippingHandler creates a new shipping handler
+func NewShippingHandler(cartService *cart.Service, shippingService *shipping.Service) *ShippingHandler {
+	return &ShippingHandler{
+		cartService:     cartService,
+		shippingService: shippingService,
+	}
+}
+
+// GetQuotes handles GET /api/shop/shipping/quote?country=
+func (h *ShippingHandler) GetQuotes(w http.ResponseWriter, r *http.Request) {
+	country := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("country")))
+	if len(country) != 2 {
+		http.Error(w, "country must be an ISO 3166-1 alpha-2 code", http.StatusBadRequest)
+		return
+	}
+
+	sessionToken := r.Header.Get("X-Cart-Session")
+	if cookie, err := r.Cookie("cart_session"); err == nil {
+		sessionToken = cookie.Value
+	}
+	if sessionToken == "" {
+		http.Error(w, "Cart session not found", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.cartService.GetOrCreateCart(sessionToken, middleware.CustomerID(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	quotes, err := h.shippingService.Quote(cart, country)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"country": country,
+		"methods": quotes,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
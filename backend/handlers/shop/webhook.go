@@ -1,25 +1,25 @@
 package shop
 
 import (
-	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 
-	"github.com/Naim0996/art-management-tool/backend/services/order"
 	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/webhook"
 )
 
 // WebhookHandler handles payment webhooks
 type WebhookHandler struct {
-	orderService    *order.Service
-	paymentProvider payment.Provider
+	payments       *payment.Registry
+	webhookService *webhook.Service
 }
 
 // NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(orderService *order.Service, paymentProvider payment.Provider) *WebhookHandler {
+func NewWebhookHandler(payments *payment.Registry, webhookService *webhook.Service) *WebhookHandler {
 	return &WebhookHandler{
-		orderService:    orderService,
-		paymentProvider: paymentProvider,
+		payments:       payments,
+		webhookService: webhookService,
 	}
 }
 
@@ -35,57 +35,58 @@ func (h *WebhookHandler) HandleStripeWebhook(w http.ResponseWriter, r *http.Requ
 	// Get signature from headers
 	signature := r.Header.Get("Stripe-Signature")
 	
-	// Verify signature (if using real Stripe provider)
-	if stripeProvider, ok := h.paymentProvider.(*payment.StripeProvider); ok {
-		if err := stripeProvider.VerifyWebhookSignature(body, signature); err != nil {
-			http.Error(w, "Invalid signature", http.StatusUnauthorized)
-			return
-		}
-	}
-	
-	// Parse webhook event
-	var event struct {
-		Type string `json:"type"`
-		Data struct {
-			Object struct {
-				ID       string                 `json:"id"`
-				Amount   int64                  `json:"amount"`
-				Currency string                 `json:"currency"`
-				Status   string                 `json:"status"`
-				Metadata map[string]string      `json:"metadata"`
-			} `json:"object"`
-		} `json:"data"`
+	// Verify signature with the registered Stripe provider
+	provider, _ := h.payments.ByName(webhook.ProviderStripe)
+	stripeProvider, ok := provider.(*payment.StripeProvider)
+	if !ok {
+		http.Error(w, "Stripe payments are not configured", http.StatusNotImplemented)
+		return
 	}
-	
-	if err := json.Unmarshal(body, &event); err != nil {
-		http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+	if err := stripeProvider.VerifyWebhookSignature(body, signature); err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
 		return
 	}
 	
-	// Handle event based on type
-	switch event.Type {
-	case "payment_intent.succeeded":
-		err = h.orderService.HandlePaymentSuccess(event.Data.Object.ID)
-		
-	case "payment_intent.payment_failed":
-		reason := "Payment failed"
-		if event.Data.Object.Status != "" {
-			reason = event.Data.Object.Status
-		}
-		err = h.orderService.HandlePaymentFailed(event.Data.Object.ID, reason)
-		
-	default:
-		// Unhandled event type - still return 200
-		w.WriteHeader(http.StatusOK)
+	h.receive(w, webhook.ProviderStripe, body)
+}
+
+// HandlePayPalWebhook handles POST /api/webhooks/payment/paypal
+func (h *WebhookHandler) HandlePayPalWebhook(w http.ResponseWriter, r *http.Request) {
+	provider, _ := h.payments.ByName(webhook.ProviderPayPal)
+	paypalProvider, ok := provider.(*payment.PayPalProvider)
+	if !ok {
+		http.Error(w, "PayPal payments are not configured", http.StatusNotImplemented)
 		return
 	}
-	
+
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		// Log error but still return 200 to acknowledge receipt
-		// In production, you might want to retry failed webhook processing
-		w.WriteHeader(http.StatusOK)
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
 		return
 	}
-	
+
+	if err := paypalProvider.VerifyWebhookSignature(r.Header, body); err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	h.receive(w, webhook.ProviderPayPal, body)
+}
+
+// receive stores and processes a verified event.
+// Once the event is stored it is acknowledged with 200 even if processing failed:
+// failed events are retried by the webhook retry worker. Redelivered events are
+// acknowledged without being processed again.
+func (h *WebhookHandler) receive(w http.ResponseWriter, provider string, body []byte) {
+	if _, _, err := h.webhookService.Receive(provider, body); err != nil {
+		if errors.Is(err, webhook.ErrInvalidEvent) {
+			http.Error(w, "Invalid webhook payload", http.StatusBadRequest)
+			return
+		}
+		// Not stored: let the provider redeliver it
+		http.Error(w, "Failed to store webhook event", http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusOK)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
@@ -10,13 +11,26 @@ import (
 	"github.com/Naim0996/art-management-tool/backend/handlers/admin"
 	"github.com/Naim0996/art-management-tool/backend/handlers/shop"
 	"github.com/Naim0996/art-management-tool/backend/middleware"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
 	"github.com/Naim0996/art-management-tool/backend/services/cart"
+	"github.com/Naim0996/art-management-tool/backend/services/customer"
 	"github.com/Naim0996/art-management-tool/backend/services/etsy"
+	"github.com/Naim0996/art-management-tool/backend/services/invoice"
+	"github.com/Naim0996/art-management-tool/backend/services/mail"
 	"github.com/Naim0996/art-management-tool/backend/services/notification"
+	"github.com/Naim0996/art-management-tool/backend/services/numbering"
 	"github.com/Naim0996/art-management-tool/backend/services/order"
 	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/privacy"
 	"github.com/Naim0996/art-management-tool/backend/services/product"
+	"github.com/Naim0996/art-management-tool/backend/services/returns"
+	"github.com/Naim0996/art-management-tool/backend/services/scheduler"
+	"github.com/Naim0996/art-management-tool/backend/services/shipping"
 	"github.com/Naim0996/art-management-tool/backend/services/shopify"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
+	"github.com/Naim0996/art-management-tool/backend/services/webhook"
 	"github.com/gorilla/mux"
 )
 
@@ -59,22 +73,168 @@ func main() {
 
 	log.Println("Database initialized successfully")
 
+	// Initialize authentication
+	if cfg.Auth.JWTSecret == "" {
+		if cfg.IsProduction() {
+			log.Fatal("JWT_SECRET must be set in production")
+		}
+		log.Println("Warning: JWT_SECRET not set, using an insecure development secret")
+		cfg.Auth.JWTSecret = "dev-insecure-secret"
+	}
+	authService := auth.NewService(database.DB, auth.Config{
+		Secret:          cfg.Auth.JWTSecret,
+		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Auth.RefreshTokenTTL,
+	})
+	if err := authService.EnsureBootstrapAdmin(cfg.Auth.BootstrapUsername, cfg.Auth.BootstrapPassword); err != nil {
+		log.Fatal("Failed to create bootstrap admin user:", err)
+	}
+	authMiddleware := middleware.AuthMiddleware(authService)
+	authHandler := handlers.NewAuthHandler(authService)
+
+	// Initialize email delivery and customer accounts. Logged emails carry verification and
+	// password reset links, which must not reach production logs.
+	if cfg.Mail.SMTPHost == "" && cfg.IsProduction() {
+		log.Fatal("SMTP_HOST must be set in production")
+	}
+	var mailer mail.Sender = mail.LogSender{}
+	if cfg.Mail.SMTPHost != "" {
+		mailer = mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
+		})
+	}
+	customerService := customer.NewService(database.DB, mailer, customer.Config{
+		Secret:          cfg.Auth.JWTSecret,
+		AccessTokenTTL:  cfg.Auth.AccessTokenTTL,
+		RefreshTokenTTL: cfg.Customers.RefreshTokenTTL,
+		VerifyTokenTTL:  cfg.Customers.VerifyTokenTTL,
+		ResetTokenTTL:   cfg.Customers.ResetTokenTTL,
+		AppURL:          cfg.Customers.ShopURL,
+	})
+
 	// Initialize services
-	cartService := cart.NewService(database.DB)
+	taxService := tax.NewService(database.DB, tax.Config{
+		PricesIncludeTax: cfg.Tax.PricesIncludeTax,
+		OriginCountry:    cfg.Tax.OriginCountry,
+	})
+	if err := taxService.EnsureDefaultRates(); err != nil {
+		log.Fatal("Failed to seed tax rates:", err)
+	}
+	cartService := cart.NewService(database.DB, taxService, cfg.Cart.ReservationTTL)
+	shippingService := shipping.NewService(database.DB)
 	productService := product.NewService(database.DB)
 	notifService := notification.NewService(database.DB)
+	auditService := audit.NewService(database.DB)
+
+	// Initialize payment provider from configuration
+	var paymentProvider payment.Provider
+	switch cfg.Payment.Provider {
+	case "stripe":
+		if cfg.Payment.StripeWebhookSecret == "" {
+			log.Println("Warning: STRIPE_WEBHOOK_SECRET not set, Stripe webhooks will be rejected")
+		}
+		stripeProvider, err := payment.NewStripeProvider(payment.StripeConfig{
+			APIKey:           cfg.Payment.StripeAPIKey,
+			WebhookSecret:    cfg.Payment.StripeWebhookSecret,
+			BaseURL:          cfg.Payment.StripeBaseURL,
+			WebhookTolerance: cfg.Payment.StripeWebhookTolerance,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize Stripe payment provider:", err)
+		}
+		paymentProvider = stripeProvider
+	case "mock":
+		if cfg.IsProduction() {
+			log.Println("Warning: using the mock payment provider in production")
+		}
+		paymentProvider = payment.NewMockProvider("mock", 1, false) // Minimum 1 cent
+	default:
+		log.Fatalf("Unknown PAYMENT_PROVIDER %q (expected stripe or mock)", cfg.Payment.Provider)
+	}
+	log.Printf("Payment provider: %s", paymentProvider.Name())
+
+	// Route checkout payment methods to providers; card payments use the configured provider
+	paymentRegistry := payment.NewRegistry(paymentProvider)
+	paymentRegistry.Register(paymentProvider, models.PaymentMethodCreditCard, models.PaymentMethodStripe)
+
+	// Initialize PayPal provider if configured
+	if cfg.IsPayPalEnabled() {
+		if cfg.Payment.PayPalWebhookID == "" {
+			log.Println("Warning: PAYPAL_WEBHOOK_ID not set, PayPal webhooks will be rejected")
+		}
+		paypalProvider, err := payment.NewPayPalProvider(payment.PayPalConfig{
+			ClientID:     cfg.Payment.PayPalClientID,
+			ClientSecret: cfg.Payment.PayPalClientSecret,
+			WebhookID:    cfg.Payment.PayPalWebhookID,
+			BaseURL:      cfg.Payment.PayPalBaseURL,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize PayPal payment provider:", err)
+		}
+		paymentRegistry.Register(paypalProvider, models.PaymentMethodPayPal)
+		log.Println("PayPal payments enabled")
+	}
 
-	// Initialize payment provider (use mock for development)
-	paymentProvider := payment.NewMockProvider("mock", 1, false) // Minimum 1 cent
-	// For production with Stripe:
-	// paymentProvider := payment.NewStripeProvider()
+	// Order, refund and invoice numbers come from per-year counters in the database
+	orderNumbers, err := numbering.NewGenerator(numbering.SequenceOrder, cfg.Orders.NumberFormat)
+	if err != nil {
+		log.Fatal("Invalid ORDER_NUMBER_FORMAT:", err)
+	}
+	refundNumbers, err := numbering.NewGenerator(numbering.SequenceRefund, cfg.Orders.RefundNumberFormat)
+	if err != nil {
+		log.Fatal("Invalid REFUND_NUMBER_FORMAT:", err)
+	}
+	invoiceNumbers, err := numbering.NewGenerator(numbering.SequenceInvoice, cfg.Invoice.NumberFormat)
+	if err != nil {
+		log.Fatal("Invalid INVOICE_NUMBER_FORMAT:", err)
+	}
+	for _, generator := range []*numbering.Generator{orderNumbers, refundNumbers, invoiceNumbers} {
+		if err := generator.CheckPeriod(database.DB); err != nil {
+			log.Fatal("Invalid number format:", err)
+		}
+	}
 
-	orderService := order.NewService(database.DB, paymentProvider, notifService)
+	orderService := order.NewService(database.DB, paymentRegistry, taxService, notifService, orderNumbers, refundNumbers)
+	returnService := returns.NewService(database.DB, orderService, productService, notifService)
+	invoiceService := invoice.NewService(database.DB, invoice.Config{
+		Seller: invoice.Seller{
+			Name:         cfg.Invoice.SellerName,
+			AddressLines: cfg.Invoice.SellerAddress,
+			VATID:        cfg.Invoice.SellerVATID,
+			Email:        cfg.Invoice.SellerEmail,
+			TaxCode:      cfg.Invoice.SellerTaxCode,
+			TaxRegime:    cfg.Invoice.SellerTaxRegime,
+			Street:       cfg.Invoice.SellerStreet,
+			ZipCode:      cfg.Invoice.SellerZipCode,
+			City:         cfg.Invoice.SellerCity,
+			Province:     cfg.Invoice.SellerProvince,
+			Country:      cfg.Invoice.SellerCountry,
+		},
+		Numbers: invoiceNumbers,
+	})
+	privacyService := privacy.NewService(database.DB, invoiceService)
 	shopifyService := shopify.NewSyncService(database.DB, "", "", "")
 
+	// Webhook events are stored before processing; failed ones are retried in the background
+	retryPolicy := webhook.DefaultRetryPolicy()
+	retryPolicy.MaxAttempts = cfg.Webhook.MaxAttempts
+	webhookService := webhook.NewService(database.DB, orderService, paymentRegistry, retryPolicy)
+
+	jobScheduler := scheduler.NewScheduler()
+	jobScheduler.AddJob("webhook-retry", cfg.Webhook.RetryInterval, webhookService.RetryFailed)
+	jobScheduler.AddJob("order-expiry", cfg.Orders.ExpiryInterval, func(ctx context.Context) error {
+		return orderService.ExpirePendingOrders(ctx, cfg.Orders.PendingTTL)
+	})
+	if cfg.Cart.ReservationTTL > 0 {
+		jobScheduler.AddJob("stock-reservation-release", cfg.Cart.ReleaseInterval, cartService.ReleaseExpiredReservations)
+	}
+
 	// Initialize Etsy integration if configured
 	var etsyService *etsy.Service
-	var etsyPaymentProvider payment.Provider
 	var etsyOAuthManager *etsy.OAuthManager
 	if cfg.IsEtsyEnabled() {
 		// Initialize OAuth manager first
@@ -109,11 +269,11 @@ func main() {
 
 			etsyService = etsy.NewService(database.DB, etsyClient)
 			// Initialize Etsy payment provider
-			etsyPaymentProvider = payment.NewEtsyProvider(
+			paymentRegistry.Register(payment.NewEtsyProvider(
 				cfg.Etsy.ShopName,
 				cfg.Etsy.ShopURL,
 				cfg.Etsy.PaymentCallbackURL,
-			)
+			), models.PaymentMethodEtsy)
 			log.Println("Etsy integration enabled (including payment and OAuth)")
 		}
 	} else {
@@ -121,38 +281,52 @@ func main() {
 	}
 
 	// Create shop handlers
-	catalogHandler := shop.NewCatalogHandler(productService)
+	catalogHandler := shop.NewCatalogHandler(productService, cartService)
 	cartHandler := shop.NewCartHandler(cartService)
-	checkoutHandler := shop.NewCheckoutHandler(database.DB, cartService, orderService, paymentProvider, etsyPaymentProvider)
-	webhookHandler := shop.NewWebhookHandler(orderService, paymentProvider)
+	orderAccess := order.NewAccessSigner(cfg.Auth.JWTSecret)
+	checkoutHandler := shop.NewCheckoutHandler(database.DB, cartService, orderService, shippingService, paymentRegistry, orderAccess, customerService)
+	shippingHandler := shop.NewShippingHandler(cartService, shippingService)
+	webhookHandler := shop.NewWebhookHandler(paymentRegistry, webhookService)
+	returnHandler := shop.NewReturnHandler(returnService)
+	shopOrderHandler := shop.NewOrderHandler(orderService, orderAccess)
+	accountHandler := shop.NewAccountHandler(customerService, orderService, cartService, orderAccess)
 
 	// Create admin handlers
-	adminProductHandler := admin.NewProductHandler(productService)
-	adminOrderHandler := admin.NewOrderHandler(orderService)
-	adminUploadHandler := admin.NewUploadHandler(database.DB)
+	adminProductHandler := admin.NewProductHandler(productService, auditService)
+	adminOrderHandler := admin.NewOrderHandler(orderService, auditService)
+	adminReturnHandler := admin.NewReturnHandler(returnService, auditService)
+	adminInvoiceHandler := admin.NewInvoiceHandler(invoiceService, auditService)
+	adminUploadHandler := admin.NewUploadHandler(database.DB, auditService)
 	adminNotifHandler := admin.NewNotificationHandler(notifService)
-	adminCategoryHandler := admin.NewCategoryHandler(database.DB)
-	adminDiscountHandler := admin.NewDiscountHandler(database.DB)
+	adminCategoryHandler := admin.NewCategoryHandler(database.DB, auditService)
+	adminDiscountHandler := admin.NewDiscountHandler(database.DB, auditService)
+	adminTaxHandler := admin.NewTaxHandler(taxService, auditService)
+	adminShippingHandler := admin.NewShippingHandler(shippingService, auditService)
+	adminUserHandler := admin.NewUserHandler(authService, auditService)
+	adminAuditHandler := admin.NewAuditHandler(auditService)
+	adminPrivacyHandler := admin.NewPrivacyHandler(privacyService, auditService)
+	adminWebhookHandler := admin.NewWebhookHandler(webhookService)
 
 	// Create Etsy handler if service is available
 	var adminEtsyHandler *admin.EtsyHandler
 	var adminEtsyOAuthHandler *admin.EtsyOAuthHandler
 	if etsyService != nil {
-		adminEtsyHandler = admin.NewEtsyHandler(etsyService)
+		adminEtsyHandler = admin.NewEtsyHandler(etsyService, auditService)
 	}
 	if etsyOAuthManager != nil {
 		adminEtsyOAuthHandler = admin.NewEtsyOAuthHandler(database.DB, etsyOAuthManager)
 	}
 
 	// Legacy handlers
-	personaggiHandler := handlers.NewPersonaggiHandler(database.DB)
-	fumettiHandler := handlers.NewFumettiHandler(database.DB)
+	personaggiHandler := handlers.NewPersonaggiHandler(database.DB, auditService)
+	fumettiHandler := handlers.NewFumettiHandler(database.DB, auditService)
 
 	r := mux.NewRouter()
 
 	// ===== Enhanced Shop API (New) =====
 	// Public shop endpoints
 	shopRouter := r.PathPrefix("/api/shop").Subrouter()
+	shopRouter.Use(middleware.CustomerMiddleware(customerService))
 	shopRouter.HandleFunc("/products", catalogHandler.ListProducts).Methods("GET")
 	shopRouter.HandleFunc("/products/{slug}", catalogHandler.GetProduct).Methods("GET")
 	shopRouter.HandleFunc("/categories", handlers.ListPublicCategories(database.DB)).Methods("GET")
@@ -163,64 +337,128 @@ func main() {
 	shopRouter.HandleFunc("/cart/items/{id}", cartHandler.RemoveItem).Methods("DELETE")
 	shopRouter.HandleFunc("/cart", cartHandler.ClearCart).Methods("DELETE")
 	shopRouter.HandleFunc("/cart/discount", checkoutHandler.ApplyDiscount).Methods("POST")
+	shopRouter.HandleFunc("/shipping/quote", shippingHandler.GetQuotes).Methods("GET")
 	shopRouter.HandleFunc("/checkout", checkoutHandler.ProcessCheckout).Methods("POST")
+	shopRouter.HandleFunc("/returns", returnHandler.OpenReturn).Methods("POST")
+	shopRouter.HandleFunc("/orders/{order_number}", shopOrderHandler.GetOrder).Methods("GET")
+
+	// Customer accounts
+	customerOnly := func(handler http.HandlerFunc) http.Handler {
+		return middleware.RequireCustomer(handler)
+	}
+	shopRouter.HandleFunc("/account/register", accountHandler.Register).Methods("POST")
+	shopRouter.HandleFunc("/account/verify", accountHandler.Verify).Methods("POST")
+	shopRouter.HandleFunc("/account/verify/resend", accountHandler.ResendVerification).Methods("POST")
+	shopRouter.HandleFunc("/account/login", accountHandler.Login).Methods("POST")
+	shopRouter.HandleFunc("/account/refresh", accountHandler.Refresh).Methods("POST")
+	shopRouter.Handle("/account/logout", customerOnly(accountHandler.Logout)).Methods("POST")
+	shopRouter.HandleFunc("/account/password/forgot", accountHandler.ForgotPassword).Methods("POST")
+	shopRouter.HandleFunc("/account/password/reset", accountHandler.ResetPassword).Methods("POST")
+	shopRouter.Handle("/me", customerOnly(accountHandler.GetProfile)).Methods("GET")
+	shopRouter.Handle("/me", customerOnly(accountHandler.UpdateProfile)).Methods("PATCH")
+	shopRouter.Handle("/me/password", customerOnly(accountHandler.ChangePassword)).Methods("POST")
+	shopRouter.Handle("/me/orders", customerOnly(accountHandler.ListOrders)).Methods("GET")
+	shopRouter.Handle("/me/addresses", customerOnly(accountHandler.ListAddresses)).Methods("GET")
+	shopRouter.Handle("/me/addresses", customerOnly(accountHandler.CreateAddress)).Methods("POST")
+	shopRouter.Handle("/me/addresses/{id}", customerOnly(accountHandler.UpdateAddress)).Methods("PATCH")
+	shopRouter.Handle("/me/addresses/{id}", customerOnly(accountHandler.DeleteAddress)).Methods("DELETE")
 
 	// Webhook endpoints (public but verified)
 	r.HandleFunc("/api/webhooks/payment/stripe", webhookHandler.HandleStripeWebhook).Methods("POST")
+	r.HandleFunc("/api/webhooks/payment/paypal", webhookHandler.HandlePayPalWebhook).Methods("POST")
 
 	// ===== Admin API (Enhanced) =====
 	adminRouter := r.PathPrefix("/api/admin").Subrouter()
-	adminRouter.Use(middleware.AuthMiddleware)
+	adminRouter.Use(authMiddleware)
+
+	// can wraps an admin handler with a permission check from the role registry
+	can := func(perm auth.Permission, handler http.HandlerFunc) http.Handler {
+		return middleware.RequirePermission(perm)(handler)
+	}
 
 	// Stats (existing)
-	adminRouter.HandleFunc("/stats", handlers.GetDashboardStats(database.DB)).Methods("GET")
+	adminRouter.Handle("/stats", can(auth.PermStatsRead, handlers.GetDashboardStats(database.DB))).Methods("GET")
 
 	// Enhanced product management
-	adminRouter.HandleFunc("/shop/products", adminProductHandler.ListProducts).Methods("GET")
-	adminRouter.HandleFunc("/shop/products", adminProductHandler.CreateProduct).Methods("POST")
-	adminRouter.HandleFunc("/shop/products/{id}", adminProductHandler.GetProduct).Methods("GET")
-	adminRouter.HandleFunc("/shop/products/{id}", adminProductHandler.UpdateProduct).Methods("PATCH")
-	adminRouter.HandleFunc("/shop/products/{id}", adminProductHandler.DeleteProduct).Methods("DELETE")
-	adminRouter.HandleFunc("/shop/products/{id}/variants", adminProductHandler.AddVariant).Methods("POST")
-	adminRouter.HandleFunc("/shop/variants/{id}", adminProductHandler.UpdateVariant).Methods("PATCH")
-	adminRouter.HandleFunc("/shop/inventory/adjust", adminProductHandler.UpdateInventory).Methods("POST")
+	adminRouter.Handle("/shop/products", can(auth.PermProductsRead, adminProductHandler.ListProducts)).Methods("GET")
+	adminRouter.Handle("/shop/products", can(auth.PermProductsWrite, adminProductHandler.CreateProduct)).Methods("POST")
+	adminRouter.Handle("/shop/products/{id}", can(auth.PermProductsRead, adminProductHandler.GetProduct)).Methods("GET")
+	adminRouter.Handle("/shop/products/{id}", can(auth.PermProductsWrite, adminProductHandler.UpdateProduct)).Methods("PATCH")
+	adminRouter.Handle("/shop/products/{id}", can(auth.PermProductsWrite, adminProductHandler.DeleteProduct)).Methods("DELETE")
+	adminRouter.Handle("/shop/products/{id}/variants", can(auth.PermProductsWrite, adminProductHandler.AddVariant)).Methods("POST")
+	adminRouter.Handle("/shop/variants/{id}", can(auth.PermProductsWrite, adminProductHandler.UpdateVariant)).Methods("PATCH")
+	adminRouter.Handle("/shop/inventory/adjust", can(auth.PermInventoryWrite, adminProductHandler.UpdateInventory)).Methods("POST")
 
 	// Product image upload management
-	adminRouter.HandleFunc("/shop/products/{id}/images", adminUploadHandler.ListProductImages).Methods("GET")
-	adminRouter.HandleFunc("/shop/products/{id}/images", adminUploadHandler.UploadProductImage).Methods("POST")
-	adminRouter.HandleFunc("/shop/products/{id}/images/{imageId}", adminUploadHandler.UpdateProductImagePosition).Methods("PATCH")
-	adminRouter.HandleFunc("/shop/products/{id}/images/{imageId}", adminUploadHandler.DeleteProductImage).Methods("DELETE")
+	adminRouter.Handle("/shop/products/{id}/images", can(auth.PermProductsRead, adminUploadHandler.ListProductImages)).Methods("GET")
+	adminRouter.Handle("/shop/products/{id}/images", can(auth.PermProductsWrite, adminUploadHandler.UploadProductImage)).Methods("POST")
+	adminRouter.Handle("/shop/products/{id}/images/{imageId}", can(auth.PermProductsWrite, adminUploadHandler.UpdateProductImagePosition)).Methods("PATCH")
+	adminRouter.Handle("/shop/products/{id}/images/{imageId}", can(auth.PermProductsWrite, adminUploadHandler.DeleteProductImage)).Methods("DELETE")
 
 	// Enhanced order management
-	adminRouter.HandleFunc("/shop/orders", adminOrderHandler.ListOrders).Methods("GET")
-	adminRouter.HandleFunc("/shop/orders/{id}", adminOrderHandler.GetOrder).Methods("GET")
-	adminRouter.HandleFunc("/shop/orders/{id}/fulfillment", adminOrderHandler.UpdateFulfillmentStatus).Methods("PATCH")
-	adminRouter.HandleFunc("/shop/orders/{id}/refund", adminOrderHandler.RefundOrder).Methods("POST")
+	adminRouter.Handle("/shop/orders", can(auth.PermOrdersRead, adminOrderHandler.ListOrders)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}", can(auth.PermOrdersRead, adminOrderHandler.GetOrder)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/fulfillment", can(auth.PermOrdersFulfill, adminOrderHandler.UpdateFulfillmentStatus)).Methods("PATCH")
+	adminRouter.Handle("/shop/orders/{id}/refund", can(auth.PermOrdersRefund, adminOrderHandler.RefundOrder)).Methods("POST")
+	adminRouter.Handle("/shop/orders/{id}/refunds", can(auth.PermOrdersRead, adminOrderHandler.ListRefunds)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/cancel", can(auth.PermOrdersCancel, adminOrderHandler.CancelOrder)).Methods("POST")
+	adminRouter.Handle("/shop/orders/{id}/shipments", can(auth.PermOrdersRead, adminOrderHandler.ListShipments)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/shipments", can(auth.PermOrdersFulfill, adminOrderHandler.CreateShipment)).Methods("POST")
+	adminRouter.Handle("/shop/orders/{id}/shipments/{shipmentId}", can(auth.PermOrdersFulfill, adminOrderHandler.UpdateShipment)).Methods("PATCH")
+	adminRouter.Handle("/shop/orders/{id}/shipments/{shipmentId}", can(auth.PermOrdersFulfill, adminOrderHandler.DeleteShipment)).Methods("DELETE")
+	adminRouter.Handle("/shop/orders/{id}/invoice", can(auth.PermOrdersRead, adminInvoiceHandler.GetInvoice)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/packing-slip", can(auth.PermOrdersRead, adminInvoiceHandler.GetPackingSlip)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/fatturapa", can(auth.PermOrdersRead, adminInvoiceHandler.GetEInvoice)).Methods("GET")
+	adminRouter.Handle("/shop/invoices/fatturapa", can(auth.PermOrdersRead, adminInvoiceHandler.ExportEInvoices)).Methods("GET")
+
+	// Returns (RMA)
+	adminRouter.Handle("/shop/returns", can(auth.PermOrdersRead, adminReturnHandler.ListReturns)).Methods("GET")
+	adminRouter.Handle("/shop/returns/{id}", can(auth.PermOrdersRead, adminReturnHandler.GetReturn)).Methods("GET")
+	adminRouter.Handle("/shop/orders/{id}/returns", can(auth.PermOrdersFulfill, adminReturnHandler.CreateReturn)).Methods("POST")
+	adminRouter.Handle("/shop/returns/{id}/approve", can(auth.PermOrdersFulfill, adminReturnHandler.ApproveReturn)).Methods("POST")
+	adminRouter.Handle("/shop/returns/{id}/reject", can(auth.PermOrdersFulfill, adminReturnHandler.RejectReturn)).Methods("POST")
+	adminRouter.Handle("/shop/returns/{id}/receive", can(auth.PermOrdersFulfill, adminReturnHandler.ReceiveReturn)).Methods("POST")
+	adminRouter.Handle("/shop/returns/{id}/refund", can(auth.PermOrdersRefund, adminReturnHandler.RefundReturn)).Methods("POST")
 
 	// Notifications
-	adminRouter.HandleFunc("/notifications", adminNotifHandler.ListNotifications).Methods("GET")
-	adminRouter.HandleFunc("/notifications/{id}", adminNotifHandler.GetNotification).Methods("GET")
-	adminRouter.HandleFunc("/notifications/{id}/read", adminNotifHandler.MarkAsRead).Methods("PATCH")
-	adminRouter.HandleFunc("/notifications/read-all", adminNotifHandler.MarkAllAsRead).Methods("POST")
-	adminRouter.HandleFunc("/notifications/{id}", adminNotifHandler.Delete).Methods("DELETE")
+	adminRouter.Handle("/notifications", can(auth.PermNotificationsRead, adminNotifHandler.ListNotifications)).Methods("GET")
+	adminRouter.Handle("/notifications/{id}", can(auth.PermNotificationsRead, adminNotifHandler.GetNotification)).Methods("GET")
+	adminRouter.Handle("/notifications/{id}/read", can(auth.PermNotificationsRead, adminNotifHandler.MarkAsRead)).Methods("PATCH")
+	adminRouter.Handle("/notifications/read-all", can(auth.PermNotificationsRead, adminNotifHandler.MarkAllAsRead)).Methods("POST")
+	adminRouter.Handle("/notifications/{id}", can(auth.PermNotificationsWrite, adminNotifHandler.Delete)).Methods("DELETE")
 
 	// Categories
-	adminRouter.HandleFunc("/categories", adminCategoryHandler.ListCategories).Methods("GET")
-	adminRouter.HandleFunc("/categories", adminCategoryHandler.CreateCategory).Methods("POST")
-	adminRouter.HandleFunc("/categories/{id}", adminCategoryHandler.GetCategory).Methods("GET")
-	adminRouter.HandleFunc("/categories/{id}", adminCategoryHandler.UpdateCategory).Methods("PATCH")
-	adminRouter.HandleFunc("/categories/{id}", adminCategoryHandler.DeleteCategory).Methods("DELETE")
+	adminRouter.Handle("/categories", can(auth.PermCategoriesRead, adminCategoryHandler.ListCategories)).Methods("GET")
+	adminRouter.Handle("/categories", can(auth.PermCategoriesWrite, adminCategoryHandler.CreateCategory)).Methods("POST")
+	adminRouter.Handle("/categories/{id}", can(auth.PermCategoriesRead, adminCategoryHandler.GetCategory)).Methods("GET")
+	adminRouter.Handle("/categories/{id}", can(auth.PermCategoriesWrite, adminCategoryHandler.UpdateCategory)).Methods("PATCH")
+	adminRouter.Handle("/categories/{id}", can(auth.PermCategoriesWrite, adminCategoryHandler.DeleteCategory)).Methods("DELETE")
 
 	// Discount codes
-	adminRouter.HandleFunc("/discounts", adminDiscountHandler.ListDiscounts).Methods("GET")
-	adminRouter.HandleFunc("/discounts", adminDiscountHandler.CreateDiscount).Methods("POST")
-	adminRouter.HandleFunc("/discounts/{id}", adminDiscountHandler.GetDiscount).Methods("GET")
-	adminRouter.HandleFunc("/discounts/{id}", adminDiscountHandler.UpdateDiscount).Methods("PATCH")
-	adminRouter.HandleFunc("/discounts/{id}", adminDiscountHandler.DeleteDiscount).Methods("DELETE")
-	adminRouter.HandleFunc("/discounts/{id}/stats", adminDiscountHandler.GetDiscountStats).Methods("GET")
+	adminRouter.Handle("/discounts", can(auth.PermDiscountsRead, adminDiscountHandler.ListDiscounts)).Methods("GET")
+	adminRouter.Handle("/discounts", can(auth.PermDiscountsWrite, adminDiscountHandler.CreateDiscount)).Methods("POST")
+	adminRouter.Handle("/discounts/{id}", can(auth.PermDiscountsRead, adminDiscountHandler.GetDiscount)).Methods("GET")
+	adminRouter.Handle("/discounts/{id}", can(auth.PermDiscountsWrite, adminDiscountHandler.UpdateDiscount)).Methods("PATCH")
+	adminRouter.Handle("/discounts/{id}", can(auth.PermDiscountsWrite, adminDiscountHandler.DeleteDiscount)).Methods("DELETE")
+	adminRouter.Handle("/discounts/{id}/stats", can(auth.PermDiscountsRead, adminDiscountHandler.GetDiscountStats)).Methods("GET")
+
+	// Tax rates
+	adminRouter.Handle("/tax/rates", can(auth.PermTaxRead, adminTaxHandler.ListRates)).Methods("GET")
+	adminRouter.Handle("/tax/rates", can(auth.PermTaxWrite, adminTaxHandler.CreateRate)).Methods("POST")
+	adminRouter.Handle("/tax/rates/{id}", can(auth.PermTaxWrite, adminTaxHandler.UpdateRate)).Methods("PATCH")
+	adminRouter.Handle("/tax/rates/{id}", can(auth.PermTaxWrite, adminTaxHandler.DeleteRate)).Methods("DELETE")
+
+	// Shipping zones and methods
+	adminRouter.Handle("/shipping/zones", can(auth.PermShippingRead, adminShippingHandler.ListZones)).Methods("GET")
+	adminRouter.Handle("/shipping/zones", can(auth.PermShippingWrite, adminShippingHandler.CreateZone)).Methods("POST")
+	adminRouter.Handle("/shipping/zones/{id}", can(auth.PermShippingWrite, adminShippingHandler.UpdateZone)).Methods("PATCH")
+	adminRouter.Handle("/shipping/zones/{id}", can(auth.PermShippingWrite, adminShippingHandler.DeleteZone)).Methods("DELETE")
+	adminRouter.Handle("/shipping/methods", can(auth.PermShippingWrite, adminShippingHandler.CreateMethod)).Methods("POST")
+	adminRouter.Handle("/shipping/methods/{id}", can(auth.PermShippingWrite, adminShippingHandler.UpdateMethod)).Methods("PATCH")
+	adminRouter.Handle("/shipping/methods/{id}", can(auth.PermShippingWrite, adminShippingHandler.DeleteMethod)).Methods("DELETE")
 
 	// Shopify sync (stub)
-	adminRouter.HandleFunc("/shopify/sync", func(w http.ResponseWriter, r *http.Request) {
+	adminRouter.Handle("/shopify/sync", can(auth.PermIntegrationsWrite, func(w http.ResponseWriter, r *http.Request) {
 		if !shopifyService.IsEnabled() {
 			http.Error(w, "Shopify integration not configured", http.StatusNotImplemented)
 			return
@@ -228,71 +466,95 @@ func main() {
 		// Trigger sync in background
 		w.WriteHeader(http.StatusAccepted)
 		w.Write([]byte(`{"message":"Sync initiated"}`))
-	}).Methods("POST")
+	})).Methods("POST")
 
 	// Etsy integration endpoints
 	if adminEtsyHandler != nil {
 		// Product sync
-		adminRouter.HandleFunc("/etsy/sync/products", adminEtsyHandler.TriggerProductSync).Methods("POST")
-		adminRouter.HandleFunc("/etsy/sync/inventory", adminEtsyHandler.TriggerInventorySync).Methods("POST")
-		adminRouter.HandleFunc("/etsy/sync/status", adminEtsyHandler.GetSyncStatus).Methods("GET")
-		adminRouter.HandleFunc("/etsy/sync/logs", adminEtsyHandler.GetInventorySyncLogs).Methods("GET")
+		adminRouter.Handle("/etsy/sync/products", can(auth.PermIntegrationsWrite, adminEtsyHandler.TriggerProductSync)).Methods("POST")
+		adminRouter.Handle("/etsy/sync/inventory", can(auth.PermIntegrationsWrite, adminEtsyHandler.TriggerInventorySync)).Methods("POST")
+		adminRouter.Handle("/etsy/sync/status", can(auth.PermIntegrationsRead, adminEtsyHandler.GetSyncStatus)).Methods("GET")
+		adminRouter.Handle("/etsy/sync/logs", can(auth.PermIntegrationsRead, adminEtsyHandler.GetInventorySyncLogs)).Methods("GET")
 
 		// Product management
-		adminRouter.HandleFunc("/etsy/products", adminEtsyHandler.ListEtsyProducts).Methods("GET")
-		adminRouter.HandleFunc("/etsy/products/{listing_id}", adminEtsyHandler.GetEtsyProduct).Methods("GET")
-		adminRouter.HandleFunc("/etsy/products/{listing_id}/link", adminEtsyHandler.LinkProduct).Methods("POST")
-		adminRouter.HandleFunc("/etsy/products/{listing_id}/link", adminEtsyHandler.UnlinkProduct).Methods("DELETE")
-		adminRouter.HandleFunc("/etsy/products/{listing_id}/sync-images", adminEtsyHandler.SyncProductImages).Methods("POST")
+		adminRouter.Handle("/etsy/products", can(auth.PermIntegrationsRead, adminEtsyHandler.ListEtsyProducts)).Methods("GET")
+		adminRouter.Handle("/etsy/products/{listing_id}", can(auth.PermIntegrationsRead, adminEtsyHandler.GetEtsyProduct)).Methods("GET")
+		adminRouter.Handle("/etsy/products/{listing_id}/link", can(auth.PermIntegrationsWrite, adminEtsyHandler.LinkProduct)).Methods("POST")
+		adminRouter.Handle("/etsy/products/{listing_id}/link", can(auth.PermIntegrationsWrite, adminEtsyHandler.UnlinkProduct)).Methods("DELETE")
+		adminRouter.Handle("/etsy/products/{listing_id}/sync-images", can(auth.PermIntegrationsWrite, adminEtsyHandler.SyncProductImages)).Methods("POST")
 
 		// Receipt/payment sync
-		adminRouter.HandleFunc("/etsy/sync/receipts", adminEtsyHandler.TriggerReceiptSync).Methods("POST")
-		adminRouter.HandleFunc("/etsy/receipts", adminEtsyHandler.ListEtsyReceipts).Methods("GET")
-		adminRouter.HandleFunc("/etsy/receipts/{receipt_id}", adminEtsyHandler.GetEtsyReceipt).Methods("GET")
-		adminRouter.HandleFunc("/etsy/receipts/{receipt_id}/link", adminEtsyHandler.LinkReceiptToOrder).Methods("POST")
-		adminRouter.HandleFunc("/etsy/receipts/{receipt_id}/link", adminEtsyHandler.UnlinkReceiptFromOrder).Methods("DELETE")
+		adminRouter.Handle("/etsy/sync/receipts", can(auth.PermIntegrationsWrite, adminEtsyHandler.TriggerReceiptSync)).Methods("POST")
+		adminRouter.Handle("/etsy/receipts", can(auth.PermIntegrationsRead, adminEtsyHandler.ListEtsyReceipts)).Methods("GET")
+		adminRouter.Handle("/etsy/receipts/{receipt_id}", can(auth.PermIntegrationsRead, adminEtsyHandler.GetEtsyReceipt)).Methods("GET")
+		adminRouter.Handle("/etsy/receipts/{receipt_id}/link", can(auth.PermIntegrationsWrite, adminEtsyHandler.LinkReceiptToOrder)).Methods("POST")
+		adminRouter.Handle("/etsy/receipts/{receipt_id}/link", can(auth.PermIntegrationsWrite, adminEtsyHandler.UnlinkReceiptFromOrder)).Methods("DELETE")
 
 		// Configuration
-		adminRouter.HandleFunc("/etsy/config", adminEtsyHandler.GetEtsyConfig).Methods("GET")
-		adminRouter.HandleFunc("/etsy/validate", adminEtsyHandler.ValidateCredentials).Methods("POST")
+		adminRouter.Handle("/etsy/config", can(auth.PermIntegrationsRead, adminEtsyHandler.GetEtsyConfig)).Methods("GET")
+		adminRouter.Handle("/etsy/validate", can(auth.PermIntegrationsWrite, adminEtsyHandler.ValidateCredentials)).Methods("POST")
 	}
 
 	// Etsy OAuth endpoints (separate from main Etsy handler)
 	if adminEtsyOAuthHandler != nil {
-		adminRouter.HandleFunc("/etsy/oauth/auth-url", adminEtsyOAuthHandler.GetAuthURL).Methods("GET")
-		adminRouter.HandleFunc("/etsy/oauth/callback", adminEtsyOAuthHandler.HandleCallback).Methods("GET")
-		adminRouter.HandleFunc("/etsy/oauth/status", adminEtsyOAuthHandler.GetTokenStatus).Methods("GET")
-		adminRouter.HandleFunc("/etsy/oauth/refresh", adminEtsyOAuthHandler.RefreshToken).Methods("POST")
-		adminRouter.HandleFunc("/etsy/oauth/revoke", adminEtsyOAuthHandler.RevokeToken).Methods("DELETE")
+		adminRouter.Handle("/etsy/oauth/auth-url", can(auth.PermIntegrationsWrite, adminEtsyOAuthHandler.GetAuthURL)).Methods("GET")
+		adminRouter.Handle("/etsy/oauth/callback", can(auth.PermIntegrationsWrite, adminEtsyOAuthHandler.HandleCallback)).Methods("GET")
+		adminRouter.Handle("/etsy/oauth/status", can(auth.PermIntegrationsRead, adminEtsyOAuthHandler.GetTokenStatus)).Methods("GET")
+		adminRouter.Handle("/etsy/oauth/refresh", can(auth.PermIntegrationsWrite, adminEtsyOAuthHandler.RefreshToken)).Methods("POST")
+		adminRouter.Handle("/etsy/oauth/revoke", can(auth.PermIntegrationsWrite, adminEtsyOAuthHandler.RevokeToken)).Methods("DELETE")
 	}
 
+	// Admin users and roles
+	adminRouter.Handle("/users", can(auth.PermUsersManage, adminUserHandler.ListUsers)).Methods("GET")
+	adminRouter.Handle("/users", can(auth.PermUsersManage, adminUserHandler.CreateUser)).Methods("POST")
+	adminRouter.Handle("/users/{id}", can(auth.PermUsersManage, adminUserHandler.GetUser)).Methods("GET")
+	adminRouter.Handle("/users/{id}", can(auth.PermUsersManage, adminUserHandler.UpdateUser)).Methods("PATCH")
+	adminRouter.Handle("/users/{id}", can(auth.PermUsersManage, adminUserHandler.DeleteUser)).Methods("DELETE")
+	adminRouter.Handle("/users/{id}/roles", can(auth.PermUsersManage, adminUserHandler.SetUserRoles)).Methods("PUT")
+	adminRouter.Handle("/roles", can(auth.PermUsersManage, adminUserHandler.ListRoles)).Methods("GET")
+
+	// Audit log
+	adminRouter.Handle("/audit", can(auth.PermAuditRead, adminAuditHandler.ListAuditLogs)).Methods("GET")
+
+	// Customer data requests (GDPR access and erasure)
+	adminRouter.Handle("/privacy/export", can(auth.PermCustomersPrivacy, adminPrivacyHandler.ExportData)).Methods("POST")
+	adminRouter.Handle("/privacy/anonymize", can(auth.PermCustomersPrivacy, adminPrivacyHandler.AnonymizeData)).Methods("POST")
+
+	// Payment webhook events
+	adminRouter.Handle("/webhooks/events", can(auth.PermWebhooksManage, adminWebhookHandler.ListEvents)).Methods("GET")
+	adminRouter.Handle("/webhooks/events/{id}", can(auth.PermWebhooksManage, adminWebhookHandler.GetEvent)).Methods("GET")
+	adminRouter.Handle("/webhooks/events/{id}/replay", can(auth.PermWebhooksManage, adminWebhookHandler.ReplayEvent)).Methods("POST")
+
 	// Note: Legacy product and order endpoints removed - use /admin/shop/* endpoints instead
 
 	// Personaggi management routes (authenticated)
-	adminRouter.HandleFunc("/personaggi", personaggiHandler.GetPersonaggi).Methods("GET")
-	adminRouter.HandleFunc("/personaggi", personaggiHandler.CreatePersonaggio).Methods("POST")
-	adminRouter.HandleFunc("/personaggi/deleted", personaggiHandler.GetDeletedPersonaggi).Methods("GET")
-	adminRouter.HandleFunc("/personaggi/{id}", personaggiHandler.GetPersonaggio).Methods("GET")
-	adminRouter.HandleFunc("/personaggi/{id}", personaggiHandler.UpdatePersonaggio).Methods("PUT")
-	adminRouter.HandleFunc("/personaggi/{id}", personaggiHandler.DeletePersonaggio).Methods("DELETE")
-	adminRouter.HandleFunc("/personaggi/{id}/restore", personaggiHandler.RestorePersonaggio).Methods("POST")
-	adminRouter.HandleFunc("/personaggi/{id}/upload", personaggiHandler.UploadImage).Methods("POST")
-	adminRouter.HandleFunc("/personaggi/{id}/images", personaggiHandler.DeleteImage).Methods("DELETE")
+	adminRouter.Handle("/personaggi", can(auth.PermContentRead, personaggiHandler.GetPersonaggi)).Methods("GET")
+	adminRouter.Handle("/personaggi", can(auth.PermContentWrite, personaggiHandler.CreatePersonaggio)).Methods("POST")
+	adminRouter.Handle("/personaggi/deleted", can(auth.PermContentRead, personaggiHandler.GetDeletedPersonaggi)).Methods("GET")
+	adminRouter.Handle("/personaggi/{id}", can(auth.PermContentRead, personaggiHandler.GetPersonaggio)).Methods("GET")
+	adminRouter.Handle("/personaggi/{id}", can(auth.PermContentWrite, personaggiHandler.UpdatePersonaggio)).Methods("PUT")
+	adminRouter.Handle("/personaggi/{id}", can(auth.PermContentWrite, personaggiHandler.DeletePersonaggio)).Methods("DELETE")
+	adminRouter.Handle("/personaggi/{id}/restore", can(auth.PermContentWrite, personaggiHandler.RestorePersonaggio)).Methods("POST")
+	adminRouter.Handle("/personaggi/{id}/upload", can(auth.PermContentWrite, personaggiHandler.UploadImage)).Methods("POST")
+	adminRouter.Handle("/personaggi/{id}/images", can(auth.PermContentWrite, personaggiHandler.DeleteImage)).Methods("DELETE")
 
 	// Fumetti management routes (authenticated)
-	adminRouter.HandleFunc("/fumetti", fumettiHandler.GetFumetti).Methods("GET")
-	adminRouter.HandleFunc("/fumetti", fumettiHandler.CreateFumetto).Methods("POST")
-	adminRouter.HandleFunc("/fumetti/deleted", fumettiHandler.GetDeletedFumetti).Methods("GET")
-	adminRouter.HandleFunc("/fumetti/{id}", fumettiHandler.GetFumetto).Methods("GET")
-	adminRouter.HandleFunc("/fumetti/{id}", fumettiHandler.UpdateFumetto).Methods("PUT")
-	adminRouter.HandleFunc("/fumetti/{id}", fumettiHandler.DeleteFumetto).Methods("DELETE")
-	adminRouter.HandleFunc("/fumetti/{id}/restore", fumettiHandler.RestoreFumetto).Methods("POST")
-	adminRouter.HandleFunc("/fumetti/{id}/upload", fumettiHandler.UploadPage).Methods("POST")
-	adminRouter.HandleFunc("/fumetti/{id}/pages", fumettiHandler.DeletePage).Methods("DELETE")
+	adminRouter.Handle("/fumetti", can(auth.PermContentRead, fumettiHandler.GetFumetti)).Methods("GET")
+	adminRouter.Handle("/fumetti", can(auth.PermContentWrite, fumettiHandler.CreateFumetto)).Methods("POST")
+	adminRouter.Handle("/fumetti/deleted", can(auth.PermContentRead, fumettiHandler.GetDeletedFumetti)).Methods("GET")
+	adminRouter.Handle("/fumetti/{id}", can(auth.PermContentRead, fumettiHandler.GetFumetto)).Methods("GET")
+	adminRouter.Handle("/fumetti/{id}", can(auth.PermContentWrite, fumettiHandler.UpdateFumetto)).Methods("PUT")
+	adminRouter.Handle("/fumetti/{id}", can(auth.PermContentWrite, fumettiHandler.DeleteFumetto)).Methods("DELETE")
+	adminRouter.Handle("/fumetti/{id}/restore", can(auth.PermContentWrite, fumettiHandler.RestoreFumetto)).Methods("POST")
+	adminRouter.Handle("/fumetti/{id}/upload", can(auth.PermContentWrite, fumettiHandler.UploadPage)).Methods("POST")
+	adminRouter.Handle("/fumetti/{id}/pages", can(auth.PermContentWrite, fumettiHandler.DeletePage)).Methods("DELETE")
 
 	// ===== Public API =====
 	// Authentication endpoints
-	r.HandleFunc("/api/auth/login", handlers.Login).Methods("POST")
+	r.HandleFunc("/api/auth/login", authHandler.Login).Methods("POST")
+	r.HandleFunc("/api/auth/refresh", authHandler.Refresh).Methods("POST")
+	r.Handle("/api/auth/logout", authMiddleware(http.HandlerFunc(authHandler.Logout))).Methods("POST")
+	r.Handle("/api/auth/me", authMiddleware(http.HandlerFunc(authHandler.Me))).Methods("GET")
 
 	// Note: Legacy customer API endpoints removed - use /api/shop/* endpoints instead
 
@@ -310,8 +572,17 @@ func main() {
 	// Health check
 	r.HandleFunc("/health", handlers.HealthCheck).Methods("GET")
 
+	// Client addresses come from X-Forwarded-For only when a trusted proxy relayed the request
+	trustedProxies, err := middleware.ParseProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// Apply CORS middleware
-	handler := corsMiddleware(r)
+	handler := corsMiddleware(middleware.RealIP(trustedProxies)(r))
+
+	// Start background jobs
+	jobScheduler.Start()
 
 	log.Println("Server starting on :8080")
 	log.Fatal(http.ListenAndServe(":8080", handler))
@@ -1,34 +1,95 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
 )
 
-// AuthMiddleware checks for valid authentication token
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Missing authorization header", http.StatusUnauthorized)
-			return
-		}
-
-		// Extract token from "Bearer <token>" format
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-		// In a real application, validate the token against a database or JWT
-		// For demo purposes, we'll accept any non-empty token
-		if token == "" {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+type contextKey string
+
+const identityContextKey contextKey = "admin_identity"
+
+// AuthMiddleware validates the bearer token and stores the admin identity in the request context
+func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r)
+			if !ok {
+				http.Error(w, "Missing or invalid authorization header", http.StatusUnauthorized)
+				return
+			}
+
+			identity, err := authService.Authenticate(token)
+			if err != nil {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", false
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// ClientIP returns the remote IP of the request. Behind a trusted proxy, RealIP has
+// already replaced it with the forwarded client address.
+func ClientIP(r *http.Request) string {
+	return remoteHost(r)
+}
+
+// WithIdentity returns a copy of ctx carrying the admin identity
+func WithIdentity(ctx context.Context, identity *auth.Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey, identity)
+}
+
+// GetIdentity returns the authenticated admin identity from the context, if any
+func GetIdentity(ctx context.Context) (*auth.Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey).(*auth.Identity)
+	return identity, ok && identity != nil
+}
+
+// Actor returns the username of the authenticated admin for audit entries, or "" if none
+func Actor(ctx context.Context) string {
+	if identity, ok := GetIdentity(ctx); ok {
+		return identity.Username
+	}
+	return ""
+}
+
+// RequirePermission rejects requests whose authenticated identity lacks the permission.
+// It must run after AuthMiddleware.
+func RequirePermission(perm auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, ok := GetIdentity(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !identity.Can(perm) {
+				http.Error(w, "Forbidden: missing permission "+string(perm), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Naim0996/art-management-tool/backend/services/customer"
+)
+
+const customerContextKey contextKey = "customer_identity"
+
+// CustomerMiddleware attaches the customer identity to shop requests that carry a
+// bearer token. Requests without one continue as guests; invalid tokens are rejected
+// so a customer whose session expired is not silently treated as a guest.
+func CustomerMiddleware(customerService *customer.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity, err := customerService.Authenticate(token)
+			if err != nil {
+				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithCustomer(r.Context(), identity)))
+		})
+	}
+}
+
+// RequireCustomer rejects guest requests. It must run after CustomerMiddleware.
+func RequireCustomer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetCustomer(r.Context()); !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithCustomer returns a copy of ctx carrying the customer identity
+func WithCustomer(ctx context.Context, identity *customer.Identity) context.Context {
+	return context.WithValue(ctx, customerContextKey, identity)
+}
+
+// GetCustomer returns the authenticated customer from the context, if any
+func GetCustomer(ctx context.Context) (*customer.Identity, bool) {
+	identity, ok := ctx.Value(customerContextKey).(*customer.Identity)
+	return identity, ok && identity != nil
+}
+
+// CustomerID returns the ID of the authenticated customer, or nil for guests
+func CustomerID(ctx context.Context) *uint {
+	if identity, ok := GetCustomer(ctx); ok {
+		id := identity.CustomerID
+		return &id
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	proxies, err := ParseProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("ParseProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"spoofed header from a client", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", "198.51.100.1", "198.51.100.1"},
+		{"client prepends a fake hop", "10.1.2.3:5000", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", "198.51.100.1, 192.168.1.5", "198.51.100.1"},
+		{"trusted proxy without header", "192.168.1.5:5000", "", "192.168.1.5"},
+		{"malformed header", "10.1.2.3:5000", "not-an-ip", "10.1.2.3"},
+	}
+	for _, tc := range tests {
+		var got string
+		handler := RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = ClientIP(r)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tc.remote
+		if tc.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tc.forwarded)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if got != tc.want {
+			t.Errorf("%s: ClientIP() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseProxiesInvalid(t *testing.T) {
+	for _, proxy := range []string{"proxy.local", "10.0.0.0/33"} {
+		if _, err := ParseProxies([]string{proxy}); err == nil {
+			t.Errorf("ParseProxies(%q) accepted an invalid address", proxy)
+		}
+	}
+}
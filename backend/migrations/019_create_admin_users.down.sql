@@ -0,0 +1,3 @@
+-- Drop admin authentication tables
+DROP TABLE IF EXISTS admin_sessions CASCADE;
+DROP TABLE IF EXISTS admin_users CASCADE;
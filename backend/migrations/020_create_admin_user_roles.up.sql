@@ -0,0 +1,13 @@
+-- Roles assigned to admin users (owner, editor, fulfillment, viewer)
+CREATE TABLE IF NOT EXISTS admin_user_roles (
+    id SERIAL PRIMARY KEY,
+    admin_user_id INTEGER NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_admin_user_role ON admin_user_roles(admin_user_id, role);
+
+-- Existing admins keep full access
+INSERT INTO admin_user_roles (admin_user_id, role)
+SELECT id, 'owner' FROM admin_users;
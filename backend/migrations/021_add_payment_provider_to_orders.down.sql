@@ -0,0 +1,3 @@
+-- Remove payment_provider field from orders table
+DROP INDEX IF EXISTS idx_orders_payment_provider;
+ALTER TABLE orders DROP COLUMN IF EXISTS payment_provider;
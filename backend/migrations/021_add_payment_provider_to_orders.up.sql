@@ -0,0 +1,7 @@
+-- Record which payment provider created each order's payment intent
+ALTER TABLE orders ADD COLUMN payment_provider VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Etsy checkouts are the only ones that did not use the default provider
+UPDATE orders SET payment_provider = 'etsy' WHERE payment_method = 'etsy';
+
+CREATE INDEX idx_orders_payment_provider ON orders(payment_provider);
@@ -0,0 +1,2 @@
+-- Remove currency field from discount_codes table
+ALTER TABLE discount_codes DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,3 @@
+-- Currency of fixed-amount discounts and minimum purchase.
+-- Monetary columns stay decimal(10,2): the application converts them to integer cents on load.
+ALTER TABLE discount_codes ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'EUR';
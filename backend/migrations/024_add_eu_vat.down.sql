@@ -0,0 +1,12 @@
+-- Remove VAT fields and the tax rate table
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_class;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_country;
+
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+
+DROP INDEX IF EXISTS idx_tax_rates_country_class;
+DROP TABLE IF EXISTS tax_rates;
@@ -0,0 +1,23 @@
+-- VAT rates by destination country and tax class, in basis points (2200 = 22%)
+CREATE TABLE IF NOT EXISTS tax_rates (
+    id SERIAL PRIMARY KEY,
+    country VARCHAR(2) NOT NULL,
+    tax_class VARCHAR(50) NOT NULL,
+    rate BIGINT NOT NULL,
+    name VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_tax_rates_country_class ON tax_rates(country, tax_class);
+
+-- Products are taxed at the standard rate unless they are printed books or comics
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+-- Tax breakdown stored with each order
+ALTER TABLE orders ADD COLUMN tax_country VARCHAR(2);
+ALTER TABLE orders ADD COLUMN prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE order_items ADD COLUMN tax_class VARCHAR(50);
+ALTER TABLE order_items ADD COLUMN tax_rate BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
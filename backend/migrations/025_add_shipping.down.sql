@@ -0,0 +1,9 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_name;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_cost;
+
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
+
+DROP TABLE IF EXISTS shipping_rates;
+DROP TABLE IF EXISTS shipping_methods;
+DROP TABLE IF EXISTS shipping_zones;
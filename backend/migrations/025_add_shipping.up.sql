@@ -0,0 +1,46 @@
+-- Shipping zones group destination countries (ISO 3166-1 alpha-2 codes)
+CREATE TABLE IF NOT EXISTS shipping_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    countries JSONB NOT NULL DEFAULT '[]',
+    fallback BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Shipping methods offered in a zone, priced by weight, item count or subtotal
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id SERIAL PRIMARY KEY,
+    zone_id INTEGER NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500),
+    rate_basis VARCHAR(20) NOT NULL DEFAULT 'items',
+    free_shipping_threshold DECIMAL(10,2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_shipping_methods_zone_id ON shipping_methods(zone_id);
+
+-- Rate brackets: min_value inclusive, max_value exclusive (0 = no upper bound)
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    id SERIAL PRIMARY KEY,
+    method_id INTEGER NOT NULL REFERENCES shipping_methods(id) ON DELETE CASCADE,
+    min_value BIGINT NOT NULL DEFAULT 0,
+    max_value BIGINT NOT NULL DEFAULT 0,
+    price DECIMAL(10,2) NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_shipping_rates_method_id ON shipping_rates(method_id);
+
+-- Product weight for weight-based rates
+ALTER TABLE products ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0;
+
+-- Shipping charged on each order
+ALTER TABLE orders ADD COLUMN shipping_cost DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN shipping_method VARCHAR(50);
+ALTER TABLE orders ADD COLUMN shipping_method_name VARCHAR(100);
@@ -0,0 +1,24 @@
+-- Shipments sent for an order, with carrier tracking
+CREATE TABLE IF NOT EXISTS shipments (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    carrier VARCHAR(100),
+    tracking_number VARCHAR(255),
+    tracking_url VARCHAR(500),
+    shipped_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_shipments_order_id ON shipments(order_id);
+
+-- Order item quantities covered by each shipment
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id SERIAL PRIMARY KEY,
+    shipment_id INTEGER NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL
+);
+
+CREATE INDEX idx_shipment_items_shipment_id ON shipment_items(shipment_id);
+CREATE INDEX idx_shipment_items_order_item_id ON shipment_items(order_item_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_discount_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_code;
@@ -0,0 +1,4 @@
+-- Discount code used by an order, so cancellations can give back its usage
+ALTER TABLE orders ADD COLUMN discount_code VARCHAR(50);
+
+CREATE INDEX idx_orders_discount_code ON orders(discount_code);
@@ -0,0 +1,25 @@
+-- Refunds given back on an order through its payment provider
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    provider VARCHAR(50),
+    provider_refund_id VARCHAR(255),
+    amount DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    status VARCHAR(50),
+    reason VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+
+-- Order item quantities covered by each refund, put back into stock
+CREATE TABLE IF NOT EXISTS refund_items (
+    id SERIAL PRIMARY KEY,
+    refund_id INTEGER NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL
+);
+
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
+CREATE INDEX idx_refund_items_order_item_id ON refund_items(order_item_id);
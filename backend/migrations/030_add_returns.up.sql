@@ -0,0 +1,29 @@
+-- Return requests (RMA) opened against order items
+CREATE TABLE IF NOT EXISTS return_requests (
+    id SERIAL PRIMARY KEY,
+    rma_number VARCHAR(80) NOT NULL UNIQUE,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested', -- requested, approved, rejected, received, refunded
+    reason TEXT NOT NULL,
+    opened_by VARCHAR(255),
+    admin_note TEXT,
+    refund_id INTEGER REFERENCES refunds(id) ON DELETE SET NULL,
+    received_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_return_requests_order_id ON return_requests(order_id);
+CREATE INDEX idx_return_requests_status ON return_requests(status);
+
+-- Order item quantities sent back in each return, and what happened to them
+CREATE TABLE IF NOT EXISTS return_items (
+    id SERIAL PRIMARY KEY,
+    return_request_id INTEGER NOT NULL REFERENCES return_requests(id) ON DELETE CASCADE,
+    order_item_id INTEGER NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    disposition VARCHAR(20) -- restock, write_off
+);
+
+CREATE INDEX idx_return_items_return_request_id ON return_items(return_request_id);
+CREATE INDEX idx_return_items_order_item_id ON return_items(order_item_id);
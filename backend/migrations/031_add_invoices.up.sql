@@ -0,0 +1,20 @@
+-- Invoices issued for paid orders; number and document never change once issued
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    number VARCHAR(50) NOT NULL UNIQUE,
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL, -- restarts at 1 every year
+    issued_at TIMESTAMP NOT NULL,
+    total DECIMAL(10, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'EUR',
+    document BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT idx_invoices_year_sequence UNIQUE (year, sequence)
+);
+
+-- Last invoice number used in each year
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,4 @@
+ALTER TABLE invoices DROP COLUMN IF EXISTS e_invoice;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_tax_rate;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_vat_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_tax_code;
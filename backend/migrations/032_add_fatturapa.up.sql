@@ -0,0 +1,7 @@
+-- Customer fiscal identifiers and the shipping VAT rate, needed for electronic invoices
+ALTER TABLE orders ADD COLUMN customer_tax_code VARCHAR(16);
+ALTER TABLE orders ADD COLUMN customer_vat_id VARCHAR(30);
+ALTER TABLE orders ADD COLUMN shipping_tax_rate BIGINT NOT NULL DEFAULT 0;
+
+-- FatturaPA XML of an invoice, kept as first exported
+ALTER TABLE invoices ADD COLUMN e_invoice BYTEA;
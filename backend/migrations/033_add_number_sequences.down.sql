@@ -0,0 +1,11 @@
+ALTER TABLE refunds DROP COLUMN IF EXISTS number;
+
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO invoice_sequences (year, last_number)
+SELECT period, last_number FROM number_sequences WHERE name = 'invoice';
+
+DROP TABLE IF EXISTS number_sequences;
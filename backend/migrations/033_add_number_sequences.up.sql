@@ -0,0 +1,17 @@
+-- Counters behind order, refund and invoice numbers; period is the year for formats
+-- that restart yearly, 0 otherwise. Existing order numbers are left as they are.
+CREATE TABLE IF NOT EXISTS number_sequences (
+    name VARCHAR(50) NOT NULL,
+    period INTEGER NOT NULL,
+    last_number INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (name, period)
+);
+
+-- Invoice numbering continues from its own counters
+INSERT INTO number_sequences (name, period, last_number)
+SELECT 'invoice', year, last_number FROM invoice_sequences;
+
+DROP TABLE IF EXISTS invoice_sequences;
+
+-- Refunds get a number; earlier refunds keep none
+ALTER TABLE refunds ADD COLUMN number VARCHAR(50) UNIQUE;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_orders_user_id;
+DROP TABLE IF EXISTS customer_tokens;
+DROP TABLE IF EXISTS customer_sessions;
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,48 @@
+-- Customer accounts of the shop; carts.user_id and orders.user_id point here
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL, -- lower-case
+    password_hash VARCHAR(255) NOT NULL, -- bcrypt hash
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    email_verified_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT true,
+    last_login_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_customers_deleted_at ON customers(deleted_at);
+
+-- Customer sessions backing refresh tokens
+CREATE TABLE IF NOT EXISTS customer_sessions (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256 of the refresh token
+    user_agent VARCHAR(500),
+    ip_address VARCHAR(64),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_sessions_customer ON customer_sessions(customer_id);
+CREATE INDEX idx_customer_sessions_expires ON customer_sessions(expires_at);
+
+-- One-time email verification and password reset tokens
+CREATE TABLE IF NOT EXISTS customer_tokens (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL, -- verify_email, reset_password
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256 of the emailed token
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_tokens_customer ON customer_tokens(customer_id);
+
+-- Order history and guest order claiming look orders up by customer
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id);
@@ -0,0 +1,17 @@
+-- Saved shipping and billing addresses of customer accounts
+CREATE TABLE IF NOT EXISTS customer_addresses (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    label VARCHAR(50),
+    street VARCHAR(255) NOT NULL,
+    city VARCHAR(100) NOT NULL,
+    state VARCHAR(100),
+    zip_code VARCHAR(20) NOT NULL,
+    country VARCHAR(2) NOT NULL, -- ISO 3166-1 alpha-2
+    default_shipping BOOLEAN NOT NULL DEFAULT false, -- at most one per customer
+    default_billing BOOLEAN NOT NULL DEFAULT false,  -- at most one per customer
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_addresses_customer_id ON customer_addresses(customer_id);
@@ -0,0 +1,2 @@
+ALTER TABLE etsy_receipts DROP COLUMN IF EXISTS anonymized_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS anonymized_at;
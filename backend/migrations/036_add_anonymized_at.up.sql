@@ -0,0 +1,3 @@
+-- When the customer data of orders and Etsy receipts was erased on request
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+ALTER TABLE etsy_receipts ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
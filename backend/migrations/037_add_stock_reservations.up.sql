@@ -0,0 +1,14 @@
+-- Soft holds on variant stock while it sits in a cart
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    cart_id INTEGER NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    variant_id INTEGER NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL, -- Released by the scheduler once past
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_stock_reservations_cart_variant ON stock_reservations(cart_id, variant_id);
+CREATE INDEX idx_stock_reservations_variant_id ON stock_reservations(variant_id);
+CREATE INDEX idx_stock_reservations_expires_at ON stock_reservations(expires_at);
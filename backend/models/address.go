@@ -0,0 +1,117 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CustomerAddress is a saved address in a customer's address book
+type CustomerAddress struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	CustomerID      uint      `gorm:"not null;index" json:"-"`
+	Label           string    `gorm:"size:50" json:"label,omitempty"` // e.g. "Home", "Studio"
+	Street          string    `gorm:"size:255;not null" json:"street"`
+	City            string    `gorm:"size:100;not null" json:"city"`
+	State           string    `gorm:"size:100" json:"state,omitempty"`
+	ZipCode         string    `gorm:"size:20;not null" json:"zip_code"`
+	Country         string    `gorm:"size:2;not null" json:"country"`
+	DefaultShipping bool      `gorm:"not null;default:false" json:"default_shipping"`
+	DefaultBilling  bool      `gorm:"not null;default:false" json:"default_billing"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Address returns the saved address in the form orders store
+func (a *CustomerAddress) Address() Address {
+	return Address{
+		Street:  a.Street,
+		City:    a.City,
+		State:   a.State,
+		ZipCode: a.ZipCode,
+		Country: a.Country,
+	}
+}
+
+// postalCodeFormats are the postal code formats of the main EU countries, checked after
+// NormalizeAddress. Other countries only need a postal code.
+var postalCodeFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"CZ": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^(0[1-9]|[1-4]\d|5[0-2])\d{3}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GR": regexp.MustCompile(`^\d{3} \d{2}$`),
+	"IE": regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) [0-9AC-FHKNPRTV-Y]{4}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"LU": regexp.MustCompile(`^\d{4}$`),
+	"NL": regexp.MustCompile(`^[1-9]\d{3} [A-Z]{2}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} \d{2}$`),
+}
+
+// provincePattern is the format of Italian province codes, which invoices need
+var provincePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// postalCodeSpaces are the countries whose codes are written with a space after the
+// given number of characters
+var postalCodeSpaces = map[string]int{"CZ": 3, "GR": 3, "IE": 3, "NL": 4, "SE": 3}
+
+// NormalizeAddress trims the fields of an address, upper-cases the country (and Italian
+// provinces) and writes the postal code in its national form, e.g. "1012ab" becomes
+// "1012 AB" in NL
+func NormalizeAddress(a Address) Address {
+	a.Street = strings.TrimSpace(a.Street)
+	a.City = strings.TrimSpace(a.City)
+	a.State = strings.TrimSpace(a.State)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+	if a.Country == "IT" {
+		a.State = strings.ToUpper(a.State)
+	}
+
+	zip := strings.ToUpper(strings.TrimSpace(a.ZipCode))
+	if a.Country == "LU" {
+		zip = strings.TrimPrefix(zip, "L-") // Often written with the country prefix
+	}
+	if at, ok := postalCodeSpaces[a.Country]; ok {
+		compact := strings.ReplaceAll(zip, " ", "")
+		if len(compact) > at {
+			zip = compact[:at] + " " + compact[at:]
+		}
+	}
+	a.ZipCode = zip
+	return a
+}
+
+// ValidateAddress checks a normalized address, including the postal code format of
+// the main EU countries
+func ValidateAddress(a Address) error {
+	v := NewValidator()
+	v.Required("street", a.Street).
+		MaxLength("street", a.Street, 255).
+		Required("city", a.City).
+		MaxLength("city", a.City, 100).
+		MaxLength("state", a.State, 100).
+		Required("zip_code", a.ZipCode).
+		MaxLength("zip_code", a.ZipCode, 20).
+		Pattern("country", a.Country, `^[A-Z]{2}$`)
+
+	if format, ok := postalCodeFormats[a.Country]; ok && a.ZipCode != "" && !format.MatchString(a.ZipCode) {
+		v.errors = append(v.errors, ValidationError{
+			Field:   "zip_code",
+			Message: "is not a valid postal code for " + a.Country,
+		})
+	}
+	if a.Country == "IT" && a.State != "" && !provincePattern.MatchString(a.State) {
+		v.errors = append(v.errors, ValidationError{
+			Field:   "state",
+			Message: "must be the two-letter province code",
+		})
+	}
+
+	return v.Errors()
+}
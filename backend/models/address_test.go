@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		in      Address
+		zipCode string
+		state   string
+	}{
+		{Address{ZipCode: " 1012ab ", Country: "nl"}, "1012 AB", ""},
+		{Address{ZipCode: "11455", Country: "SE"}, "114 55", ""},
+		{Address{ZipCode: "d02x285", Country: "IE"}, "D02 X285", ""},
+		{Address{ZipCode: "L-1311", Country: "LU"}, "1311", ""},
+		{Address{ZipCode: "10100", State: "to", Country: "IT"}, "10100", "TO"},
+		{Address{ZipCode: "sw1a 1aa", State: "london", Country: "GB"}, "SW1A 1AA", "london"},
+	}
+	for _, tt := range tests {
+		got := NormalizeAddress(tt.in)
+		if got.ZipCode != tt.zipCode || got.State != tt.state {
+			t.Errorf("NormalizeAddress(%+v) = %+v, want zip %q state %q", tt.in, got, tt.zipCode, tt.state)
+		}
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	valid := []Address{
+		{Street: "Via Po 2", City: "Torino", State: "TO", ZipCode: "10100", Country: "IT"},
+		{Street: "Hauptstr. 5", City: "Berlin", ZipCode: "10115", Country: "DE"},
+		{Street: "Rua Augusta 1", City: "Lisboa", ZipCode: "1100-053", Country: "PT"},
+		{Street: "1 Main St", City: "Springfield", ZipCode: "62701", Country: "US"}, // No known format
+	}
+	for _, address := range valid {
+		if err := ValidateAddress(NormalizeAddress(address)); err != nil {
+			t.Errorf("ValidateAddress(%+v) error = %v", address, err)
+		}
+	}
+
+	invalid := map[string]Address{
+		"zip_code": {Street: "Via Po 2", City: "Torino", ZipCode: "1010", Country: "IT"},
+		"country":  {Street: "Via Po 2", City: "Torino", ZipCode: "10100", Country: "Italy"},
+		"state":    {Street: "Via Po 2", City: "Torino", State: "Torino", ZipCode: "10100", Country: "IT"},
+		"street":   {City: "Madrid", ZipCode: "28013", Country: "ES"},
+	}
+	for field, address := range invalid {
+		err := ValidateAddress(NormalizeAddress(address))
+		var validation ValidationErrors
+		if !errors.As(err, &validation) || !strings.Contains(err.Error(), field) {
+			t.Errorf("ValidateAddress(%+v) error = %v, want a %s error", address, err, field)
+		}
+	}
+
+	// Spanish codes start with a province number up to 52
+	if err := ValidateAddress(Address{Street: "Calle 1", City: "X", ZipCode: "99001", Country: "ES"}); err == nil {
+		t.Errorf("ValidateAddress() accepted an unknown Spanish province")
+	}
+}
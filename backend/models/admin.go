@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminUser represents a user allowed to access the admin panel
+type AdminUser struct {
+	ID           uint            `gorm:"primarykey" json:"id"`
+	Username     string          `gorm:"size:100;uniqueIndex;not null" json:"username"`
+	Email        string          `gorm:"size:255;index" json:"email,omitempty"`
+	PasswordHash string          `gorm:"size:255;not null" json:"-"`
+	Active       bool            `gorm:"not null;default:true" json:"active"`
+	LastLoginAt  *time.Time      `json:"last_login_at,omitempty"`
+	Roles        []AdminUserRole `gorm:"foreignKey:AdminUserID" json:"-"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// RoleNames returns the names of the roles assigned to the user
+func (u *AdminUser) RoleNames() []string {
+	names := make([]string, len(u.Roles))
+	for i, role := range u.Roles {
+		names[i] = role.Role
+	}
+	return names
+}
+
+// AdminUserRole assigns a role to an admin user
+type AdminUserRole struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	AdminUserID uint      `gorm:"not null;uniqueIndex:idx_admin_user_role" json:"admin_user_id"`
+	Role        string    `gorm:"size:50;not null;uniqueIndex:idx_admin_user_role" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AdminSession represents a login session of an admin user
+type AdminSession struct {
+	Session
+	AdminUserID uint `gorm:"not null;index" json:"admin_user_id"`
+}
+
+// SessionData returns the session shared with customer sessions
+func (s *AdminSession) SessionData() *Session {
+	return &s.Session
+}
+
+// OwnerID returns the admin user the session belongs to
+func (s *AdminSession) OwnerID() uint {
+	return s.AdminUserID
+}
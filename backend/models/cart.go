@@ -28,18 +28,40 @@ type CartItem struct {
 	UpdatedAt time.Time        `json:"updated_at"`
 }
 
-// CalculateTotal calculates the total price for this cart item
-func (ci *CartItem) CalculateTotal() float64 {
+// UnitPrice returns the price of one unit of this cart item in the product currency
+func (ci *CartItem) UnitPrice() Money {
 	if ci.Product == nil {
-		return 0
+		return Money{}
 	}
 
-	price := ci.Product.BasePrice
+	price := ci.Product.BasePrice.WithCurrency(ci.Product.Currency)
 	if ci.Variant != nil {
-		price += ci.Variant.PriceAdjustment
+		price = ci.Variant.GetPrice(price)
 	}
 
-	return price * float64(ci.Quantity)
+	return price
+}
+
+// CalculateTotal calculates the total price for this cart item
+func (ci *CartItem) CalculateTotal() Money {
+	return ci.UnitPrice().Mul(int64(ci.Quantity))
+}
+
+// StockReservation is a soft hold on variant stock while it sits in a cart. Holds expire
+// unless the cart is used again; the shop shows stock net of active holds.
+type StockReservation struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CartID    uint      `gorm:"not null;uniqueIndex:idx_stock_reservations_cart_variant" json:"cart_id"`
+	VariantID uint      `gorm:"not null;uniqueIndex:idx_stock_reservations_cart_variant;index" json:"variant_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsActive checks if the hold has not expired yet
+func (r *StockReservation) IsActive() bool {
+	return time.Now().Before(r.ExpiresAt)
 }
 
 // Legacy types for backward compatibility
@@ -36,8 +36,10 @@ type EnhancedProduct struct {
 	Title            string           `gorm:"size:500;not null" json:"title"`
 	ShortDescription string           `gorm:"size:1000" json:"short_description,omitempty"`
 	LongDescription  string           `gorm:"type:text" json:"long_description,omitempty"` // Markdown supported
-	BasePrice        float64          `gorm:"type:decimal(10,2);not null;default:0" json:"base_price"`
+	BasePrice        Money            `gorm:"type:decimal(10,2);not null;default:0" json:"base_price"`
 	Currency         string           `gorm:"size:3;not null;default:'EUR'" json:"currency"`
+	TaxClass         TaxClass         `gorm:"size:50;not null;default:'standard'" json:"tax_class"`
+	WeightGrams      int              `gorm:"not null;default:0" json:"weight_grams"` // Shipping weight
 	SKU              string           `gorm:"size:100;uniqueIndex" json:"sku,omitempty"`
 	GTIN             string           `gorm:"size:50" json:"gtin,omitempty"`
 	Status           ProductStatus    `gorm:"size:20;not null;default:'draft'" json:"status"`
@@ -57,6 +59,23 @@ func (EnhancedProduct) TableName() string {
 	return "products"
 }
 
+// AfterFind sets the product currency on its prices
+func (p *EnhancedProduct) AfterFind(tx *gorm.DB) error {
+	p.ApplyCurrency()
+	return nil
+}
+
+// ApplyCurrency sets the product currency on the base price and the loaded variants
+func (p *EnhancedProduct) ApplyCurrency() {
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+	p.BasePrice = p.BasePrice.WithCurrency(p.Currency)
+	for i := range p.Variants {
+		p.Variants[i].PriceAdjustment = p.Variants[i].PriceAdjustment.WithCurrency(p.Currency)
+	}
+}
+
 // ProductImage represents a product image
 type ProductImage struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
@@ -74,7 +93,7 @@ type ProductVariant struct {
 	SKU             string         `gorm:"size:100;uniqueIndex;not null" json:"sku"`
 	Name            string         `gorm:"size:255;not null" json:"name"`
 	Attributes      string         `gorm:"type:jsonb" json:"attributes,omitempty"` // JSON: {"size": "M", "color": "red"}
-	PriceAdjustment float64        `gorm:"type:decimal(10,2);default:0" json:"price_adjustment"`
+	PriceAdjustment Money          `gorm:"type:decimal(10,2);default:0" json:"price_adjustment"`
 	Stock           int            `gorm:"not null;default:0" json:"stock"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
@@ -82,6 +101,6 @@ type ProductVariant struct {
 }
 
 // GetPrice returns the actual price of the variant
-func (v *ProductVariant) GetPrice(basePrice float64) float64 {
-	return basePrice + v.PriceAdjustment
+func (v *ProductVariant) GetPrice(basePrice Money) Money {
+	return basePrice.Add(v.PriceAdjustment)
 }
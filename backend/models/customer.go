@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Customer is a shop account. Carts and orders point to it through their UserID.
+type Customer struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	Email           string         `gorm:"size:255;uniqueIndex;not null" json:"email"` // Stored lower-case
+	PasswordHash    string         `gorm:"size:255;not null" json:"-"`
+	Name            string         `gorm:"size:255;not null" json:"name"`
+	Phone           string         `gorm:"size:50" json:"phone,omitempty"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at,omitempty"`
+	Active          bool           `gorm:"not null;default:true" json:"active"`
+	LastLoginAt     *time.Time     `json:"last_login_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// IsVerified checks if the customer confirmed their email address
+func (c *Customer) IsVerified() bool {
+	return c.EmailVerifiedAt != nil
+}
+
+// CustomerSession represents a login session of a customer
+type CustomerSession struct {
+	Session
+	CustomerID uint `gorm:"not null;index" json:"customer_id"`
+}
+
+// SessionData returns the session shared with admin sessions
+func (s *CustomerSession) SessionData() *Session {
+	return &s.Session
+}
+
+// OwnerID returns the customer the session belongs to
+func (s *CustomerSession) OwnerID() uint {
+	return s.CustomerID
+}
+
+// CustomerTokenPurpose is what a one-time customer token is for
+type CustomerTokenPurpose string
+
+const (
+	CustomerTokenVerifyEmail   CustomerTokenPurpose = "verify_email"
+	CustomerTokenResetPassword CustomerTokenPurpose = "reset_password"
+)
+
+// CustomerToken is a one-time token sent by email; only its hash is stored
+type CustomerToken struct {
+	ID         uint                 `gorm:"primarykey" json:"id"`
+	CustomerID uint                 `gorm:"not null;index" json:"customer_id"`
+	Purpose    CustomerTokenPurpose `gorm:"size:20;not null" json:"purpose"`
+	TokenHash  string               `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt  time.Time            `gorm:"not null" json:"expires_at"`
+	UsedAt     *time.Time           `json:"used_at,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+// IsUsable checks if the token was neither used nor expired
+func (t *CustomerToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -134,6 +134,7 @@ type EtsyReceipt struct {
 	EtsyUpdatedAt     time.Time      `json:"etsy_updated_at"`
 	LastSyncedAt      *time.Time     `json:"last_synced_at,omitempty"`
 	SyncStatus        string         `gorm:"default:'pending'" json:"sync_status"` // pending, synced, error
+	AnonymizedAt      *time.Time     `json:"anonymized_at,omitempty"` // Buyer data erased on request; syncs leave it out
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
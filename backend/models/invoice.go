@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invoice is the invoice issued for a paid order. Its number and document are kept as
+// issued and never regenerated.
+type Invoice struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	OrderID   uint      `gorm:"not null;uniqueIndex" json:"order_id"`
+	Number    string    `gorm:"size:50;not null;uniqueIndex" json:"number"`
+	Year      int       `gorm:"not null;uniqueIndex:idx_invoices_year_sequence" json:"year"`
+	Sequence  int       `gorm:"not null;uniqueIndex:idx_invoices_year_sequence" json:"sequence"` // Restarts at 1 every year with a yearly number format
+	IssuedAt  time.Time `gorm:"not null" json:"issued_at"`
+	Total     Money     `gorm:"type:decimal(10,2);not null" json:"total"`
+	Currency  string    `gorm:"size:3;not null;default:'EUR'" json:"currency"`
+	Document  []byte    `gorm:"type:bytea" json:"-"` // The PDF as issued
+	EInvoice  []byte    `gorm:"type:bytea" json:"-"` // FatturaPA XML, kept as first exported
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// AfterFind sets the invoice currency on its total
+func (i *Invoice) AfterFind(tx *gorm.DB) error {
+	i.Total = i.Total.WithCurrency(i.Currency)
+	return nil
+}
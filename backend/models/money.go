@@ -0,0 +1,274 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the shop currency used when none is specified
+const DefaultCurrency = "EUR"
+
+// ErrInvalidMoney is returned when an amount cannot be parsed
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// Money is an amount in minor units (hundredths of the currency unit, e.g. cents)
+// plus its ISO 4217 currency code.
+//
+// Money is stored in the existing decimal(10,2) columns and serialized to JSON as a
+// plain decimal number, so neither the schema nor the API change. The currency is
+// not part of the column: models that hold Money set it from their currency column
+// after loading.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney creates an amount from minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// EUR creates an amount in euro cents
+func EUR(cents int64) Money {
+	return NewMoney(cents, "EUR")
+}
+
+// MoneyFromFloat converts a decimal amount to Money, rounding half away from zero.
+// Use it only at boundaries with float-based external APIs.
+func MoneyFromFloat(value float64, currency string) Money {
+	return NewMoney(int64(math.Round(value*100)), currency)
+}
+
+// ParseMoney parses a decimal string such as "19.99" or "-5" without going through
+// floating point. Digits beyond the second decimal are rounded half away from zero.
+func ParseMoney(s string, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, ErrInvalidMoney
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, ErrInvalidMoney
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/100-1 {
+		return Money{}, fmt.Errorf("%w: %q out of range", ErrInvalidMoney, s)
+	}
+
+	cents := int64(0)
+	for i := 0; i < 2; i++ {
+		cents *= 10
+		if i < len(frac) {
+			cents += int64(frac[i] - '0')
+		}
+	}
+	if len(frac) > 2 && frac[2] >= '5' {
+		cents++
+	}
+
+	amount := units*100 + cents
+	if negative {
+		amount = -amount
+	}
+	return NewMoney(amount, currency), nil
+}
+
+// isDigits reports whether s only contains ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// currency returns the currency of m, or of o when m has none
+func (m Money) currency(o Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return o.Currency
+}
+
+// Add returns m + o. Both amounts are expected to be in the same currency.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.currency(o)}
+}
+
+// Sub returns m - o. Both amounts are expected to be in the same currency.
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.currency(o)}
+}
+
+// Mul returns m multiplied by a quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// MulRatio returns m * numerator / denominator, rounded half away from zero.
+// Percentages are expressed in basis points: m.MulRatio(2200, 10000) is 22% of m.
+func (m Money) MulRatio(numerator, denominator int64) Money {
+	if denominator == 0 {
+		return Money{Currency: m.Currency}
+	}
+
+	product := m.Amount * numerator
+	quotient := product / denominator
+	remainder := product % denominator
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	absDenominator := denominator
+	if absDenominator < 0 {
+		absDenominator = -absDenominator
+	}
+	if remainder*2 >= absDenominator {
+		if (product < 0) != (denominator < 0) {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return Money{Amount: quotient, Currency: m.Currency}
+}
+
+// Min returns the smaller of m and o
+func (m Money) Min(o Money) Money {
+	if o.Amount < m.Amount {
+		return Money{Amount: o.Amount, Currency: m.currency(o)}
+	}
+	return m
+}
+
+// Cmp compares the amounts of m and o, returning -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// IsPositive reports whether the amount is above zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// WithCurrency returns m in the given currency, without conversion
+func (m Money) WithCurrency(currency string) Money {
+	return NewMoney(m.Amount, currency)
+}
+
+// Float64 returns the amount in currency units.
+// Use it only at boundaries with float-based external APIs.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / 100
+}
+
+// Decimal formats the amount as a decimal string such as "19.99"
+func (m Money) Decimal() string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// String formats the amount with its currency, e.g. "19.99 EUR"
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// Value implements driver.Valuer, storing the amount in a decimal column
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan implements sql.Scanner, reading the amount from a decimal column.
+// The currency is left unchanged.
+func (m *Money) Scan(src interface{}) error {
+	var parsed Money
+	var err error
+
+	switch v := src.(type) {
+	case nil:
+		m.Amount = 0
+		return nil
+	case []byte:
+		parsed, err = ParseMoney(string(v), m.Currency)
+	case string:
+		parsed, err = ParseMoney(v, m.Currency)
+	case int64:
+		parsed = NewMoney(v*100, m.Currency)
+	case float64:
+		parsed = MoneyFromFloat(v, m.Currency)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.Amount = parsed.Amount
+	return nil
+}
+
+// MarshalJSON encodes the amount as a decimal number, e.g. 19.99
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON decodes a decimal number or string. The currency is left unchanged.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+
+	parsed, err := ParseMoney(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	m.Amount = parsed.Amount
+	return nil
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"19.99", 1999, false},
+		{"0.29", 29, false},
+		{"5", 500, false},
+		{"5.5", 550, false},
+		{"-15.00", -1500, false},
+		{".5", 50, false},
+		{"1.005", 101, false},
+		{"1.004", 100, false},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"1.2.3", 0, true},
+		{"-", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input, "eur")
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidMoney) {
+				t.Errorf("ParseMoney(%q) error = %v, want ErrInvalidMoney", tt.input, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMoney(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got.Amount != tt.want || got.Currency != "EUR" {
+			t.Errorf("ParseMoney(%q) = %+v, want %d EUR", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := map[int64]string{
+		1999:  "19.99",
+		5:     "0.05",
+		0:     "0.00",
+		-1500: "-15.00",
+		-5:    "-0.05",
+	}
+	for amount, want := range tests {
+		if got := NewMoney(amount, "EUR").Decimal(); got != want {
+			t.Errorf("Decimal(%d) = %q, want %q", amount, got, want)
+		}
+	}
+}
+
+func TestMoneyMulRatio(t *testing.T) {
+	tests := []struct {
+		amount      int64
+		numerator   int64
+		denominator int64
+		want        int64
+	}{
+		{1999, 1500, 10000, 300}, // 15% of 19.99 = 2.9985
+		{1000, 2200, 10000, 220}, // 22% of 10.00
+		{5, 5000, 10000, 3},      // half a cent rounds away from zero
+		{-5, 5000, 10000, -3},    // also for negative amounts
+		{3333, 1, 3, 1111},       // a third
+		{100, 1, 0, 0},           // division by zero yields zero
+	}
+	for _, tt := range tests {
+		if got := NewMoney(tt.amount, "EUR").MulRatio(tt.numerator, tt.denominator); got.Amount != tt.want {
+			t.Errorf("MulRatio(%d, %d/%d) = %d, want %d", tt.amount, tt.numerator, tt.denominator, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Total Money `json:"total"`
+	}{NewMoney(1999, "EUR")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"total":19.99}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	var decoded struct {
+		Total Money `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(`{"total":0.29}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Total.Amount != 29 {
+		t.Errorf("Unmarshal amount = %d, want 29", decoded.Total.Amount)
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	m := Money{Currency: "EUR"}
+	if err := m.Scan([]byte("49.90")); err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 4990 || m.Currency != "EUR" {
+		t.Errorf("Scan = %+v", m)
+	}
+
+	value, err := m.Value()
+	if err != nil || value != "49.90" {
+		t.Errorf("Value() = %v, %v", value, err)
+	}
+}
+
+func TestCalculateDiscount(t *testing.T) {
+	subtotal := NewMoney(1999, "EUR")
+
+	percentage := &DiscountCode{Type: "percentage", Value: 15, Active: true}
+	if got := percentage.CalculateDiscount(subtotal); got.Amount != 300 {
+		t.Errorf("15%% of 19.99 = %d, want 300", got.Amount)
+	}
+
+	fixed := &DiscountCode{Type: "fixed_amount", Value: 25, Active: true}
+	if got := fixed.CalculateDiscount(subtotal); got.Amount != 1999 {
+		t.Errorf("fixed discount should be capped at the subtotal, got %d", got.Amount)
+	}
+
+	minimum := &DiscountCode{Type: "fixed_amount", Value: 5, MinPurchase: NewMoney(5000, "EUR"), Active: true}
+	if got := minimum.CalculateDiscount(subtotal); !got.IsZero() {
+		t.Errorf("discount below minimum purchase = %d, want 0", got.Amount)
+	}
+}
@@ -10,10 +10,13 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending  PaymentStatus = "pending"
-	PaymentStatusPaid     PaymentStatus = "paid"
-	PaymentStatusFailed   PaymentStatus = "failed"
-	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusPaid              PaymentStatus = "paid"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	PaymentStatusCancelled         PaymentStatus = "cancelled"
+	PaymentStatusExpired           PaymentStatus = "expired" // Never paid within the pending order TTL
 )
 
 // FulfillmentStatus represents the order fulfillment status
@@ -23,31 +26,69 @@ const (
 	FulfillmentStatusUnfulfilled        FulfillmentStatus = "unfulfilled"
 	FulfillmentStatusFulfilled          FulfillmentStatus = "fulfilled"
 	FulfillmentStatusPartiallyFulfilled FulfillmentStatus = "partially_fulfilled"
+	FulfillmentStatusCancelled          FulfillmentStatus = "cancelled"
 )
 
 // Order represents an enhanced order
 type Order struct {
-	ID                uint              `gorm:"primarykey" json:"id"`
-	OrderNumber       string            `gorm:"size:50;uniqueIndex;not null" json:"order_number"`
-	UserID            *uint             `json:"user_id,omitempty"`
-	CustomerEmail     string            `gorm:"size:255;not null" json:"customer_email"`
-	CustomerName      string            `gorm:"size:255;not null" json:"customer_name"`
-	Subtotal          float64           `gorm:"type:decimal(10,2);not null;default:0" json:"subtotal"`
-	Tax               float64           `gorm:"type:decimal(10,2);not null;default:0" json:"tax"`
-	Discount          float64           `gorm:"type:decimal(10,2);not null;default:0" json:"discount"`
-	Total             float64           `gorm:"type:decimal(10,2);not null;default:0" json:"total"`
-	Currency          string            `gorm:"size:3;not null;default:'EUR'" json:"currency"`
-	PaymentStatus     PaymentStatus     `gorm:"size:20;not null;default:'pending'" json:"payment_status"`
-	PaymentIntentID   string            `gorm:"size:255" json:"payment_intent_id,omitempty"`
-	PaymentMethod     string            `gorm:"size:50" json:"payment_method,omitempty"`
-	FulfillmentStatus FulfillmentStatus `gorm:"size:20;not null;default:'unfulfilled'" json:"fulfillment_status"`
-	ShippingAddress   string            `gorm:"type:jsonb" json:"shipping_address,omitempty"`
-	BillingAddress    string            `gorm:"type:jsonb" json:"billing_address,omitempty"`
-	Notes             string            `gorm:"type:text" json:"notes,omitempty"`
-	Items             []OrderItem       `gorm:"foreignKey:OrderID" json:"items,omitempty"`
-	CreatedAt         time.Time         `json:"created_at"`
-	UpdatedAt         time.Time         `json:"updated_at"`
-	DeletedAt         gorm.DeletedAt    `gorm:"index" json:"-"`
+	ID                 uint              `gorm:"primarykey" json:"id"`
+	OrderNumber        string            `gorm:"size:50;uniqueIndex;not null" json:"order_number"`
+	UserID             *uint             `gorm:"index" json:"user_id,omitempty"` // Customer account, nil for guest orders
+	CustomerEmail      string            `gorm:"size:255;not null" json:"customer_email"`
+	CustomerName       string            `gorm:"size:255;not null" json:"customer_name"`
+	CustomerTaxCode    string            `gorm:"size:16" json:"customer_tax_code,omitempty"` // Italian codice fiscale, for electronic invoices
+	CustomerVATID      string            `gorm:"size:30" json:"customer_vat_id,omitempty"`   // Business customers' VAT number with country prefix
+	Subtotal           Money             `gorm:"type:decimal(10,2);not null;default:0" json:"subtotal"`
+	Tax                Money             `gorm:"type:decimal(10,2);not null;default:0" json:"tax"`
+	Discount           Money             `gorm:"type:decimal(10,2);not null;default:0" json:"discount"`
+	DiscountCode       string            `gorm:"size:50;index" json:"discount_code,omitempty"` // Code whose usage the order counted
+	ShippingCost       Money             `gorm:"type:decimal(10,2);not null;default:0" json:"shipping_cost"`
+	ShippingTaxRate    int64             `gorm:"not null;default:0" json:"shipping_tax_rate"` // Basis points
+	Total              Money             `gorm:"type:decimal(10,2);not null;default:0" json:"total"`
+	Currency           string            `gorm:"size:3;not null;default:'EUR'" json:"currency"`
+	TaxCountry         string            `gorm:"size:2" json:"tax_country,omitempty"`              // Country whose VAT rates were applied
+	PricesIncludeTax   bool              `gorm:"not null;default:false" json:"prices_include_tax"` // Subtotal is gross when true, net otherwise
+	PaymentStatus      PaymentStatus     `gorm:"size:20;not null;default:'pending'" json:"payment_status"`
+	PaymentIntentID    string            `gorm:"size:255" json:"payment_intent_id,omitempty"`
+	PaymentMethod      string            `gorm:"size:50" json:"payment_method,omitempty"`
+	PaymentProvider    string            `gorm:"size:50;index" json:"payment_provider,omitempty"` // Name of the provider that created the payment intent
+	ShippingMethod     string            `gorm:"size:50" json:"shipping_method,omitempty"`        // Code of the chosen shipping method
+	ShippingMethodName string            `gorm:"size:100" json:"shipping_method_name,omitempty"`
+	FulfillmentStatus  FulfillmentStatus `gorm:"size:20;not null;default:'unfulfilled'" json:"fulfillment_status"`
+	ShippingAddress    string            `gorm:"type:jsonb" json:"shipping_address,omitempty"`
+	BillingAddress     string            `gorm:"type:jsonb" json:"billing_address,omitempty"`
+	Notes              string            `gorm:"type:text" json:"notes,omitempty"`
+	AnonymizedAt       *time.Time        `json:"anonymized_at,omitempty"` // Customer data erased on request, totals kept
+	Items              []OrderItem       `gorm:"foreignKey:OrderID" json:"items,omitempty"`
+	Shipments          []Shipment        `gorm:"foreignKey:OrderID" json:"shipments,omitempty"`
+	Events             []OrderEvent      `gorm:"foreignKey:OrderID" json:"events,omitempty"`
+	Refunds            []Refund          `gorm:"foreignKey:OrderID" json:"refunds,omitempty"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// AfterFind sets the order currency on its amounts
+func (o *Order) AfterFind(tx *gorm.DB) error {
+	o.ApplyCurrency()
+	return nil
+}
+
+// ApplyCurrency sets the order currency on the totals and the loaded items
+func (o *Order) ApplyCurrency() {
+	if o.Currency == "" {
+		o.Currency = DefaultCurrency
+	}
+	o.Subtotal = o.Subtotal.WithCurrency(o.Currency)
+	o.Tax = o.Tax.WithCurrency(o.Currency)
+	o.Discount = o.Discount.WithCurrency(o.Currency)
+	o.ShippingCost = o.ShippingCost.WithCurrency(o.Currency)
+	o.Total = o.Total.WithCurrency(o.Currency)
+	for i := range o.Items {
+		o.Items[i].UnitPrice = o.Items[i].UnitPrice.WithCurrency(o.Currency)
+		o.Items[i].TotalPrice = o.Items[i].TotalPrice.WithCurrency(o.Currency)
+		o.Items[i].TaxAmount = o.Items[i].TaxAmount.WithCurrency(o.Currency)
+	}
 }
 
 // OrderItem represents an item in an order
@@ -60,7 +101,34 @@ type OrderItem struct {
 	VariantName string    `gorm:"size:255" json:"variant_name,omitempty"`
 	SKU         string    `gorm:"size:100" json:"sku,omitempty"`
 	Quantity    int       `gorm:"not null;default:1" json:"quantity"`
-	UnitPrice   float64   `gorm:"type:decimal(10,2);not null" json:"unit_price"`
-	TotalPrice  float64   `gorm:"type:decimal(10,2);not null" json:"total_price"`
+	UnitPrice   Money     `gorm:"type:decimal(10,2);not null" json:"unit_price"`
+	TotalPrice  Money     `gorm:"type:decimal(10,2);not null" json:"total_price"`
+	TaxClass    TaxClass  `gorm:"size:50" json:"tax_class,omitempty"`
+	TaxRate     int64     `gorm:"not null;default:0" json:"tax_rate"` // Basis points: 2200 = 22%
+	TaxAmount   Money     `gorm:"type:decimal(10,2);not null;default:0" json:"tax_amount"`
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+// OrderEventType is the status an order event changed
+type OrderEventType string
+
+const (
+	OrderEventPayment     OrderEventType = "payment"
+	OrderEventFulfillment OrderEventType = "fulfillment"
+)
+
+// OrderEvent is a status transition in an order's timeline
+type OrderEvent struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	OrderID    uint           `gorm:"not null;index" json:"order_id"`
+	Type       OrderEventType `gorm:"size:20;not null" json:"type"`
+	FromStatus string         `gorm:"size:30" json:"from_status,omitempty"` // Empty for the initial status
+	ToStatus   string         `gorm:"size:30;not null" json:"to_status"`
+	Note       string         `gorm:"size:500" json:"note,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (OrderEvent) TableName() string {
+	return "order_events"
+}
@@ -14,14 +14,19 @@ const (
 
 // CheckoutRequest represents a checkout request
 type CheckoutRequest struct {
-	CartID          string        `json:"cart_id"`
-	SessionToken    string        `json:"session_token,omitempty"`
-	PaymentMethod   PaymentMethod `json:"payment_method"`
-	Email           string        `json:"email"`
-	Name            string        `json:"name"`
-	ShippingAddress Address       `json:"shipping_address"`
-	BillingAddress  Address       `json:"billing_address,omitempty"`
-	DiscountCode    string        `json:"discount_code,omitempty"`
+	CartID           string        `json:"cart_id"`
+	SessionToken     string        `json:"session_token,omitempty"`
+	PaymentMethod    PaymentMethod `json:"payment_method"`
+	Email            string        `json:"email"`
+	Name             string        `json:"name"`
+	TaxCode          string        `json:"tax_code,omitempty"` // Italian codice fiscale
+	VATID            string        `json:"vat_id,omitempty"`   // Business customers only, e.g. IT01234567890
+	ShippingAddress  Address       `json:"shipping_address"`
+	BillingAddress   Address       `json:"billing_address,omitempty"`
+	AddressID        *uint         `json:"address_id,omitempty"`         // Saved shipping address of the signed-in customer
+	BillingAddressID *uint         `json:"billing_address_id,omitempty"` // Saved billing address of the signed-in customer
+	DiscountCode     string        `json:"discount_code,omitempty"`
+	ShippingMethodID uint          `json:"shipping_method_id,omitempty"` // Cheapest available method when empty
 }
 
 // Address represents a physical address
@@ -35,19 +40,30 @@ type Address struct {
 
 // CheckoutResponse represents the response from checkout
 type CheckoutResponse struct {
-	OrderID         string  `json:"order_id"`
-	OrderNumber     string  `json:"order_number"`
-	PaymentIntentID string  `json:"payment_intent_id,omitempty"`
-	ClientSecret    string  `json:"client_secret,omitempty"`
-	Total           float64 `json:"total"`
-	Status          string  `json:"status"`
+	OrderID         string `json:"order_id"`
+	OrderNumber     string `json:"order_number"`
+	PaymentIntentID string `json:"payment_intent_id,omitempty"`
+	ClientSecret    string `json:"client_secret,omitempty"`
+	Total           Money  `json:"total"`
+	Status          string `json:"status"`
+	AccessToken     string `json:"access_token"` // Opens GET /api/shop/orders/{order_number}
 }
 
+// PaymentIntentStatus is a provider-neutral payment intent status
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentStatusPending    PaymentIntentStatus = "pending"    // Waiting for the customer
+	PaymentIntentStatusProcessing PaymentIntentStatus = "processing" // Authorized or being captured
+	PaymentIntentStatusSucceeded  PaymentIntentStatus = "succeeded"
+	PaymentIntentStatusCanceled   PaymentIntentStatus = "canceled" // Cancelled, voided or expired
+)
+
 // PaymentIntent represents a payment intent for processing
 type PaymentIntent struct {
 	ID           string
-	Amount       float64
-	Currency     string
+	Amount       Money
+	Status       PaymentIntentStatus // Empty when the provider cannot tell
 	CustomerRef  string
 	Items        []PaymentItem
 	Metadata     map[string]string
@@ -57,7 +73,7 @@ type PaymentIntent struct {
 // PaymentItem represents an item in a payment
 type PaymentItem struct {
 	Name     string
-	Amount   float64
+	Amount   Money
 	Quantity int
 }
 
@@ -66,8 +82,35 @@ type PaymentWebhookEvent struct {
 	Type            string
 	PaymentIntentID string
 	Status          string
-	Amount          float64
-	Currency        string
+	Amount          Money
 	Metadata        map[string]string
 	ReceivedAt      time.Time
 }
+
+// WebhookEventStatus represents the processing status of a stored webhook event
+type WebhookEventStatus string
+
+const (
+	WebhookEventStatusPending    WebhookEventStatus = "pending"
+	WebhookEventStatusProcessing WebhookEventStatus = "processing"
+	WebhookEventStatusProcessed  WebhookEventStatus = "processed"
+	WebhookEventStatusIgnored    WebhookEventStatus = "ignored"
+	WebhookEventStatusFailed     WebhookEventStatus = "failed"
+)
+
+// WebhookEvent is a raw payment provider webhook event, stored before processing.
+// Provider and EventID are unique together so redelivered events are dropped.
+type WebhookEvent struct {
+	ID            uint               `gorm:"primarykey" json:"id"`
+	Provider      string             `gorm:"size:50;not null;uniqueIndex:idx_webhook_events_provider_event" json:"provider"`
+	EventID       string             `gorm:"size:255;not null;uniqueIndex:idx_webhook_events_provider_event" json:"event_id"`
+	EventType     string             `gorm:"size:100;not null;index" json:"event_type"`
+	Payload       string             `gorm:"type:jsonb;not null" json:"payload"`
+	Status        WebhookEventStatus `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	Attempts      int                `gorm:"not null;default:0" json:"attempts"`
+	LastError     string             `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt *time.Time         `gorm:"index" json:"next_attempt_at,omitempty"` // Set while a failed event is waiting for a retry
+	ProcessedAt   *time.Time         `json:"processed_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
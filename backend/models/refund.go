@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Refund is money given back on an order through its payment provider
+type Refund struct {
+	ID               uint         `gorm:"primarykey" json:"id"`
+	Number           string       `gorm:"size:50;uniqueIndex" json:"number,omitempty"` // Empty for refunds recorded before numbering
+	OrderID          uint         `gorm:"not null;index" json:"order_id"`
+	Provider         string       `gorm:"size:50" json:"provider,omitempty"`
+	ProviderRefundID string       `gorm:"size:255" json:"provider_refund_id,omitempty"`
+	Amount           Money        `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Currency         string       `gorm:"size:3;not null;default:'EUR'" json:"currency"`
+	Status           string       `gorm:"size:50" json:"status,omitempty"` // As reported by the provider
+	Reason           string       `gorm:"size:500" json:"reason,omitempty"`
+	Items            []RefundItem `gorm:"foreignKey:RefundID" json:"items"` // Empty for amount-only refunds
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// AfterFind sets the refund currency on its amount
+func (r *Refund) AfterFind(tx *gorm.DB) error {
+	r.Amount = r.Amount.WithCurrency(r.Currency)
+	return nil
+}
+
+// RefundItem is the quantity of an order item a refund covers; it goes back into stock
+type RefundItem struct {
+	ID          uint `gorm:"primarykey" json:"id"`
+	RefundID    uint `gorm:"not null;index" json:"refund_id"`
+	OrderItemID uint `gorm:"not null;index" json:"order_item_id"`
+	Quantity    int  `gorm:"not null" json:"quantity"`
+}
+
+// TableName overrides the table name
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
+
+// RefundedAmount sums the amounts of refunds
+func RefundedAmount(refunds []Refund, currency string) Money {
+	total := NewMoney(0, currency)
+	for _, refund := range refunds {
+		total = total.Add(refund.Amount)
+	}
+	return total
+}
+
+// RefundedQuantities sums the quantities refunded per order item
+func RefundedQuantities(refunds []Refund) map[uint]int {
+	refunded := make(map[uint]int)
+	for _, refund := range refunds {
+		for _, item := range refund.Items {
+			refunded[item.OrderItemID] += item.Quantity
+		}
+	}
+	return refunded
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// ReturnStatus represents the stage of a return request
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	ReturnStatusReceived  ReturnStatus = "received"
+	ReturnStatusRefunded  ReturnStatus = "refunded"
+)
+
+// ReturnDisposition is what happens to a returned item once received
+type ReturnDisposition string
+
+const (
+	ReturnDispositionRestock  ReturnDisposition = "restock"
+	ReturnDispositionWriteOff ReturnDisposition = "write_off"
+)
+
+// ReturnRequest is a customer's request to send back items of an order (RMA)
+type ReturnRequest struct {
+	ID         uint         `gorm:"primarykey" json:"id"`
+	RMANumber  string       `gorm:"size:80;uniqueIndex;not null" json:"rma_number"`
+	OrderID    uint         `gorm:"not null;index" json:"order_id"`
+	Status     ReturnStatus `gorm:"size:20;not null;default:'requested';index" json:"status"`
+	Reason     string       `gorm:"type:text;not null" json:"reason"`
+	OpenedBy   string       `gorm:"size:255" json:"opened_by,omitempty"`   // Admin username, or "customer"
+	AdminNote  string       `gorm:"type:text" json:"admin_note,omitempty"` // Given when approving or rejecting
+	RefundID   *uint        `json:"refund_id,omitempty"`
+	Items      []ReturnItem `gorm:"foreignKey:ReturnRequestID" json:"items"`
+	ReceivedAt *time.Time   `json:"received_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ReturnRequest) TableName() string {
+	return "return_requests"
+}
+
+// ReturnItem is the quantity of an order item sent back in a return
+type ReturnItem struct {
+	ID              uint              `gorm:"primarykey" json:"id"`
+	ReturnRequestID uint              `gorm:"not null;index" json:"return_request_id"`
+	OrderItemID     uint              `gorm:"not null;index" json:"order_item_id"`
+	Quantity        int               `gorm:"not null" json:"quantity"`
+	Disposition     ReturnDisposition `gorm:"size:20" json:"disposition,omitempty"` // Set when the goods are received
+}
+
+// TableName overrides the table name
+func (ReturnItem) TableName() string {
+	return "return_items"
+}
@@ -0,0 +1,14 @@
+package models
+
+// NumberSequence holds the last number a generator handed out in a period.
+// Period is the year for formats that restart every year, 0 otherwise.
+type NumberSequence struct {
+	Name       string `gorm:"primaryKey;size:50"`
+	Period     int    `gorm:"primaryKey;autoIncrement:false"`
+	LastNumber int    `gorm:"not null;default:0"`
+}
+
+// TableName overrides the table name
+func (NumberSequence) TableName() string {
+	return "number_sequences"
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Session is a login session backing a refresh token. Admins and customers keep their
+// sessions in separate tables, each embedding it next to the owner's ID.
+type Session struct {
+	ID               uint       `gorm:"primarykey" json:"id"`
+	RefreshTokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	UserAgent        string     `gorm:"size:500" json:"user_agent,omitempty"`
+	IPAddress        string     `gorm:"size:64" json:"ip_address,omitempty"`
+	ExpiresAt        time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// IsActive checks if the session can still be used
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// SessionRecord is the session row of an admin or customer
+type SessionRecord interface {
+	SessionData() *Session
+	OwnerID() uint
+}
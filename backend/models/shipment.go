@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Shipment is a parcel sent for an order, covering some or all of its items
+type Shipment struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	OrderID        uint           `gorm:"not null;index" json:"order_id"`
+	Carrier        string         `gorm:"size:100" json:"carrier,omitempty"`
+	TrackingNumber string         `gorm:"size:255" json:"tracking_number,omitempty"`
+	TrackingURL    string         `gorm:"size:500" json:"tracking_url,omitempty"`
+	ShippedAt      time.Time      `gorm:"not null" json:"shipped_at"`
+	Items          []ShipmentItem `gorm:"foreignKey:ShipmentID" json:"items"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+// ShipmentItem is the quantity of an order item sent in a shipment
+type ShipmentItem struct {
+	ID          uint `gorm:"primarykey" json:"id"`
+	ShipmentID  uint `gorm:"not null;index" json:"shipment_id"`
+	OrderItemID uint `gorm:"not null;index" json:"order_item_id"`
+	Quantity    int  `gorm:"not null" json:"quantity"`
+}
+
+// TableName overrides the table name
+func (ShipmentItem) TableName() string {
+	return "shipment_items"
+}
+
+// ShippedQuantities sums the quantities shipped per order item
+func ShippedQuantities(shipments []Shipment) map[uint]int {
+	shipped := make(map[uint]int)
+	for _, shipment := range shipments {
+		for _, item := range shipment.Items {
+			shipped[item.OrderItemID] += item.Quantity
+		}
+	}
+	return shipped
+}
+
+// FulfillmentStatusFor works out the fulfillment status of order items from their shipments
+func FulfillmentStatusFor(items []OrderItem, shipments []Shipment) FulfillmentStatus {
+	shipped := ShippedQuantities(shipments)
+
+	some, all := false, len(items) > 0
+	for _, item := range items {
+		quantity := shipped[item.ID]
+		if quantity > 0 {
+			some = true
+		}
+		if quantity < item.Quantity {
+			all = false
+		}
+	}
+
+	switch {
+	case all:
+		return FulfillmentStatusFulfilled
+	case some:
+		return FulfillmentStatusPartiallyFulfilled
+	default:
+		return FulfillmentStatusUnfulfilled
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestFulfillmentStatusFor(t *testing.T) {
+	items := []OrderItem{{ID: 1, Quantity: 2}, {ID: 2, Quantity: 1}}
+
+	tests := []struct {
+		name      string
+		shipments []Shipment
+		want      FulfillmentStatus
+	}{
+		{"no shipments", nil, FulfillmentStatusUnfulfilled},
+		{"one item partly", []Shipment{{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 1}}}}, FulfillmentStatusPartiallyFulfilled},
+		{"one item fully", []Shipment{{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 2}}}}, FulfillmentStatusPartiallyFulfilled},
+		{"all items over two shipments", []Shipment{
+			{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 2, Quantity: 1}}},
+			{Items: []ShipmentItem{{OrderItemID: 1, Quantity: 1}}},
+		}, FulfillmentStatusFulfilled},
+	}
+
+	for _, tt := range tests {
+		if got := FulfillmentStatusFor(items, tt.shipments); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
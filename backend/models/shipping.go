@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ShippingRateBasis is the cart measure a shipping method's rate brackets are based on
+type ShippingRateBasis string
+
+const (
+	ShippingRateByWeight   ShippingRateBasis = "weight"   // Total weight in grams
+	ShippingRateByItems    ShippingRateBasis = "items"    // Number of items
+	ShippingRateBySubtotal ShippingRateBasis = "subtotal" // Cart subtotal in cents
+)
+
+// IsValid checks if the rate basis is supported
+func (b ShippingRateBasis) IsValid() bool {
+	switch b {
+	case ShippingRateByWeight, ShippingRateByItems, ShippingRateBySubtotal:
+		return true
+	}
+	return false
+}
+
+// ShippingZone groups the destination countries that share shipping methods
+type ShippingZone struct {
+	ID        uint                        `gorm:"primarykey" json:"id"`
+	Name      string                      `gorm:"size:100;not null" json:"name"`
+	Countries datatypes.JSONSlice[string] `gorm:"type:jsonb;not null" json:"countries"`   // ISO 3166-1 alpha-2 codes
+	Fallback  bool                        `gorm:"not null;default:false" json:"fallback"` // Matches countries not listed in any zone
+	Methods   []ShippingMethod            `gorm:"foreignKey:ZoneID" json:"methods,omitempty"`
+	CreatedAt time.Time                   `json:"created_at"`
+	UpdatedAt time.Time                   `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ShippingZone) TableName() string {
+	return "shipping_zones"
+}
+
+// HasCountry checks if the zone lists a country
+func (z *ShippingZone) HasCountry(country string) bool {
+	for _, c := range z.Countries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// ShippingMethod is a way to ship to a zone, e.g. standard or express
+type ShippingMethod struct {
+	ID                    uint              `gorm:"primarykey" json:"id"`
+	ZoneID                uint              `gorm:"not null;index" json:"zone_id"`
+	Code                  string            `gorm:"size:50;not null" json:"code"` // standard, express
+	Name                  string            `gorm:"size:100;not null" json:"name"`
+	Description           string            `gorm:"size:500" json:"description,omitempty"`
+	RateBasis             ShippingRateBasis `gorm:"size:20;not null;default:'items'" json:"rate_basis"`
+	FreeShippingThreshold Money             `gorm:"type:decimal(10,2);not null;default:0" json:"free_shipping_threshold"` // Zero disables free shipping
+	Currency              string            `gorm:"size:3;not null;default:'EUR'" json:"currency"`
+	Active                bool              `gorm:"not null" json:"active"`
+	Position              int               `gorm:"not null;default:0" json:"position"`
+	Rates                 []ShippingRate    `gorm:"foreignKey:MethodID" json:"rates,omitempty"`
+	CreatedAt             time.Time         `json:"created_at"`
+	UpdatedAt             time.Time         `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (ShippingMethod) TableName() string {
+	return "shipping_methods"
+}
+
+// AfterFind sets the method currency on its amounts
+func (m *ShippingMethod) AfterFind(tx *gorm.DB) error {
+	m.ApplyCurrency()
+	return nil
+}
+
+// ApplyCurrency sets the method currency on the threshold and the loaded rates
+func (m *ShippingMethod) ApplyCurrency() {
+	if m.Currency == "" {
+		m.Currency = DefaultCurrency
+	}
+	m.FreeShippingThreshold = m.FreeShippingThreshold.WithCurrency(m.Currency)
+	for i := range m.Rates {
+		m.Rates[i].Price = m.Rates[i].Price.WithCurrency(m.Currency)
+	}
+}
+
+// PriceFor returns the price of the bracket containing value, and false when no bracket does
+func (m *ShippingMethod) PriceFor(value int64) (Money, bool) {
+	for _, rate := range m.Rates {
+		if rate.Contains(value) {
+			return rate.Price.WithCurrency(m.Currency), true
+		}
+	}
+	return Money{}, false
+}
+
+// ShippingRate is a bracket of a shipping method: the price charged when the
+// measured value is at least MinValue and below MaxValue
+type ShippingRate struct {
+	ID       uint  `gorm:"primarykey" json:"id"`
+	MethodID uint  `gorm:"not null;index" json:"method_id"`
+	MinValue int64 `gorm:"not null;default:0" json:"min_value"`
+	MaxValue int64 `gorm:"not null;default:0" json:"max_value"` // Exclusive; zero means no upper bound
+	Price    Money `gorm:"type:decimal(10,2);not null;default:0" json:"price"`
+}
+
+// TableName overrides the table name
+func (ShippingRate) TableName() string {
+	return "shipping_rates"
+}
+
+// Contains checks if a measured value falls in the bracket
+func (r *ShippingRate) Contains(value int64) bool {
+	return value >= r.MinValue && (r.MaxValue == 0 || value < r.MaxValue)
+}
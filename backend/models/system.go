@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,11 +11,15 @@ import (
 type NotificationType string
 
 const (
-	NotificationTypeLowStock      NotificationType = "low_stock"
-	NotificationTypePaymentFailed NotificationType = "payment_failed"
-	NotificationTypeOrderCreated  NotificationType = "order_created"
-	NotificationTypeOrderPaid     NotificationType = "order_paid"
-	NotificationTypeSystem        NotificationType = "system"
+	NotificationTypeLowStock       NotificationType = "low_stock"
+	NotificationTypePaymentFailed  NotificationType = "payment_failed"
+	NotificationTypeOrderCreated   NotificationType = "order_created"
+	NotificationTypeOrderPaid      NotificationType = "order_paid"
+	NotificationTypeOrderCancelled NotificationType = "order_cancelled"
+	NotificationTypeOrderExpired   NotificationType = "order_expired"
+	NotificationTypeLatePayment    NotificationType = "late_payment"
+	NotificationTypeReturn         NotificationType = "return"
+	NotificationTypeSystem         NotificationType = "system"
 )
 
 // NotificationSeverity represents the severity level
@@ -66,8 +71,9 @@ type DiscountCode struct {
 	ID          uint           `gorm:"primarykey" json:"id"`
 	Code        string         `gorm:"size:50;uniqueIndex;not null" json:"code"`
 	Type        string         `gorm:"size:20;not null" json:"type"` // percentage, fixed_amount
-	Value       float64        `gorm:"type:decimal(10,2);not null" json:"value"`
-	MinPurchase float64        `gorm:"type:decimal(10,2)" json:"min_purchase,omitempty"`
+	Value       float64        `gorm:"type:decimal(10,2);not null" json:"value"` // Percent for percentage codes, currency units for fixed_amount codes
+	MinPurchase Money          `gorm:"type:decimal(10,2)" json:"min_purchase,omitempty"`
+	Currency    string         `gorm:"size:3;not null;default:'EUR'" json:"currency"`
 	MaxUses     *int           `json:"max_uses,omitempty"`
 	UsedCount   int            `gorm:"not null;default:0" json:"used_count"`
 	StartsAt    *time.Time     `json:"starts_at,omitempty"`
@@ -101,21 +107,35 @@ func (d *DiscountCode) IsValid() bool {
 	return true
 }
 
-// CalculateDiscount calculates the discount amount
-func (d *DiscountCode) CalculateDiscount(subtotal float64) float64 {
+// AfterFind sets the discount currency on its amounts
+func (d *DiscountCode) AfterFind(tx *gorm.DB) error {
+	if d.Currency == "" {
+		d.Currency = DefaultCurrency
+	}
+	d.MinPurchase = d.MinPurchase.WithCurrency(d.Currency)
+	return nil
+}
+
+// CalculateDiscount calculates the discount amount, never more than the subtotal
+func (d *DiscountCode) CalculateDiscount(subtotal Money) Money {
+	zero := Money{Currency: subtotal.Currency}
 	if !d.IsValid() {
-		return 0
+		return zero
 	}
 	
-	if d.MinPurchase > 0 && subtotal < d.MinPurchase {
-		return 0
+	if d.MinPurchase.IsPositive() && subtotal.Cmp(d.MinPurchase) < 0 {
+		return zero
 	}
 	
+	var discount Money
 	if d.Type == "percentage" {
-		return subtotal * (d.Value / 100)
+		// Value is a percentage with two decimals: work in basis points to avoid float drift
+		discount = subtotal.MulRatio(int64(math.Round(d.Value*100)), 10000)
+	} else {
+		discount = MoneyFromFloat(d.Value, subtotal.Currency)
 	}
 	
-	return d.Value
+	return discount.Min(subtotal)
 }
 
 // ShopifyLink represents a mapping between local and Shopify entities
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TaxClass groups products that are taxed at the same VAT rate
+type TaxClass string
+
+const (
+	TaxClassStandard TaxClass = "standard" // Art prints, originals, merchandise
+	TaxClassReduced  TaxClass = "reduced"  // Printed books and comics
+)
+
+// TaxClasses lists the supported tax classes
+var TaxClasses = []TaxClass{TaxClassStandard, TaxClassReduced}
+
+// IsValid checks if the tax class is supported
+func (c TaxClass) IsValid() bool {
+	for _, class := range TaxClasses {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxRate is the VAT rate applied to a tax class in a destination country
+type TaxRate struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Country   string    `gorm:"size:2;not null;uniqueIndex:idx_tax_rates_country_class" json:"country"` // ISO 3166-1 alpha-2
+	TaxClass  TaxClass  `gorm:"size:50;not null;uniqueIndex:idx_tax_rates_country_class" json:"tax_class"`
+	Rate      int64     `gorm:"not null" json:"rate"` // Basis points: 2200 = 22%
+	Name      string    `gorm:"size:100" json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
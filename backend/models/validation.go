@@ -95,6 +95,17 @@ func (v *Validator) MaxValue(field string, value float64, max float64) *Validato
 	return v
 }
 
+// MinMoney validates a minimum money amount
+func (v *Validator) MinMoney(field string, value Money, min Money) *Validator {
+	if value.Cmp(min) < 0 {
+		v.errors = append(v.errors, ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("must be at least %s", min.Decimal()),
+		})
+	}
+	return v
+}
+
 // Pattern validates string against regex pattern
 func (v *Validator) Pattern(field, value, pattern string) *Validator {
 	matched, err := regexp.MatchString(pattern, value)
@@ -253,7 +264,7 @@ func ValidateProductCreate(p *EnhancedProduct) error {
 	v.MaxLength("shortDescription", p.ShortDescription, 1000)
 	v.MaxLength("longDescription", p.LongDescription, 50000)
 
-	v.MinValue("basePrice", p.BasePrice, 0)
+	v.MinMoney("basePrice", p.BasePrice, Money{})
 
 	if p.Currency == "" {
 		p.Currency = "EUR"
@@ -263,6 +274,13 @@ func ValidateProductCreate(p *EnhancedProduct) error {
 	v.MaxLength("sku", p.SKU, 100)
 	v.MaxLength("gtin", p.GTIN, 50)
 
+	if p.TaxClass == "" {
+		p.TaxClass = TaxClassStandard
+	}
+	v.OneOf("taxClass", string(p.TaxClass), taxClassNames())
+
+	v.MinValue("weightGrams", float64(p.WeightGrams), 0)
+
 	if p.Status == "" {
 		p.Status = ProductStatusDraft
 	}
@@ -298,8 +316,8 @@ func ValidateProductUpdate(p *EnhancedProduct) error {
 		v.MaxLength("longDescription", p.LongDescription, 50000)
 	}
 
-	if p.BasePrice < 0 {
-		v.MinValue("basePrice", p.BasePrice, 0)
+	if p.BasePrice.IsNegative() {
+		v.MinMoney("basePrice", p.BasePrice, Money{})
 	}
 
 	if p.Currency != "" {
@@ -314,6 +332,14 @@ func ValidateProductUpdate(p *EnhancedProduct) error {
 		v.MaxLength("gtin", p.GTIN, 50)
 	}
 
+	if p.TaxClass != "" {
+		v.OneOf("taxClass", string(p.TaxClass), taxClassNames())
+	}
+
+	if p.WeightGrams < 0 {
+		v.MinValue("weightGrams", float64(p.WeightGrams), 0)
+	}
+
 	if p.Status != "" {
 		v.OneOf("status", string(p.Status), []string{
 			string(ProductStatusDraft),
@@ -393,3 +419,12 @@ func (f *FumettoInput) Validate() error {
 
 	return v.Errors()
 }
+
+// taxClassNames returns the supported tax classes as strings
+func taxClassNames() []string {
+	names := make([]string, len(TaxClasses))
+	for i, class := range TaxClasses {
+		names[i] = string(class)
+	}
+	return names
+}
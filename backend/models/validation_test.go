@@ -154,7 +154,7 @@ func TestValidateProductCreate(t *testing.T) {
 				Title:            "Test Product",
 				Slug:             "test-product",
 				ShortDescription: "A test product",
-				BasePrice:        99.99,
+				BasePrice:        NewMoney(9999, "EUR"),
 				Currency:         "EUR",
 				SKU:              "TEST-001",
 				Status:           ProductStatusDraft,
@@ -166,7 +166,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Minimal Product",
 				Slug:      "minimal-product",
-				BasePrice: 0,
+				BasePrice: NewMoney(0, "EUR"),
 			},
 			wantErr: false,
 		},
@@ -175,7 +175,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "",
 				Slug:      "test-product",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -184,7 +184,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Test Product",
 				Slug:      "",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -193,7 +193,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Test Product",
 				Slug:      "Test-Product",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -202,7 +202,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Test Product",
 				Slug:      "test product",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -211,7 +211,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Test Product",
 				Slug:      "test-product",
-				BasePrice: -10.0,
+				BasePrice: NewMoney(-1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -220,7 +220,7 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     string(make([]byte, 501)),
 				Slug:      "test-product",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -229,11 +229,21 @@ func TestValidateProductCreate(t *testing.T) {
 			product: EnhancedProduct{
 				Title:     "Test Product",
 				Slug:      "test-product",
-				BasePrice: 10.0,
+				BasePrice: NewMoney(1000, "EUR"),
 				Status:    ProductStatus("invalid"),
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid - unknown tax class",
+			product: EnhancedProduct{
+				Title:     "Test Product",
+				Slug:      "test-product",
+				BasePrice: NewMoney(1000, "EUR"),
+				TaxClass:  TaxClass("zero"),
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,7 +272,7 @@ func TestValidateProductUpdate(t *testing.T) {
 		{
 			name: "valid update - price only",
 			product: EnhancedProduct{
-				BasePrice: 199.99,
+				BasePrice: NewMoney(19999, "EUR"),
 			},
 			wantErr: false,
 		},
@@ -272,7 +282,7 @@ func TestValidateProductUpdate(t *testing.T) {
 				Title:            "Updated Product",
 				Slug:             "updated-product",
 				ShortDescription: "Updated description",
-				BasePrice:        150.0,
+				BasePrice:        NewMoney(15000, "EUR"),
 				Status:           ProductStatusPublished,
 			},
 			wantErr: false,
@@ -294,7 +304,7 @@ func TestValidateProductUpdate(t *testing.T) {
 		{
 			name: "invalid - negative price",
 			product: EnhancedProduct{
-				BasePrice: -50.0,
+				BasePrice: NewMoney(-5000, "EUR"),
 			},
 			wantErr: true,
 		},
@@ -322,7 +332,7 @@ func TestValidateVariant(t *testing.T) {
 				SKU:             "VAR-001",
 				Name:            "Size M",
 				Attributes:      `{"size": "M"}`,
-				PriceAdjustment: 5.0,
+				PriceAdjustment: NewMoney(500, "EUR"),
 				Stock:           100,
 			},
 			wantErr: false,
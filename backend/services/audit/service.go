@@ -0,0 +1,243 @@
+package audit
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// Action represents the kind of change recorded in the audit log
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionDelete    Action = "delete"
+	ActionRestore   Action = "restore"
+	ActionFulfill   Action = "fulfill"
+	ActionRefund    Action = "refund"
+	ActionCancel    Action = "cancel"
+	ActionApprove   Action = "approve"
+	ActionReject    Action = "reject"
+	ActionReceive   Action = "receive"
+	ActionLink      Action = "link"
+	ActionUnlink    Action = "unlink"
+	ActionExport    Action = "export"
+	ActionAnonymize Action = "anonymize"
+)
+
+// Entity types recorded in the audit log
+const (
+	EntityProduct        = "product"
+	EntityVariant        = "product_variant"
+	EntityProductImage   = "product_image"
+	EntityCategory       = "category"
+	EntityDiscount       = "discount_code"
+	EntityTaxRate        = "tax_rate"
+	EntityShippingZone   = "shipping_zone"
+	EntityShippingMethod = "shipping_method"
+	EntityOrder          = "order"
+	EntityShipment       = "shipment"
+	EntityReturn         = "return_request"
+	EntityInvoice        = "invoice"
+	EntityPersonaggio    = "personaggio"
+	EntityFumetto        = "fumetto"
+	EntityEtsyProduct    = "etsy_product"
+	EntityEtsyReceipt    = "etsy_receipt"
+	EntityAdminUser      = "admin_user"
+	EntityCustomerData   = "customer_data" // Data subject requests, by customer account ID or 0
+)
+
+// ignoredFields are left out of update diffs because they change on every save
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+// Service handles audit log operations
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new audit service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// AuditFilters represents filters for listing audit entries
+type AuditFilters struct {
+	EntityType string
+	EntityID   uint
+	Actor      string
+	Action     Action
+	StartDate  time.Time
+	EndDate    time.Time
+	Page       int
+	PerPage    int
+}
+
+// DefaultFilters returns default audit filters
+func DefaultFilters() *AuditFilters {
+	return &AuditFilters{
+		Page:    1,
+		PerPage: 50,
+	}
+}
+
+// Record writes an audit entry with the JSON diff between before and after.
+// Pass a nil before for creations and a nil after for deletions. Failures are
+// logged rather than returned so that an audit problem never fails the mutation.
+func (s *Service) Record(actor string, entityType string, entityID uint, action Action, before, after interface{}) {
+	diff, err := Diff(before, after)
+	if err != nil {
+		log.Printf("Warning: failed to build audit diff for %s %d: %v", entityType, entityID, err)
+		diff = "{}"
+	}
+
+	entry := &models.AuditLog{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     string(action),
+		Actor:      actor,
+		Diff:       diff,
+	}
+
+	if err := s.db.Create(entry).Error; err != nil {
+		log.Printf("Warning: failed to write audit log for %s %d: %v", entityType, entityID, err)
+	}
+}
+
+// List lists audit entries with filters, newest first
+func (s *Service) List(filters *AuditFilters) ([]models.AuditLog, int64, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	query := s.db.Model(&models.AuditLog{})
+
+	if filters.EntityType != "" {
+		query = query.Where("entity_type = ?", filters.EntityType)
+	}
+
+	if filters.EntityID > 0 {
+		query = query.Where("entity_id = ?", filters.EntityID)
+	}
+
+	if filters.Actor != "" {
+		query = query.Where("actor = ?", filters.Actor)
+	}
+
+	if filters.Action != "" {
+		query = query.Where("action = ?", filters.Action)
+	}
+
+	if !filters.StartDate.IsZero() {
+		query = query.Where("created_at >= ?", filters.StartDate)
+	}
+
+	if !filters.EndDate.IsZero() {
+		query = query.Where("created_at <= ?", filters.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filters.Page - 1) * filters.PerPage
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(filters.PerPage).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// Diff builds the JSON diff stored in an audit entry.
+// Creations store the full "after" state, deletions the full "before" state,
+// and updates only the fields whose values changed.
+func Diff(before, after interface{}) (string, error) {
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return "", err
+	}
+
+	afterMap, err := toMap(after)
+	if err != nil {
+		return "", err
+	}
+
+	diff := make(map[string]interface{})
+
+	switch {
+	case beforeMap == nil && afterMap == nil:
+		return "{}", nil
+	case beforeMap == nil:
+		diff["after"] = afterMap
+	case afterMap == nil:
+		diff["before"] = beforeMap
+	default:
+		changedBefore := make(map[string]interface{})
+		changedAfter := make(map[string]interface{})
+
+		for key, oldValue := range beforeMap {
+			if ignoredFields[key] {
+				continue
+			}
+			newValue, ok := afterMap[key]
+			if !ok || !jsonEqual(oldValue, newValue) {
+				changedBefore[key] = oldValue
+				changedAfter[key] = newValue
+			}
+		}
+
+		for key, newValue := range afterMap {
+			if _, ok := beforeMap[key]; !ok && !ignoredFields[key] {
+				changedBefore[key] = nil
+				changedAfter[key] = newValue
+			}
+		}
+
+		diff["before"] = changedBefore
+		diff["after"] = changedAfter
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toMap converts a value to its JSON object representation.
+// Non-object values are wrapped under a "value" key.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "null" {
+		return nil, nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value}, nil
+	}
+	return m, nil
+}
+
+// jsonEqual compares two values decoded from JSON
+func jsonEqual(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
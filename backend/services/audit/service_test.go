@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type item struct {
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+func decodeDiff(t *testing.T, diff string) map[string]map[string]interface{} {
+	t.Helper()
+	var out map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(diff), &out); err != nil {
+		t.Fatalf("invalid diff JSON %q: %v", diff, err)
+	}
+	return out
+}
+
+func TestDiffUpdateKeepsOnlyChangedFields(t *testing.T) {
+	before := item{Name: "Print", Price: 10, UpdatedAt: "a"}
+	after := item{Name: "Print", Price: 12.5, UpdatedAt: "b"}
+
+	diff, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	out := decodeDiff(t, diff)
+	if len(out["before"]) != 1 || len(out["after"]) != 1 {
+		t.Fatalf("expected only price in diff, got %s", diff)
+	}
+	if out["before"]["price"] != 10.0 || out["after"]["price"] != 12.5 {
+		t.Errorf("unexpected price diff: %s", diff)
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	v := item{Name: "Print", Price: 10}
+
+	created, err := Diff(nil, v)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if out := decodeDiff(t, created); out["after"]["name"] != "Print" || out["before"] != nil {
+		t.Errorf("unexpected create diff: %s", created)
+	}
+
+	deleted, err := Diff(&v, nil)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if out := decodeDiff(t, deleted); out["before"]["name"] != "Print" || out["after"] != nil {
+		t.Errorf("unexpected delete diff: %s", deleted)
+	}
+}
+
+func TestDiffNonObjectValues(t *testing.T) {
+	diff, err := Diff("pending", "shipped")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	out := decodeDiff(t, diff)
+	if out["before"]["value"] != "pending" || out["after"]["value"] != "shipped" {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+}
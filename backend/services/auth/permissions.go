@@ -0,0 +1,165 @@
+package auth
+
+import "sort"
+
+// Role represents an admin role
+type Role string
+
+const (
+	RoleOwner       Role = "owner"
+	RoleEditor      Role = "editor"
+	RoleFulfillment Role = "fulfillment"
+	RoleViewer      Role = "viewer"
+)
+
+// Permission represents a single action an admin can be allowed to perform
+type Permission string
+
+const (
+	PermStatsRead          Permission = "stats:read"
+	PermProductsRead       Permission = "products:read"
+	PermProductsWrite      Permission = "products:write"
+	PermInventoryWrite     Permission = "inventory:write"
+	PermCategoriesRead     Permission = "categories:read"
+	PermCategoriesWrite    Permission = "categories:write"
+	PermOrdersRead         Permission = "orders:read"
+	PermOrdersFulfill      Permission = "orders:fulfill"
+	PermOrdersRefund       Permission = "orders:refund"
+	PermOrdersCancel       Permission = "orders:cancel"
+	PermDiscountsRead      Permission = "discounts:read"
+	PermDiscountsWrite     Permission = "discounts:write"
+	PermTaxRead            Permission = "tax:read"
+	PermTaxWrite           Permission = "tax:write"
+	PermShippingRead       Permission = "shipping:read"
+	PermShippingWrite      Permission = "shipping:write"
+	PermNotificationsRead  Permission = "notifications:read"
+	PermNotificationsWrite Permission = "notifications:write"
+	PermContentRead        Permission = "content:read"
+	PermContentWrite       Permission = "content:write"
+	PermIntegrationsRead   Permission = "integrations:read"
+	PermIntegrationsWrite  Permission = "integrations:write"
+	PermUsersManage        Permission = "users:manage"
+	PermAuditRead          Permission = "audit:read"
+	PermWebhooksManage     Permission = "webhooks:manage"
+	PermCustomersPrivacy   Permission = "customers:privacy"
+)
+
+// allPermissions lists every permission known to the registry
+var allPermissions = []Permission{
+	PermStatsRead,
+	PermProductsRead,
+	PermProductsWrite,
+	PermInventoryWrite,
+	PermCategoriesRead,
+	PermCategoriesWrite,
+	PermOrdersRead,
+	PermOrdersFulfill,
+	PermOrdersRefund,
+	PermOrdersCancel,
+	PermDiscountsRead,
+	PermDiscountsWrite,
+	PermTaxRead,
+	PermTaxWrite,
+	PermShippingRead,
+	PermShippingWrite,
+	PermNotificationsRead,
+	PermNotificationsWrite,
+	PermContentRead,
+	PermContentWrite,
+	PermIntegrationsRead,
+	PermIntegrationsWrite,
+	PermUsersManage,
+	PermAuditRead,
+	PermWebhooksManage,
+	PermCustomersPrivacy,
+}
+
+// rolePermissions is the permission registry: the permissions granted by each role
+var rolePermissions = map[Role][]Permission{
+	RoleOwner: allPermissions,
+	RoleEditor: {
+		PermStatsRead,
+		PermProductsRead,
+		PermProductsWrite,
+		PermInventoryWrite,
+		PermCategoriesRead,
+		PermCategoriesWrite,
+		PermOrdersRead,
+		PermDiscountsRead,
+		PermDiscountsWrite,
+		PermTaxRead,
+		PermShippingRead,
+		PermShippingWrite,
+		PermNotificationsRead,
+		PermNotificationsWrite,
+		PermContentRead,
+		PermContentWrite,
+		PermIntegrationsRead,
+		PermIntegrationsWrite,
+	},
+	RoleFulfillment: {
+		PermStatsRead,
+		PermProductsRead,
+		PermInventoryWrite,
+		PermOrdersRead,
+		PermOrdersFulfill,
+		PermShippingRead,
+		PermNotificationsRead,
+		PermNotificationsWrite,
+	},
+	RoleViewer: {
+		PermStatsRead,
+		PermProductsRead,
+		PermCategoriesRead,
+		PermOrdersRead,
+		PermDiscountsRead,
+		PermTaxRead,
+		PermShippingRead,
+		PermNotificationsRead,
+		PermContentRead,
+		PermIntegrationsRead,
+	},
+}
+
+// IsValidRole checks if a role exists in the registry
+func IsValidRole(role Role) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission checks if any of the roles grants the permission
+func HasPermission(roles []Role, perm Permission) bool {
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PermissionsFor returns the sorted, de-duplicated permissions granted by the roles
+func PermissionsFor(roles []Role) []Permission {
+	seen := make(map[Permission]bool)
+	var perms []Permission
+	for _, role := range roles {
+		for _, p := range rolePermissions[role] {
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+	return perms
+}
+
+// RoleRegistry returns a copy of the registry keyed by role
+func RoleRegistry() map[Role][]Permission {
+	registry := make(map[Role][]Permission, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		registry[role] = append([]Permission(nil), perms...)
+	}
+	return registry
+}
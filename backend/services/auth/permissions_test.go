@@ -0,0 +1,44 @@
+package auth
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []Role
+		perm  Permission
+		want  bool
+	}{
+		{"owner can manage users", []Role{RoleOwner}, PermUsersManage, true},
+		{"editor cannot refund", []Role{RoleEditor}, PermOrdersRefund, false},
+		{"only owners handle customer data requests", []Role{RoleEditor, RoleFulfillment, RoleViewer}, PermCustomersPrivacy, false},
+		{"fulfillment can fulfill", []Role{RoleFulfillment}, PermOrdersFulfill, true},
+		{"fulfillment cannot edit discounts", []Role{RoleFulfillment}, PermDiscountsWrite, false},
+		{"viewer cannot write products", []Role{RoleViewer}, PermProductsWrite, false},
+		{"roles are combined", []Role{RoleViewer, RoleFulfillment}, PermOrdersFulfill, true},
+		{"unknown role grants nothing", []Role{"guest"}, PermStatsRead, false},
+		{"no roles", nil, PermStatsRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPermission(tt.roles, tt.perm); got != tt.want {
+				t.Errorf("HasPermission(%v, %s) = %v, want %v", tt.roles, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionsForDeduplicates(t *testing.T) {
+	perms := PermissionsFor([]Role{RoleViewer, RoleEditor})
+	seen := make(map[Permission]bool)
+	for _, p := range perms {
+		if seen[p] {
+			t.Fatalf("duplicate permission %s", p)
+		}
+		seen[p] = true
+	}
+	if !seen[PermProductsWrite] || seen[PermUsersManage] {
+		t.Errorf("unexpected permissions: %v", perms)
+	}
+}
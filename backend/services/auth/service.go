@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenExpired       = errors.New("token expired")
+	ErrSessionRevoked     = errors.New("session revoked or expired")
+	ErrUserInactive       = errors.New("user inactive")
+	ErrUsernameTaken      = errors.New("username already exists")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrNoRoles            = errors.New("at least one role is required")
+	ErrLastOwner          = errors.New("cannot remove the last active owner")
+	ErrUserNotFound       = errors.New("admin user not found")
+	ErrUsernameRequired   = errors.New("username is required")
+)
+
+// Config holds authentication settings
+type Config struct {
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// Identity represents the authenticated admin attached to a request
+type Identity struct {
+	UserID    uint   `json:"id"`
+	Username  string `json:"username"`
+	Roles     []Role `json:"roles"`
+	SessionID uint   `json:"-"`
+}
+
+// Can checks if the identity has been granted a permission
+func (i *Identity) Can(perm Permission) bool {
+	return HasPermission(i.Roles, perm)
+}
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Service handles admin authentication
+type Service struct {
+	db       *gorm.DB
+	config   Config
+	sessions *SessionStore
+}
+
+// NewService creates a new auth service
+func NewService(db *gorm.DB, config Config) *Service {
+	return &Service{
+		db:       db,
+		config:   config,
+		sessions: NewSessionStore(db, AudienceAdmin, config),
+	}
+}
+
+// HashPassword hashes a plain-text password with bcrypt
+func HashPassword(password string) (string, error) {
+	if len(password) < 8 {
+		return "", ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CreateAdmin creates a new admin user with the given roles
+func (s *Service) CreateAdmin(username, email, password string, roles []Role) (*models.AdminUser, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, ErrUsernameRequired
+	}
+
+	if err := validateRoles(roles); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.AdminUser{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrUsernameTaken
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := models.AdminUser{
+		Username:     username,
+		Email:        email,
+		PasswordHash: hash,
+		Active:       true,
+		Roles:        toUserRoles(roles),
+	}
+
+	if err := s.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// EnsureBootstrapAdmin creates the initial admin user when no admin exists yet
+func (s *Service) EnsureBootstrapAdmin(username, password string) error {
+	var count int64
+	if err := s.db.Model(&models.AdminUser{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 || username == "" || password == "" {
+		return nil
+	}
+
+	_, err := s.CreateAdmin(username, "", password, []Role{RoleOwner})
+	return err
+}
+
+// Login verifies credentials and opens a new session
+func (s *Service) Login(username, password, userAgent, ipAddress string) (*TokenPair, *models.AdminUser, error) {
+	var user models.AdminUser
+	if err := s.db.Where("username = ?", username).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	if !CheckPassword(user.PasswordHash, password) {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if !user.Active {
+		return nil, nil, ErrUserInactive
+	}
+
+	pair, err := s.sessions.Open(user.ID, user.Username, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	user.LastLoginAt = &now
+	s.db.Model(&user).Update("last_login_at", now)
+
+	return pair, &user, nil
+}
+
+// Refresh rotates the refresh token of a session and issues a new access token
+func (s *Service) Refresh(refreshToken string) (*TokenPair, *models.AdminUser, error) {
+	session, err := s.sessions.Find(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user models.AdminUser
+	if err := s.db.First(&user, session.OwnerID()).Error; err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	if !user.Active {
+		return nil, nil, ErrUserInactive
+	}
+
+	pair, err := s.sessions.Rotate(session, user.Username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, &user, nil
+}
+
+// Logout revokes a session so neither its access nor refresh tokens work anymore
+func (s *Service) Logout(sessionID uint) error {
+	return s.sessions.Revoke(sessionID)
+}
+
+// Authenticate validates an access token and returns the identity it belongs to
+func (s *Service) Authenticate(accessToken string) (*Identity, error) {
+	claims, err := s.sessions.Verify(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.AdminUser
+	if err := s.db.Preload("Roles").First(&user, claims.Subject).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !user.Active {
+		return nil, ErrUserInactive
+	}
+
+	roles := make([]Role, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = Role(role.Role)
+	}
+
+	return &Identity{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Roles:     roles,
+		SessionID: claims.SessionID,
+	}, nil
+}
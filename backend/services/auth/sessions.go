@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// dummyHash is compared against when the account does not exist
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+// CheckPassword reports whether password matches the bcrypt hash. An empty hash stands
+// for an unknown account and is compared against a dummy hash to keep timing similar.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// SessionStore opens, refreshes and revokes the login sessions of one token audience.
+// Admin sessions live in admin_sessions and customer sessions in customer_sessions.
+type SessionStore struct {
+	db       *gorm.DB
+	audience string
+	config   Config
+}
+
+// NewSessionStore creates a session store for the admin or customer audience
+func NewSessionStore(db *gorm.DB, audience string, config Config) *SessionStore {
+	return &SessionStore{
+		db:       db,
+		audience: audience,
+		config:   config,
+	}
+}
+
+// Open creates a session for the owner and issues its first token pair
+func (s *SessionStore) Open(ownerID uint, username, userAgent, ipAddress string) (*TokenPair, error) {
+	refreshToken, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	record := s.newRecord(ownerID)
+	session := record.SessionData()
+	session.RefreshTokenHash = HashToken(refreshToken)
+	session.UserAgent = truncate(userAgent, 500)
+	session.IPAddress = truncate(ipAddress, 64)
+	session.ExpiresAt = time.Now().Add(s.config.RefreshTokenTTL)
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(record, username, refreshToken)
+}
+
+// Find returns the active session of a refresh token
+func (s *SessionStore) Find(refreshToken string) (models.SessionRecord, error) {
+	record := s.newRecord(0)
+	if err := s.db.Where("refresh_token_hash = ?", HashToken(refreshToken)).First(record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if !record.SessionData().IsActive() {
+		return nil, ErrSessionRevoked
+	}
+	return record, nil
+}
+
+// Rotate replaces the refresh token of a session found with Find and issues a new
+// token pair
+func (s *SessionStore) Rotate(record models.SessionRecord, username string) (*TokenPair, error) {
+	refreshToken, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := record.SessionData()
+	session.RefreshTokenHash = HashToken(refreshToken)
+	session.ExpiresAt = time.Now().Add(s.config.RefreshTokenTTL)
+	if err := s.db.Save(record).Error; err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(record, username, refreshToken)
+}
+
+// Verify validates an access token of the store's audience and checks that its session
+// is still active
+func (s *SessionStore) Verify(accessToken string) (*Claims, error) {
+	claims, err := ParseToken(accessToken, []byte(s.config.Secret), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Audience != s.audience {
+		return nil, ErrInvalidToken
+	}
+
+	record := s.newRecord(0)
+	if err := s.db.First(record, claims.SessionID).Error; err != nil {
+		return nil, ErrSessionRevoked
+	}
+	if !record.SessionData().IsActive() || record.OwnerID() != claims.Subject {
+		return nil, ErrSessionRevoked
+	}
+	return claims, nil
+}
+
+// Revoke revokes a session so neither its access nor refresh tokens work anymore
+func (s *SessionStore) Revoke(sessionID uint) error {
+	return s.db.Model(s.newRecord(0)).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOwner revokes the owner's open sessions except the one to keep, which is 0 to
+// revoke them all. It runs on tx so callers can revoke inside their transaction.
+func (s *SessionStore) RevokeOwner(tx *gorm.DB, ownerID, keepSessionID uint) error {
+	return tx.Model(s.newRecord(0)).
+		Where(s.ownerColumn()+" = ? AND id <> ? AND revoked_at IS NULL", ownerID, keepSessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// newRecord returns an empty session row of the store's audience
+func (s *SessionStore) newRecord(ownerID uint) models.SessionRecord {
+	if s.audience == AudienceCustomer {
+		return &models.CustomerSession{CustomerID: ownerID}
+	}
+	return &models.AdminSession{AdminUserID: ownerID}
+}
+
+// ownerColumn is the column holding the session owner
+func (s *SessionStore) ownerColumn() string {
+	if s.audience == AudienceCustomer {
+		return "customer_id"
+	}
+	return "admin_user_id"
+}
+
+// issueTokens signs an access token for the session
+func (s *SessionStore) issueTokens(record models.SessionRecord, username, refreshToken string) (*TokenPair, error) {
+	session := record.SessionData()
+	now := time.Now()
+	expiresAt := now.Add(s.config.AccessTokenTTL)
+
+	accessToken, err := SignToken(&Claims{
+		Audience:  s.audience,
+		Subject:   record.OwnerID(),
+		Username:  username,
+		SessionID: session.ID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, []byte(s.config.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// truncate shortens a string to at most max bytes
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
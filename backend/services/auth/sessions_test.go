@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionStoreVerifyRejectsOtherAudience(t *testing.T) {
+	for _, tt := range []struct{ store, token string }{
+		{AudienceAdmin, AudienceCustomer},
+		{AudienceCustomer, AudienceAdmin},
+	} {
+		store := NewSessionStore(nil, tt.store, Config{Secret: "secret"})
+		token, _ := SignToken(&Claims{
+			Audience:  tt.token,
+			Subject:   1,
+			SessionID: 1,
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		}, []byte("secret"))
+
+		if _, err := store.Verify(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s store Verify(%s token) error = %v, want %v", tt.store, tt.token, err, ErrInvalidToken)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("long-enough-password")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	if !CheckPassword(hash, "long-enough-password") {
+		t.Error("CheckPassword() with the right password = false")
+	}
+	if CheckPassword(hash, "wrong-password") {
+		t.Error("CheckPassword() with a wrong password = true")
+	}
+	if CheckPassword("", "not-a-real-password") {
+		t.Error("CheckPassword() without a hash = true")
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndParseToken(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Now()
+
+	claims := &Claims{
+		Subject:   42,
+		Username:  "artadmin",
+		SessionID: 7,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Minute).Unix(),
+	}
+
+	token, err := SignToken(claims, secret)
+	if err != nil {
+		t.Fatalf("SignToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  []byte
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:   "valid token",
+			token:  token,
+			secret: secret,
+			now:    now,
+		},
+		{
+			name:    "wrong secret",
+			token:   token,
+			secret:  []byte("other-secret"),
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "expired token",
+			token:   token,
+			secret:  secret,
+			now:     now.Add(2 * time.Minute),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "tampered payload",
+			token:   tamperPayload(token),
+			secret:  secret,
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "malformed token",
+			token:   "not-a-token",
+			secret:  secret,
+			now:     now,
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToken(tt.token, tt.secret, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseToken() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseToken() unexpected error = %v", err)
+			}
+			if got.Subject != claims.Subject || got.SessionID != claims.SessionID || got.Username != claims.Username {
+				t.Errorf("ParseToken() claims = %+v, want %+v", got, claims)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsCustomerTokens(t *testing.T) {
+	s := NewService(nil, Config{Secret: "secret"})
+	token, _ := SignToken(&Claims{
+		Audience:  AudienceCustomer,
+		Subject:   1,
+		SessionID: 1,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}, []byte("secret"))
+
+	if _, err := s.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	if _, err := HashPassword("short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("HashPassword() with short password error = %v, want %v", err, ErrWeakPassword)
+	}
+
+	hash, err := HashPassword("long-enough-password")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if hash == "long-enough-password" || !strings.HasPrefix(hash, "$2") {
+		t.Errorf("HashPassword() returned %q, want a bcrypt hash", hash)
+	}
+}
+
+// tamperPayload replaces the payload segment of a token with different claims
+func tamperPayload(token string) string {
+	parts := strings.Split(token, ".")
+	forged, _ := SignToken(&Claims{Subject: 1, ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte("attacker"))
+	parts[1] = strings.Split(forged, ".")[1]
+	return strings.Join(parts, ".")
+}
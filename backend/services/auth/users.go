@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// UpdateAdminRequest represents changes to an admin user; nil fields are left unchanged
+type UpdateAdminRequest struct {
+	Email    *string
+	Password *string
+	Active   *bool
+}
+
+// ListAdmins lists all admin users with their roles
+func (s *Service) ListAdmins() ([]models.AdminUser, error) {
+	var users []models.AdminUser
+	if err := s.db.Preload("Roles").Order("username ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetAdmin gets an admin user by ID
+func (s *Service) GetAdmin(id uint) (*models.AdminUser, error) {
+	var user models.AdminUser
+	if err := s.db.Preload("Roles").First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateAdmin updates an admin user's email, password or active flag
+func (s *Service) UpdateAdmin(id uint, req *UpdateAdminRequest) (*models.AdminUser, error) {
+	user, err := s.GetAdmin(id)
+	if err != nil {
+		return nil, err
+	}
+
+	revokeSessions := false
+
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+
+	if req.Password != nil {
+		hash, err := HashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
+		revokeSessions = true
+	}
+
+	if req.Active != nil && *req.Active != user.Active {
+		if !*req.Active && hasRole(user, RoleOwner) {
+			if err := s.ensureAnotherOwner(user.ID); err != nil {
+				return nil, err
+			}
+		}
+		user.Active = *req.Active
+		revokeSessions = revokeSessions || !user.Active
+	}
+
+	if err := s.db.Omit("Roles").Save(user).Error; err != nil {
+		return nil, err
+	}
+
+	if revokeSessions {
+		if err := s.sessions.RevokeOwner(s.db, user.ID, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// SetRoles replaces the roles assigned to an admin user
+func (s *Service) SetRoles(id uint, roles []Role) (*models.AdminUser, error) {
+	if err := validateRoles(roles); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetAdmin(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasRole(user, RoleOwner) && !containsRole(roles, RoleOwner) {
+		if err := s.ensureAnotherOwner(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("admin_user_id = ?", user.ID).Delete(&models.AdminUserRole{}).Error; err != nil {
+			return err
+		}
+
+		userRoles := toUserRoles(roles)
+		for i := range userRoles {
+			userRoles[i].AdminUserID = user.ID
+		}
+		return tx.Create(&userRoles).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAdmin(id)
+}
+
+// DeleteAdmin soft deletes an admin user and revokes its sessions
+func (s *Service) DeleteAdmin(id uint) error {
+	user, err := s.GetAdmin(id)
+	if err != nil {
+		return err
+	}
+
+	if hasRole(user, RoleOwner) {
+		if err := s.ensureAnotherOwner(user.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.db.Delete(user).Error; err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeOwner(s.db, user.ID, 0)
+}
+
+// ensureAnotherOwner returns ErrLastOwner if no active owner other than userID exists
+func (s *Service) ensureAnotherOwner(userID uint) error {
+	var count int64
+	err := s.db.Model(&models.AdminUser{}).
+		Joins("JOIN admin_user_roles ON admin_user_roles.admin_user_id = admin_users.id").
+		Where("admin_user_roles.role = ? AND admin_users.active = ? AND admin_users.id != ?", RoleOwner, true, userID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// validateRoles checks that roles is non-empty and only contains known roles
+func validateRoles(roles []Role) error {
+	if len(roles) == 0 {
+		return ErrNoRoles
+	}
+	for _, role := range roles {
+		if !IsValidRole(role) {
+			return ErrInvalidRole
+		}
+	}
+	return nil
+}
+
+// toUserRoles converts roles to de-duplicated role assignments
+func toUserRoles(roles []Role) []models.AdminUserRole {
+	seen := make(map[Role]bool)
+	var userRoles []models.AdminUserRole
+	for _, role := range roles {
+		if !seen[role] {
+			seen[role] = true
+			userRoles = append(userRoles, models.AdminUserRole{Role: string(role)})
+		}
+	}
+	return userRoles
+}
+
+// hasRole checks if a loaded user has a role
+func hasRole(user *models.AdminUser, role Role) bool {
+	for _, r := range user.Roles {
+		if Role(r.Role) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRole checks if roles contains role
+func containsRole(roles []Role, role Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+//go:build integration
+
+package cart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestMergeGuestCart(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewService(db, nil, 15*time.Minute)
+	customerID := uint(7)
+	prints := testdb.CreateVariant(t, db, "PRINT-A3", 3)
+	poster := testdb.CreateVariant(t, db, "POSTER-A2", 5)
+
+	// The customer's cart from an earlier visit, then a guest cart on another device
+	if _, err := s.AddItem("account-session", &customerID, prints.ProductID, &prints.ID, 2); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if _, err := s.AddItem("guest-session", nil, prints.ProductID, &prints.ID, 1); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if _, err := s.AddItem("guest-session", nil, poster.ProductID, &poster.ID, 2); err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	// Stock sold elsewhere meanwhile: only two prints are left for the merged line
+	if err := db.Model(prints).Update("stock", 2).Error; err != nil {
+		t.Fatal(err)
+	}
+	var guest models.Cart
+	if err := db.Where("session_token = ?", "guest-session").First(&guest).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := s.MergeGuestCart("guest-session", customerID)
+	if err != nil {
+		t.Fatalf("MergeGuestCart() error = %v", err)
+	}
+
+	if merged.SessionToken != "account-session" {
+		t.Errorf("session token = %q, want the account cart's", merged.SessionToken)
+	}
+	quantities := make(map[uint]int)
+	for _, item := range merged.Items {
+		quantities[*item.VariantID] = item.Quantity
+	}
+	if quantities[prints.ID] != 2 || quantities[poster.ID] != 2 {
+		t.Errorf("quantities = %v, want 2 prints capped at stock and 2 posters", quantities)
+	}
+
+	var carts, items, reservations int64
+	db.Model(&models.Cart{}).Where("id = ?", guest.ID).Count(&carts)
+	db.Model(&models.CartItem{}).Where("cart_id = ?", guest.ID).Count(&items)
+	db.Model(&models.StockReservation{}).Where("cart_id = ?", guest.ID).Count(&reservations)
+	if carts != 0 || items != 0 || reservations != 0 {
+		t.Errorf("guest cart left %d carts, %d items and %d reservations, want none", carts, items, reservations)
+	}
+}
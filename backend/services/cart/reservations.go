@@ -0,0 +1,165 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Stock reservations hold the variant quantities in a cart for the reservation TTL, so
+// that two shoppers cannot both add the last piece. Every cart change refreshes the
+// holds of the cart; the scheduler releases the expired ones. A zero TTL turns
+// reservations off and stock is only checked when adding and at checkout.
+
+// reserving checks if stock reservations are on
+func (s *Service) reserving() bool {
+	return s.reservationTTL > 0
+}
+
+// reserve holds a quantity of a variant for a cart, replacing its previous hold. The
+// variant is locked while its held stock is counted, and ErrOutOfStock is returned
+// when the other carts' holds leave less than the quantity.
+func (s *Service) reserve(cartID, variantID uint, quantity int) error {
+	if !s.reserving() {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var variant models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, variantID).Error; err != nil {
+			return err
+		}
+
+		held, err := heldStock(tx, []uint{variantID}, cartID)
+		if err != nil {
+			return err
+		}
+		if availableStock(variant.Stock, held[variantID]) < quantity {
+			return fmt.Errorf("%w: %s", ErrOutOfStock, variant.Name)
+		}
+
+		reservation := models.StockReservation{
+			CartID:    cartID,
+			VariantID: variantID,
+			Quantity:  quantity,
+			ExpiresAt: time.Now().Add(s.reservationTTL),
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cart_id"}, {Name: "variant_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "expires_at", "updated_at"}),
+		}).Create(&reservation).Error
+	})
+}
+
+// ReserveCart holds the stock of every line of a cart, as checkout does before creating
+// the order. Lines whose hold expired are held again when the stock is still there;
+// otherwise ErrOutOfStock names the variant.
+func (s *Service) ReserveCart(cart *models.Cart) error {
+	for _, item := range cart.Items {
+		if item.VariantID == nil {
+			continue
+		}
+		if err := s.reserve(cart.ID, *item.VariantID, item.Quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchReservations extends the holds of a cart and drops those of variants no longer
+// in it
+func (s *Service) touchReservations(cartID uint) error {
+	if !s.reserving() {
+		return nil
+	}
+
+	lines := s.db.Model(&models.CartItem{}).Select("variant_id").Where("cart_id = ? AND variant_id IS NOT NULL", cartID)
+	if err := s.db.Where("cart_id = ? AND variant_id NOT IN (?)", cartID, lines).Delete(&models.StockReservation{}).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&models.StockReservation{}).
+		Where("cart_id = ?", cartID).
+		Update("expires_at", time.Now().Add(s.reservationTTL)).Error
+}
+
+// ReleaseExpiredReservations deletes the holds past their expiry, giving their stock back
+// to the shop. Run by the scheduler.
+func (s *Service) ReleaseExpiredReservations(ctx context.Context) error {
+	result := s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.StockReservation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Released %d expired stock reservations", result.RowsAffected)
+	}
+	return nil
+}
+
+// ApplyReservations subtracts the active holds from the stock of the products' variants,
+// so that the shop catalog shows the stock still available
+func (s *Service) ApplyReservations(products []models.EnhancedProduct) error {
+	if !s.reserving() {
+		return nil
+	}
+
+	var variantIDs []uint
+	for _, product := range products {
+		for _, variant := range product.Variants {
+			variantIDs = append(variantIDs, variant.ID)
+		}
+	}
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	held, err := heldStock(s.db, variantIDs, 0)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		subtractHolds(products[i].Variants, held)
+	}
+	return nil
+}
+
+// heldStock sums the active holds of variants, leaving out those of a cart (0 for none)
+func heldStock(db *gorm.DB, variantIDs []uint, excludeCartID uint) (map[uint]int, error) {
+	var rows []struct {
+		VariantID uint
+		Quantity  int
+	}
+	err := db.Model(&models.StockReservation{}).
+		Select("variant_id, SUM(quantity) AS quantity").
+		Where("variant_id IN ? AND cart_id <> ? AND expires_at > ?", variantIDs, excludeCartID, time.Now()).
+		Group("variant_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	held := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		held[row.VariantID] = row.Quantity
+	}
+	return held, nil
+}
+
+// subtractHolds sets the variants' stock to what the holds leave available
+func subtractHolds(variants []models.ProductVariant, held map[uint]int) {
+	for i := range variants {
+		variants[i].Stock = availableStock(variants[i].Stock, held[variants[i].ID])
+	}
+}
+
+// availableStock is the stock left by the holds, never negative
+func availableStock(stock, held int) int {
+	if held >= stock {
+		return 0
+	}
+	return stock - held
+}
@@ -0,0 +1,58 @@
+package cart
+
+import (
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestAvailableStock(t *testing.T) {
+	tests := []struct {
+		stock, held, want int
+	}{
+		{5, 0, 5},
+		{5, 2, 3},
+		{1, 1, 0},
+		{1, 3, 0}, // Stock lowered under the holds by an admin adjustment
+	}
+	for _, tt := range tests {
+		if got := availableStock(tt.stock, tt.held); got != tt.want {
+			t.Errorf("availableStock(%d, %d) = %d, want %d", tt.stock, tt.held, got, tt.want)
+		}
+	}
+}
+
+func TestSubtractHolds(t *testing.T) {
+	variants := []models.ProductVariant{
+		{ID: 1, Stock: 1},
+		{ID: 2, Stock: 4},
+		{ID: 3, Stock: 2},
+	}
+	subtractHolds(variants, map[uint]int{1: 1, 2: 1})
+
+	want := []int{0, 3, 2}
+	for i, variant := range variants {
+		if variant.Stock != want[i] {
+			t.Errorf("variant %d stock = %d, want %d", variant.ID, variant.Stock, want[i])
+		}
+	}
+}
+
+func TestReservationsOff(t *testing.T) {
+	// Without a TTL nothing touches the database
+	s := NewService(nil, nil, 0)
+	variantID := uint(1)
+	cart := &models.Cart{ID: 1, Items: []models.CartItem{{VariantID: &variantID, Quantity: 2}}}
+
+	if err := s.ReserveCart(cart); err != nil {
+		t.Errorf("ReserveCart() error = %v", err)
+	}
+	if err := s.touchReservations(cart.ID); err != nil {
+		t.Errorf("touchReservations() error = %v", err)
+	}
+
+	products := []models.EnhancedProduct{{Variants: []models.ProductVariant{{ID: 1, Stock: 1}}}}
+	if err := s.ApplyReservations(products); err != nil || products[0].Variants[0].Stock != 1 {
+		t.Errorf("ApplyReservations() = %v, stock %d, want stock untouched", err, products[0].Variants[0].Stock)
+	}
+}
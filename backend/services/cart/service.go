@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -19,16 +20,36 @@ var (
 
 // Service handles cart operations
 type Service struct {
-	db *gorm.DB
+	db             *gorm.DB
+	taxService     *tax.Service
+	reservationTTL time.Duration // How long cart lines hold their stock; 0 turns holds off
 }
 
 // NewService creates a new cart service
-func NewService(db *gorm.DB) *Service {
-	return &Service{db: db}
+func NewService(db *gorm.DB, taxService *tax.Service, reservationTTL time.Duration) *Service {
+	return &Service{db: db, taxService: taxService, reservationTTL: reservationTTL}
 }
 
-// GetOrCreateCart gets an existing cart or creates a new one
+// GetOrCreateCart gets an existing cart or creates a new one. A user ID merges the
+// session cart into that customer's cart (see MergeGuestCart), so the returned cart
+// may have another session token.
 func (s *Service) GetOrCreateCart(sessionToken string, userID *uint) (*models.Cart, error) {
+	if userID != nil {
+		cart, err := s.MergeGuestCart(sessionToken, *userID)
+		if !errors.Is(err, ErrCartNotFound) {
+			return cart, err
+		}
+
+		// The session token may still hold another customer's cart: start a new one
+		var taken int64
+		if err := s.db.Model(&models.Cart{}).Where("session_token = ?", sessionToken).Count(&taken).Error; err != nil {
+			return nil, err
+		}
+		if taken > 0 {
+			sessionToken = GenerateSessionToken()
+		}
+	}
+
 	var cart models.Cart
 	
 	err := s.db.Where("session_token = ?", sessionToken).
@@ -60,8 +81,10 @@ func (s *Service) GetOrCreateCart(sessionToken string, userID *uint) (*models.Ca
 	return &cart, nil
 }
 
-// AddItem adds an item to the cart
-func (s *Service) AddItem(sessionToken string, productID uint, variantID *uint, quantity int) (*models.Cart, error) {
+// AddItem adds an item to the cart. Like every cart change, it works on the cart
+// GetOrCreateCart returns for the session and user, whose session token the caller
+// must hand back to the client.
+func (s *Service) AddItem(sessionToken string, userID *uint, productID uint, variantID *uint, quantity int) (*models.Cart, error) {
 	if quantity <= 0 {
 		return nil, ErrInvalidQuantity
 	}
@@ -91,7 +114,7 @@ func (s *Service) AddItem(sessionToken string, productID uint, variantID *uint,
 	}
 	
 	// Get or create cart
-	cart, err := s.GetOrCreateCart(sessionToken, nil)
+	cart, err := s.GetOrCreateCart(sessionToken, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -106,6 +129,17 @@ func (s *Service) AddItem(sessionToken string, productID uint, variantID *uint,
 	}
 	
 	err = query.First(&existingItem).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Hold the stock of the whole line
+	if variantID != nil {
+		if err := s.reserve(cart.ID, *variantID, existingItem.Quantity+quantity); err != nil {
+			return nil, err
+		}
+	}
+
 	if err == nil {
 		// Update quantity
 		existingItem.Quantity += quantity
@@ -123,21 +157,23 @@ func (s *Service) AddItem(sessionToken string, productID uint, variantID *uint,
 		if err := s.db.Create(&item).Error; err != nil {
 			return nil, err
 		}
-	} else {
+	}
+	
+	if err := s.touchReservations(cart.ID); err != nil {
 		return nil, err
 	}
 	
 	// Reload cart with items
-	return s.GetOrCreateCart(sessionToken, nil)
+	return s.loadCart(cart.ID)
 }
 
 // UpdateItemQuantity updates the quantity of an item
-func (s *Service) UpdateItemQuantity(sessionToken string, itemID uint, quantity int) (*models.Cart, error) {
+func (s *Service) UpdateItemQuantity(sessionToken string, userID *uint, itemID uint, quantity int) (*models.Cart, error) {
 	if quantity < 0 {
 		return nil, ErrInvalidQuantity
 	}
 	
-	cart, err := s.GetOrCreateCart(sessionToken, nil)
+	cart, err := s.GetOrCreateCart(sessionToken, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +192,12 @@ func (s *Service) UpdateItemQuantity(sessionToken string, itemID uint, quantity
 			return nil, err
 		}
 	} else {
+		if item.VariantID != nil {
+			if err := s.reserve(cart.ID, *item.VariantID, quantity); err != nil {
+				return nil, err
+			}
+		}
+		
 		// Update quantity
 		item.Quantity = quantity
 		if err := s.db.Save(&item).Error; err != nil {
@@ -163,13 +205,17 @@ func (s *Service) UpdateItemQuantity(sessionToken string, itemID uint, quantity
 		}
 	}
 	
+	if err := s.touchReservations(cart.ID); err != nil {
+		return nil, err
+	}
+	
 	// Reload cart
-	return s.GetOrCreateCart(sessionToken, nil)
+	return s.loadCart(cart.ID)
 }
 
 // RemoveItem removes an item from the cart
-func (s *Service) RemoveItem(sessionToken string, itemID uint) (*models.Cart, error) {
-	cart, err := s.GetOrCreateCart(sessionToken, nil)
+func (s *Service) RemoveItem(sessionToken string, userID *uint, itemID uint) (*models.Cart, error) {
+	cart, err := s.GetOrCreateCart(sessionToken, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -183,35 +229,40 @@ func (s *Service) RemoveItem(sessionToken string, itemID uint) (*models.Cart, er
 		return nil, ErrItemNotFound
 	}
 	
+	if err := s.touchReservations(cart.ID); err != nil {
+		return nil, err
+	}
+	
 	// Reload cart
-	return s.GetOrCreateCart(sessionToken, nil)
+	return s.loadCart(cart.ID)
 }
 
 // ClearCart removes all items from the cart
-func (s *Service) ClearCart(sessionToken string) error {
-	cart, err := s.GetOrCreateCart(sessionToken, nil)
+func (s *Service) ClearCart(sessionToken string, userID *uint) error {
+	cart, err := s.GetOrCreateCart(sessionToken, userID)
 	if err != nil {
 		return err
 	}
 	
+	if err := s.db.Where("cart_id = ?", cart.ID).Delete(&models.StockReservation{}).Error; err != nil {
+		return err
+	}
 	return s.db.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error
 }
 
-// CalculateTotal calculates the total for a cart
-func (s *Service) CalculateTotal(cart *models.Cart) (subtotal, tax, discount, total float64) {
+// CalculateTotal calculates the totals for a cart shipped to a country, with VAT.
+// An empty country estimates VAT for the shop's origin country.
+func (s *Service) CalculateTotal(cart *models.Cart, country string, discount models.Money) (*tax.Result, error) {
+	lines := make([]tax.Line, 0, len(cart.Items))
 	for _, item := range cart.Items {
-		itemTotal := item.CalculateTotal()
-		subtotal += itemTotal
+		line := tax.Line{Amount: item.CalculateTotal()}
+		if item.Product != nil {
+			line.TaxClass = item.Product.TaxClass
+		}
+		lines = append(lines, line)
 	}
 	
-	// Placeholder tax calculation (can be enhanced with tax rules)
-	tax = subtotal * 0.0 // No tax for now
-	
-	// Discount will be applied during checkout
-	discount = 0.0
-	
-	total = subtotal + tax - discount
-	return
+	return s.taxService.Calculate(country, lines, discount)
 }
 
 // GenerateSessionToken generates a new session token
@@ -219,57 +270,150 @@ func GenerateSessionToken() string {
 	return uuid.New().String()
 }
 
-// MergeGuestCart merges a guest cart into a user cart after login
-func (s *Service) MergeGuestCart(guestToken string, userID uint, userToken string) (*models.Cart, error) {
-	// Get guest cart
-	var guestCart models.Cart
-	err := s.db.Where("session_token = ?", guestToken).
-		Preload("Items").
-		First(&guestCart).Error
-	
+// MergeGuestCart merges the session cart into the customer's cart after login. Items of
+// both carts are combined, the customer's cart is kept and the session cart deleted; the
+// caller must re-issue the cart cookie with the returned cart's session token. A session
+// cart of another customer is left alone. Returns ErrCartNotFound when the customer has
+// no cart and there is no guest cart to take over.
+func (s *Service) MergeGuestCart(sessionToken string, userID uint) (*models.Cart, error) {
+	var sessionCart models.Cart
+	err := s.db.Where("session_token = ?", sessionToken).
+		Preload("Items.Variant").
+		First(&sessionCart).Error
+	hasSessionCart := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if hasSessionCart && sessionCart.UserID != nil && *sessionCart.UserID != userID {
+		hasSessionCart = false // Left behind by another customer on this device
+	}
+
+	var userCart models.Cart
+	err = s.db.Where("user_id = ? AND session_token <> ?", userID, sessionToken).
+		Order("updated_at DESC").
+		Preload("Items.Variant").
+		First(&userCart).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// No guest cart, just get or create user cart
-		return s.GetOrCreateCart(userToken, &userID)
+		if !hasSessionCart {
+			return nil, ErrCartNotFound
+		}
+		// Only a session cart: it becomes the customer's cart
+		if sessionCart.UserID == nil {
+			if err := s.db.Model(&sessionCart).Update("user_id", userID).Error; err != nil {
+				return nil, err
+			}
+		}
+		return s.loadCart(sessionCart.ID)
 	}
-	
 	if err != nil {
 		return nil, err
 	}
-	
-	// Get or create user cart
-	userCart, err := s.GetOrCreateCart(userToken, &userID)
+	if !hasSessionCart {
+		return s.loadCart(userCart.ID)
+	}
+
+	updated, moved := mergeItems(userCart.Items, sessionCart.Items)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range updated {
+			if err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).Update("quantity", item.Quantity).Error; err != nil {
+				return err
+			}
+		}
+		for _, item := range moved {
+			if err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).
+				Updates(map[string]interface{}{"cart_id": userCart.ID, "quantity": item.Quantity}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("cart_id = ?", sessionCart.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		// The merged lines hold their stock again on the next cart change or at checkout
+		if err := tx.Where("cart_id = ?", sessionCart.ID).Delete(&models.StockReservation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&sessionCart).Error; err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(30 * 24 * time.Hour)
+		return tx.Model(&userCart).Update("expires_at", expiresAt).Error
+	})
 	if err != nil {
 		return nil, err
 	}
-	
-	// Transfer items from guest cart to user cart
-	for _, guestItem := range guestCart.Items {
-		// Check if item already exists in user cart
-		var existingItem models.CartItem
-		query := s.db.Where("cart_id = ? AND product_id = ?", userCart.ID, guestItem.ProductID)
-		if guestItem.VariantID != nil {
-			query = query.Where("variant_id = ?", *guestItem.VariantID)
-		} else {
-			query = query.Where("variant_id IS NULL")
+
+	return s.loadCart(userCart.ID)
+}
+
+// loadCart loads a cart with its items
+func (s *Service) loadCart(id uint) (*models.Cart, error) {
+	var cart models.Cart
+	err := s.db.Preload("Items.Product").
+		Preload("Items.Variant").
+		First(&cart, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// mergeItems combines the lines of a session cart with those of the user cart. Lines of
+// the same product and variant are summed, capped at the variant stock but never below
+// what the user cart already holds. It returns the user lines whose quantity changed and
+// the session lines to move over; session lines left out are dropped with their cart.
+func mergeItems(userItems, sessionItems []models.CartItem) (updated, moved []models.CartItem) {
+	type key struct {
+		productID uint
+		variantID uint
+	}
+	keyOf := func(item models.CartItem) key {
+		k := key{productID: item.ProductID}
+		if item.VariantID != nil {
+			k.variantID = *item.VariantID
 		}
-		
-		err := query.First(&existingItem).Error
-		if err == nil {
-			// Update quantity
-			existingItem.Quantity += guestItem.Quantity
-			s.db.Save(&existingItem)
-		} else if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Transfer item
-			guestItem.CartID = userCart.ID
-			s.db.Save(&guestItem)
+		return k
+	}
+
+	existing := make(map[key]int, len(userItems))
+	for i, item := range userItems {
+		existing[keyOf(item)] = i
+	}
+
+	changed := make(map[int]bool)
+	merged := append([]models.CartItem(nil), userItems...)
+	for _, item := range sessionItems {
+		i, ok := existing[keyOf(item)]
+		if !ok {
+			item.Quantity = capAtStock(item, item.Quantity)
+			if item.Quantity > 0 {
+				moved = append(moved, item)
+			}
+			continue
+		}
+
+		quantity := capAtStock(merged[i], merged[i].Quantity+item.Quantity)
+		if quantity > merged[i].Quantity {
+			merged[i].Quantity = quantity
+			changed[i] = true
 		}
 	}
-	
-	// Delete guest cart
-	s.db.Delete(&guestCart)
-	
-	// Reload user cart
-	return s.GetOrCreateCart(userToken, &userID)
+
+	for i, item := range merged {
+		if changed[i] {
+			updated = append(updated, item)
+		}
+	}
+	return updated, moved
+}
+
+// capAtStock limits a quantity to the stock of the item's variant; items without a
+// variant are not tracked
+func capAtStock(item models.CartItem, quantity int) int {
+	if item.Variant != nil && quantity > item.Variant.Stock {
+		return item.Variant.Stock
+	}
+	return quantity
 }
 
 // CleanupExpiredCarts removes expired carts
@@ -0,0 +1,74 @@
+package cart
+
+import (
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestMergeItems(t *testing.T) {
+	userItems := []models.CartItem{
+		{ID: 1, ProductID: 10, Quantity: 1},
+		{ID: 2, ProductID: 20, VariantID: uintPtr(5), Variant: &models.ProductVariant{ID: 5, Stock: 4}, Quantity: 3},
+		{ID: 3, ProductID: 30, Quantity: 2},
+	}
+	sessionItems := []models.CartItem{
+		{ID: 11, ProductID: 10, Quantity: 2}, // Summed
+		{ID: 12, ProductID: 20, VariantID: uintPtr(5), Variant: &models.ProductVariant{ID: 5, Stock: 4}, Quantity: 3}, // Capped at stock
+		{ID: 13, ProductID: 20, VariantID: uintPtr(6), Variant: &models.ProductVariant{ID: 6, Stock: 1}, Quantity: 2}, // Moved, capped
+		{ID: 14, ProductID: 40, Quantity: 1}, // Moved
+		{ID: 15, ProductID: 50, VariantID: uintPtr(7), Variant: &models.ProductVariant{ID: 7, Stock: 0}, Quantity: 1}, // Sold out, dropped
+	}
+
+	updated, moved := mergeItems(userItems, sessionItems)
+
+	wantUpdated := map[uint]int{1: 3, 2: 4}
+	if len(updated) != len(wantUpdated) {
+		t.Fatalf("updated = %+v, want %v", updated, wantUpdated)
+	}
+	for _, item := range updated {
+		if wantUpdated[item.ID] != item.Quantity {
+			t.Errorf("updated item %d quantity = %d, want %d", item.ID, item.Quantity, wantUpdated[item.ID])
+		}
+	}
+
+	wantMoved := map[uint]int{13: 1, 14: 1}
+	if len(moved) != len(wantMoved) {
+		t.Fatalf("moved = %+v, want %v", moved, wantMoved)
+	}
+	for _, item := range moved {
+		if wantMoved[item.ID] != item.Quantity {
+			t.Errorf("moved item %d quantity = %d, want %d", item.ID, item.Quantity, wantMoved[item.ID])
+		}
+	}
+
+	// The user cart lines themselves are left untouched
+	if userItems[0].Quantity != 1 || userItems[1].Quantity != 3 {
+		t.Errorf("mergeItems() modified the user items: %+v", userItems)
+	}
+}
+
+func TestMergeItemsKeepsUserQuantityAboveStock(t *testing.T) {
+	variant := &models.ProductVariant{ID: 5, Stock: 1}
+	userItems := []models.CartItem{{ID: 1, ProductID: 20, VariantID: uintPtr(5), Variant: variant, Quantity: 3}}
+	sessionItems := []models.CartItem{{ID: 2, ProductID: 20, VariantID: uintPtr(5), Variant: variant, Quantity: 1}}
+
+	updated, moved := mergeItems(userItems, sessionItems)
+	if len(updated) != 0 || len(moved) != 0 {
+		t.Errorf("mergeItems() = %+v, %+v, want no changes", updated, moved)
+	}
+}
+
+func TestMergeItemsTellsVariantsApart(t *testing.T) {
+	userItems := []models.CartItem{{ID: 1, ProductID: 20, Quantity: 1}}
+	sessionItems := []models.CartItem{{ID: 2, ProductID: 20, VariantID: uintPtr(5), Quantity: 1}}
+
+	updated, moved := mergeItems(userItems, sessionItems)
+	if len(updated) != 0 || len(moved) != 1 || moved[0].ID != 2 {
+		t.Errorf("mergeItems() = %+v, %+v, want the variant line moved", updated, moved)
+	}
+}
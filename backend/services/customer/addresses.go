@@ -0,0 +1,179 @@
+package customer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+var ErrAddressNotFound = errors.New("address not found")
+
+// AddressInput holds the fields of a saved address
+type AddressInput struct {
+	Label           string `json:"label"`
+	Street          string `json:"street"`
+	City            string `json:"city"`
+	State           string `json:"state"`
+	ZipCode         string `json:"zip_code"`
+	Country         string `json:"country"`
+	DefaultShipping *bool  `json:"default_shipping"` // Unchanged when omitted
+	DefaultBilling  *bool  `json:"default_billing"`
+}
+
+// ListAddresses lists the address book of a customer, defaults first
+func (s *Service) ListAddresses(customerID uint) ([]models.CustomerAddress, error) {
+	var addresses []models.CustomerAddress
+	err := s.db.Where("customer_id = ?", customerID).
+		Order("default_shipping DESC, default_billing DESC, id ASC").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// GetAddress gets a saved address of a customer
+func (s *Service) GetAddress(customerID, id uint) (*models.CustomerAddress, error) {
+	var address models.CustomerAddress
+	if err := s.db.Where("id = ? AND customer_id = ?", id, customerID).First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressNotFound
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// DefaultAddress gets the customer's default shipping or billing address, or nil when
+// none is set
+func (s *Service) DefaultAddress(customerID uint, billing bool) (*models.CustomerAddress, error) {
+	column := "default_shipping"
+	if billing {
+		column = "default_billing"
+	}
+
+	var address models.CustomerAddress
+	err := s.db.Where("customer_id = ? AND "+column+" = ?", customerID, true).First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateAddress saves a new address. The first address becomes the default for both
+// shipping and billing.
+func (s *Service) CreateAddress(customerID uint, input AddressInput) (*models.CustomerAddress, error) {
+	address := models.CustomerAddress{CustomerID: customerID}
+	if err := applyAddressInput(&address, input); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.CustomerAddress{}).Where("customer_id = ?", customerID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.DefaultShipping = true
+			address.DefaultBilling = true
+		}
+
+		if err := clearDefaults(tx, &address); err != nil {
+			return err
+		}
+		return tx.Create(&address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// UpdateAddress replaces the fields of a saved address; omitted defaults stay as they are
+func (s *Service) UpdateAddress(customerID, id uint, input AddressInput) (*models.CustomerAddress, error) {
+	address, err := s.GetAddress(customerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAddressInput(address, input); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Save(address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress removes a saved address. Orders keep their own copy of the addresses
+// they were shipped to.
+func (s *Service) DeleteAddress(customerID, id uint) error {
+	result := s.db.Where("id = ? AND customer_id = ?", id, customerID).Delete(&models.CustomerAddress{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAddressNotFound
+	}
+	return nil
+}
+
+// applyAddressInput normalizes and validates the input into a saved address
+func applyAddressInput(address *models.CustomerAddress, input AddressInput) error {
+	normalized := models.NormalizeAddress(models.Address{
+		Street:  input.Street,
+		City:    input.City,
+		State:   input.State,
+		ZipCode: input.ZipCode,
+		Country: input.Country,
+	})
+	label := strings.TrimSpace(input.Label)
+
+	if err := models.ValidateAddress(normalized); err != nil {
+		return err
+	}
+	if err := models.NewValidator().MaxLength("label", label, 50).Errors(); err != nil {
+		return err
+	}
+
+	address.Label = label
+	address.Street = normalized.Street
+	address.City = normalized.City
+	address.State = normalized.State
+	address.ZipCode = normalized.ZipCode
+	address.Country = normalized.Country
+	if input.DefaultShipping != nil {
+		address.DefaultShipping = *input.DefaultShipping
+	}
+	if input.DefaultBilling != nil {
+		address.DefaultBilling = *input.DefaultBilling
+	}
+	return nil
+}
+
+// clearDefaults unsets the defaults of the customer's other addresses that the address
+// is about to take over
+func clearDefaults(tx *gorm.DB, address *models.CustomerAddress) error {
+	others := func() *gorm.DB {
+		return tx.Model(&models.CustomerAddress{}).Where("customer_id = ? AND id <> ?", address.CustomerID, address.ID)
+	}
+	if address.DefaultShipping {
+		if err := others().Update("default_shipping", false).Error; err != nil {
+			return err
+		}
+	}
+	if address.DefaultBilling {
+		if err := others().Update("default_billing", false).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package customer
+
+import (
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestApplyAddressInputKeepsOmittedDefaults(t *testing.T) {
+	address := models.CustomerAddress{DefaultShipping: true, DefaultBilling: true}
+	no := false
+	input := AddressInput{
+		Label:          " Studio ",
+		Street:         "Via Roma 1",
+		City:           "Milano",
+		State:          "mi",
+		ZipCode:        "20121",
+		Country:        "it",
+		DefaultBilling: &no,
+	}
+
+	if err := applyAddressInput(&address, input); err != nil {
+		t.Fatalf("applyAddressInput() error = %v", err)
+	}
+	if !address.DefaultShipping || address.DefaultBilling {
+		t.Errorf("defaults = shipping %v billing %v, want true false", address.DefaultShipping, address.DefaultBilling)
+	}
+	if address.Label != "Studio" || address.State != "MI" || address.Country != "IT" {
+		t.Errorf("applyAddressInput() = %+v, want normalized fields", address)
+	}
+}
@@ -0,0 +1,476 @@
+package customer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
+	"github.com/Naim0996/art-management-tool/backend/services/mail"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrEmailNotVerified   = errors.New("email not verified")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrSessionRevoked     = auth.ErrSessionRevoked
+	ErrCustomerInactive   = errors.New("customer inactive")
+	ErrCustomerNotFound   = errors.New("customer not found")
+)
+
+// Config holds customer account settings
+type Config struct {
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	VerifyTokenTTL  time.Duration
+	ResetTokenTTL   time.Duration
+	AppURL          string // Storefront base URL for the links in emails
+}
+
+// Identity represents the authenticated customer attached to a shop request
+type Identity struct {
+	CustomerID uint   `json:"id"`
+	Email      string `json:"email"`
+	SessionID  uint   `json:"-"`
+}
+
+// Service handles customer accounts
+type Service struct {
+	db       *gorm.DB
+	mailer   mail.Sender
+	config   Config
+	sessions *auth.SessionStore
+}
+
+// NewService creates a new customer service
+func NewService(db *gorm.DB, mailer mail.Sender, config Config) *Service {
+	return &Service{
+		db:     db,
+		mailer: mailer,
+		config: config,
+		sessions: auth.NewSessionStore(db, auth.AudienceCustomer, auth.Config{
+			Secret:          config.Secret,
+			AccessTokenTTL:  config.AccessTokenTTL,
+			RefreshTokenTTL: config.RefreshTokenTTL,
+		}),
+	}
+}
+
+// RegisterInput holds the details of a new account
+type RegisterInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+}
+
+// Register creates an unverified account and emails the verification link. When the
+// address already has an account, that account is told by email instead, so the
+// response does not reveal which addresses are registered.
+func (s *Service) Register(input RegisterInput) error {
+	email := normalizeEmail(input.Email)
+	name := strings.TrimSpace(input.Name)
+	if err := validateProfile(email, name, input.Phone); err != nil {
+		return err
+	}
+
+	hash, err := auth.HashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+
+	// Deleted accounts keep their address, which the unique index still covers
+	var existing models.Customer
+	err = s.db.Unscoped().Where("email = ?", email).First(&existing).Error
+	if err == nil {
+		if err := s.sendAccountExists(&existing); err != nil {
+			log.Printf("Failed to send account notice to customer %d: %v", existing.ID, err)
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	customer := models.Customer{
+		Email:        email,
+		PasswordHash: hash,
+		Name:         name,
+		Phone:        strings.TrimSpace(input.Phone),
+		Active:       true,
+	}
+	if err := s.db.Create(&customer).Error; err != nil {
+		return err
+	}
+
+	if err := s.sendVerification(&customer); err != nil {
+		log.Printf("Failed to send verification email to customer %d: %v", customer.ID, err)
+	}
+	return nil
+}
+
+// ResendVerification emails a new verification link. Unknown and verified addresses
+// are ignored, so the response does not reveal which accounts exist.
+func (s *Service) ResendVerification(email string) error {
+	var customer models.Customer
+	err := s.db.Where("email = ?", normalizeEmail(email)).First(&customer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if customer.IsVerified() || !customer.Active {
+		return nil
+	}
+	return s.sendVerification(&customer)
+}
+
+// Verify confirms the email address of a verification token and claims the guest
+// orders placed with it. It returns the number of orders claimed.
+func (s *Service) Verify(token string) (*models.Customer, int64, error) {
+	var customer models.Customer
+	var claimed int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		customerID, err := useToken(tx, token, models.CustomerTokenVerifyEmail)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&customer, customerID).Error; err != nil {
+			return ErrInvalidToken
+		}
+		claimed, err = confirmEmail(tx, &customer)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &customer, claimed, nil
+}
+
+// RequestPasswordReset emails a password reset link. Unknown addresses are ignored.
+func (s *Service) RequestPasswordReset(email string) error {
+	var customer models.Customer
+	err := s.db.Where("email = ?", normalizeEmail(email)).First(&customer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !customer.Active {
+		return nil
+	}
+
+	token, err := issueToken(s.db, customer.ID, models.CustomerTokenResetPassword, s.config.ResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mail.Message{
+		To:      customer.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to choose a new password:\n%s\n\nThe link expires in %s. If you did not ask for it, ignore this email.\n",
+			customer.Name, s.link("/account/reset-password", token), s.config.ResetTokenTTL),
+	})
+}
+
+// ResetPassword sets a new password with a reset token and signs out every session.
+// Receiving the token proves the address, so unverified accounts become verified.
+func (s *Service) ResetPassword(token, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		customerID, err := useToken(tx, token, models.CustomerTokenResetPassword)
+		if err != nil {
+			return err
+		}
+
+		var customer models.Customer
+		if err := tx.First(&customer, customerID).Error; err != nil {
+			return ErrInvalidToken
+		}
+		if err := tx.Model(&customer).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		if _, err := confirmEmail(tx, &customer); err != nil {
+			return err
+		}
+		return s.sessions.RevokeOwner(tx, customer.ID, 0)
+	})
+}
+
+// Login verifies credentials of a verified account and opens a new session
+func (s *Service) Login(email, password, userAgent, ipAddress string) (*auth.TokenPair, *models.Customer, error) {
+	var customer models.Customer
+	if err := s.db.Where("email = ?", normalizeEmail(email)).First(&customer).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	if !auth.CheckPassword(customer.PasswordHash, password) {
+		return nil, nil, ErrInvalidCredentials
+	}
+	if !customer.Active {
+		return nil, nil, ErrCustomerInactive
+	}
+	if !customer.IsVerified() {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	pair, err := s.sessions.Open(customer.ID, customer.Email, userAgent, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	customer.LastLoginAt = &now
+	s.db.Model(&customer).Update("last_login_at", now)
+
+	return pair, &customer, nil
+}
+
+// Refresh rotates the refresh token of a session and issues a new access token
+func (s *Service) Refresh(refreshToken string) (*auth.TokenPair, *models.Customer, error) {
+	session, err := s.sessions.Find(refreshToken)
+	if err != nil {
+		return nil, nil, sessionError(err)
+	}
+
+	var customer models.Customer
+	if err := s.db.First(&customer, session.OwnerID()).Error; err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+	if !customer.Active {
+		return nil, nil, ErrCustomerInactive
+	}
+
+	pair, err := s.sessions.Rotate(session, customer.Email)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, &customer, nil
+}
+
+// Logout revokes a session so neither its access nor refresh tokens work anymore
+func (s *Service) Logout(sessionID uint) error {
+	return s.sessions.Revoke(sessionID)
+}
+
+// Authenticate validates a customer access token and returns the identity it belongs to
+func (s *Service) Authenticate(accessToken string) (*Identity, error) {
+	claims, err := s.sessions.Verify(accessToken)
+	if err != nil {
+		return nil, sessionError(err)
+	}
+
+	var customer models.Customer
+	if err := s.db.First(&customer, claims.Subject).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if !customer.Active {
+		return nil, ErrCustomerInactive
+	}
+
+	return &Identity{
+		CustomerID: customer.ID,
+		Email:      customer.Email,
+		SessionID:  claims.SessionID,
+	}, nil
+}
+
+// Get gets a customer by ID
+func (s *Service) Get(id uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := s.db.First(&customer, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateProfile changes the name and phone of a customer. The email cannot change
+// because guest orders were claimed with it.
+func (s *Service) UpdateProfile(id uint, name, phone string) (*models.Customer, error) {
+	customer, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	phone = strings.TrimSpace(phone)
+	if err := validateProfile(customer.Email, name, phone); err != nil {
+		return nil, err
+	}
+
+	customer.Name = name
+	customer.Phone = phone
+	if err := s.db.Model(customer).Updates(map[string]interface{}{"name": name, "phone": phone}).Error; err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// ChangePassword replaces the password after checking the current one and signs out
+// every other session
+func (s *Service) ChangePassword(id, sessionID uint, current, password string) error {
+	customer, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if !auth.CheckPassword(customer.PasswordHash, current) {
+		return ErrInvalidCredentials
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(customer).Update("password_hash", hash).Error; err != nil {
+			return err
+		}
+		return s.sessions.RevokeOwner(tx, customer.ID, sessionID)
+	})
+}
+
+// sendVerification emails a new verification link to the customer
+func (s *Service) sendVerification(customer *models.Customer) error {
+	token, err := issueToken(s.db, customer.ID, models.CustomerTokenVerifyEmail, s.config.VerifyTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(mail.Message{
+		To:      customer.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nopen this link to confirm your email address and activate your account:\n%s\n\nThe link expires in %s. Orders you placed as a guest with this address will appear in your account.\n",
+			customer.Name, s.link("/account/verify", token), s.config.VerifyTokenTTL),
+	})
+}
+
+// sendAccountExists tells the owner of an address that someone tried to register it
+// again, with the links to log in or reset the password
+func (s *Service) sendAccountExists(customer *models.Customer) error {
+	return s.mailer.Send(mail.Message{
+		To:      customer.Email,
+		Subject: "You already have an account",
+		Body: fmt.Sprintf("Hi %s,\n\nsomeone tried to create an account with this email address, but you already have one.\n\nLog in here:\n%s\n\nForgot your password? Choose a new one here:\n%s\n\nIf it was not you, ignore this email.\n",
+			customer.Name, s.page("/account/login"), s.page("/account/forgot-password")),
+	})
+}
+
+// page builds a storefront URL
+func (s *Service) page(path string) string {
+	return strings.TrimRight(s.config.AppURL, "/") + path
+}
+
+// link builds a storefront URL carrying a token
+func (s *Service) link(path, token string) string {
+	return s.page(path) + "?token=" + token
+}
+
+// issueToken stores a new one-time token, retiring the customer's earlier ones for the
+// same purpose so only the latest link works
+func issueToken(db *gorm.DB, customerID uint, purpose models.CustomerTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CustomerToken{}).
+			Where("customer_id = ? AND purpose = ? AND used_at IS NULL", customerID, purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.CustomerToken{
+			CustomerID: customerID,
+			Purpose:    purpose,
+			TokenHash:  auth.HashToken(token),
+			ExpiresAt:  time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// useToken marks a one-time token as used and returns its customer
+func useToken(tx *gorm.DB, token string, purpose models.CustomerTokenPurpose) (uint, error) {
+	var stored models.CustomerToken
+	err := tx.Where("token_hash = ? AND purpose = ?", auth.HashToken(token), purpose).First(&stored).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+	if !stored.IsUsable() {
+		return 0, ErrInvalidToken
+	}
+
+	// Only the first of concurrent requests marks the token
+	result := tx.Model(&models.CustomerToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrInvalidToken
+	}
+	return stored.CustomerID, nil
+}
+
+// confirmEmail marks the customer's email as verified and claims the guest orders
+// placed with it
+func confirmEmail(tx *gorm.DB, customer *models.Customer) (int64, error) {
+	if !customer.IsVerified() {
+		now := time.Now()
+		if err := tx.Model(customer).Update("email_verified_at", now).Error; err != nil {
+			return 0, err
+		}
+		customer.EmailVerifiedAt = &now
+	}
+
+	result := tx.Model(&models.Order{}).
+		Where("user_id IS NULL AND LOWER(customer_email) = ?", customer.Email).
+		Update("user_id", customer.ID)
+	return result.RowsAffected, result.Error
+}
+
+// sessionError maps access and refresh token errors of the session store to the
+// errors of this package
+func sessionError(err error) error {
+	if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenExpired) {
+		return ErrInvalidToken
+	}
+	return err
+}
+
+// validateProfile checks the email, name and phone of an account
+func validateProfile(email, name, phone string) error {
+	return models.NewValidator().
+		Required("email", email).
+		Email("email", email).
+		MaxLength("email", email, 255).
+		Required("name", name).
+		MaxLength("name", name, 255).
+		MaxLength("phone", phone, 50).
+		Errors()
+}
+
+// normalizeEmail trims and lower-cases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,52 @@
+package customer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/auth"
+)
+
+func TestAuthenticateRejectsAdminTokens(t *testing.T) {
+	s := NewService(nil, nil, Config{Secret: "secret"})
+	token, err := auth.SignToken(&auth.Claims{
+		Audience:  auth.AudienceAdmin,
+		Subject:   1,
+		SessionID: 1,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}, []byte("secret"))
+	if err != nil {
+		t.Fatalf("SignToken() error = %v", err)
+	}
+
+	if _, err := s.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Authenticate() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	if err := validateProfile("mario@example.com", "Mario Rossi", "+39 333 1234567"); err != nil {
+		t.Errorf("validateProfile() error = %v", err)
+	}
+
+	err := validateProfile("not-an-email", "", "")
+	var validation models.ValidationErrors
+	if !errors.As(err, &validation) || len(validation) != 2 {
+		t.Fatalf("validateProfile() error = %v, want email and name errors", err)
+	}
+}
+
+func TestLink(t *testing.T) {
+	s := NewService(nil, nil, Config{AppURL: "https://shop.example.com/"})
+	if got := s.link("/account/verify", "abc"); got != "https://shop.example.com/account/verify?token=abc" {
+		t.Errorf("link() = %s", got)
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	if got := normalizeEmail("  Mario.Rossi@Example.COM "); got != "mario.rossi@example.com" {
+		t.Errorf("normalizeEmail() = %s", got)
+	}
+}
@@ -48,7 +48,7 @@ func MapListingToProduct(listing *ListingDTO) *models.EnhancedProduct {
 		Title:            listing.Title,
 		ShortDescription: listing.Description,
 		LongDescription:  listing.Description,
-		BasePrice:        listing.Price.GetPriceAmount(),
+		BasePrice:        models.MoneyFromFloat(listing.Price.GetPriceAmount(), listing.Price.CurrencyCode),
 		Currency:         listing.Price.CurrencyCode,
 		SKU:              listing.GetSKU(),
 		Slug:             slug,
@@ -59,7 +59,7 @@ func MapListingToProduct(listing *ListingDTO) *models.EnhancedProduct {
 }
 
 // MapInventoryToProductVariant converts Etsy inventory product to a ProductVariant
-func MapInventoryToProductVariant(product *ListingInventoryProductDTO, listing *ListingDTO, basePrice float64) *models.ProductVariant {
+func MapInventoryToProductVariant(product *ListingInventoryProductDTO, listing *ListingDTO, basePrice models.Money) *models.ProductVariant {
 	if len(product.Offerings) == 0 {
 		return nil
 	}
@@ -74,7 +74,7 @@ func MapInventoryToProductVariant(product *ListingInventoryProductDTO, listing *
 	}
 	
 	// Calculate price adjustment relative to base price
-	priceAdjustment := offering.Price.GetPriceAmount() - basePrice
+	priceAdjustment := models.MoneyFromFloat(offering.Price.GetPriceAmount(), basePrice.Currency).Sub(basePrice)
 	
 	now := time.Now()
 	
@@ -108,7 +108,7 @@ func MapProductToUpdateRequest(product *models.EnhancedProduct, etsyProduct *mod
 					Quantity:  etsyProduct.Quantity,
 					IsEnabled: product.Status == models.ProductStatusPublished,
 					Price: &UpdatePriceDTO{
-						Amount:       product.BasePrice * 100, // Convert to cents
+						Amount:       float64(product.BasePrice.Amount), // Already in cents
 						Divisor:      100,
 						CurrencyCode: product.Currency,
 					},
@@ -123,13 +123,13 @@ func MapProductToUpdateRequest(product *models.EnhancedProduct, etsyProduct *mod
 }
 
 // MapVariantToUpdateRequest converts a product variant to an Etsy offering update
-func MapVariantToUpdateRequest(variant *models.ProductVariant, productID, offeringID int64, basePrice float64, currency string) *UpdateListingInventoryRequest {
+func MapVariantToUpdateRequest(variant *models.ProductVariant, productID, offeringID int64, basePrice models.Money, currency string) *UpdateListingInventoryRequest {
 	if variant == nil {
 		return nil
 	}
 	
 	// Calculate actual price from base price and adjustment
-	actualPrice := basePrice + variant.PriceAdjustment
+	actualPrice := variant.GetPrice(basePrice)
 	
 	products := []UpdateInventoryProductDTO{
 		{
@@ -141,7 +141,7 @@ func MapVariantToUpdateRequest(variant *models.ProductVariant, productID, offeri
 					Quantity:   variant.Stock,
 					IsEnabled:  true, // Variants don't have an IsActive flag
 					Price: &UpdatePriceDTO{
-						Amount:       actualPrice * 100,
+						Amount:       float64(actualPrice.Amount),
 						Divisor:      100,
 						CurrencyCode: currency,
 					},
@@ -587,7 +587,9 @@ func (s *Service) processReceipt(receipt *ReceiptDTO) error {
 	existingReceipt.Subtotal = receipt.Subtotal.GetAmount()
 	existingReceipt.TotalShippingCost = receipt.TotalShippingCost.GetAmount()
 	existingReceipt.TotalTaxCost = receipt.TotalTaxCost.GetAmount()
-	existingReceipt.ShippingAddress = shippingAddress
+	if existingReceipt.AnonymizedAt == nil {
+		existingReceipt.ShippingAddress = shippingAddress
+	}
 	existingReceipt.EtsyUpdatedAt = receipt.GetUpdatedAt()
 	existingReceipt.LastSyncedAt = &now
 	existingReceipt.SyncStatus = "synced"
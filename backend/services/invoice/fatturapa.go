@@ -0,0 +1,539 @@
+package invoice
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+// FatturaPA 1.2 namespaces
+const (
+	fatturaNamespace      = "http://ivaservizi.agenziaentrate.gov.it/docs/xsd/fatture/v1.2"
+	fatturaSchemaLocation = fatturaNamespace + " http://www.fatturapa.gov.it/export/fatturazione/sdi/fatturapa/v1.2/Schema_del_file_xml_FatturaPA_versione_1.2.xsd"
+	formatPrivati         = "FPR12" // Invoices to businesses and consumers, not to public administrations
+)
+
+// Codes of the FatturaPA tables used by the shop
+const (
+	documentTypeInvoice  = "TD01"
+	recipientCodeDefault = "0000000" // Delivered to the customer's fiscal drawer
+	recipientCodeForeign = "XXXXXXX" // Customers without an Italian tax position
+	foreignIDCode        = "99999999999"
+	foreignZipCode       = "00000"
+	paymentTermsFull     = "TP02"
+	paymentMethodCard    = "MP08"
+	vatDueImmediately    = "I"
+)
+
+// Natura codes of lines without Italian VAT, with the law they refer to
+var natures = map[string]string{
+	"N2.2": "Non soggetta - art. 7 DPR 633/72",
+	"N3.1": "Non imponibile - esportazione, art. 8 DPR 633/72",
+	"N7":   "IVA assolta in altro stato UE - vendita a distanza, art. 41 c.1 lett. b DL 331/93",
+}
+
+// ErrInvalidEInvoice is wrapped by SchemaError
+var ErrInvalidEInvoice = errors.New("invalid electronic invoice")
+
+// fatturaElettronica is the root of a FatturaPA document
+type fatturaElettronica struct {
+	XMLName        xml.Name `xml:"p:FatturaElettronica"`
+	Versione       string   `xml:"versione,attr"`
+	XmlnsDS        string   `xml:"xmlns:ds,attr"`
+	XmlnsP         string   `xml:"xmlns:p,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Header         fatturaHeader
+	Body           fatturaBody
+}
+
+type fatturaHeader struct {
+	XMLName                xml.Name `xml:"FatturaElettronicaHeader"`
+	DatiTrasmissione       datiTrasmissione
+	CedentePrestatore      soggetto `xml:"CedentePrestatore"`
+	CessionarioCommittente soggetto `xml:"CessionarioCommittente"`
+}
+
+type datiTrasmissione struct {
+	IdTrasmittente      idFiscale
+	ProgressivoInvio    string
+	FormatoTrasmissione string
+	CodiceDestinatario  string
+}
+
+type idFiscale struct {
+	IdPaese  string
+	IdCodice string
+}
+
+// soggetto is the seller or the customer. RegimeFiscale is only set on the seller.
+type soggetto struct {
+	DatiAnagrafici datiAnagrafici
+	Sede           sede
+}
+
+type datiAnagrafici struct {
+	IdFiscaleIVA  *idFiscale `xml:",omitempty"`
+	CodiceFiscale string     `xml:",omitempty"`
+	Anagrafica    anagrafica
+	RegimeFiscale string `xml:",omitempty"`
+}
+
+type anagrafica struct {
+	Denominazione string `xml:",omitempty"`
+	Nome          string `xml:",omitempty"`
+	Cognome       string `xml:",omitempty"`
+}
+
+type sede struct {
+	Indirizzo string
+	CAP       string
+	Comune    string
+	Provincia string `xml:",omitempty"`
+	Nazione   string
+}
+
+type fatturaBody struct {
+	XMLName         xml.Name `xml:"FatturaElettronicaBody"`
+	DatiGenerali    datiGenerali
+	DatiBeniServizi datiBeniServizi
+	DatiPagamento   *datiPagamento `xml:",omitempty"`
+}
+
+type datiGenerali struct {
+	DatiGeneraliDocumento datiGeneraliDocumento
+}
+
+type datiGeneraliDocumento struct {
+	TipoDocumento          string
+	Divisa                 string
+	Data                   string
+	Numero                 string
+	ImportoTotaleDocumento string
+}
+
+type datiBeniServizi struct {
+	DettaglioLinee []dettaglioLinea
+	DatiRiepilogo  []datiRiepilogo
+}
+
+type dettaglioLinea struct {
+	NumeroLinea    int
+	CodiceArticolo *codiceArticolo `xml:",omitempty"`
+	Descrizione    string
+	Quantita       string
+	PrezzoUnitario string
+	PrezzoTotale   string
+	AliquotaIVA    string
+	Natura         string `xml:",omitempty"`
+
+	// Amounts of the line for the summary, not serialized
+	amount models.Money
+	vat    models.Money
+	rate   int64
+}
+
+type codiceArticolo struct {
+	CodiceTipo   string
+	CodiceValore string
+}
+
+type datiRiepilogo struct {
+	AliquotaIVA          string
+	Natura               string `xml:",omitempty"`
+	ImponibileImporto    string
+	Imposta              string
+	EsigibilitaIVA       string `xml:",omitempty"`
+	RiferimentoNormativo string `xml:",omitempty"`
+}
+
+type datiPagamento struct {
+	CondizioniPagamento string
+	DettaglioPagamento  dettaglioPagamento
+}
+
+type dettaglioPagamento struct {
+	ModalitaPagamento string
+	ImportoPagamento  string
+}
+
+// EInvoice returns the invoice of a paid order with its FatturaPA XML, issuing the invoice
+// the first time like Issue. The XML is generated and stored on the first export and then
+// served as stored. It reports whether the invoice was issued by this call, which can
+// happen even when the XML then fails validation.
+func (s *Service) EInvoice(orderID uint) (*models.Invoice, bool, error) {
+	invoice, issued, err := s.Issue(orderID)
+	if err != nil {
+		return nil, false, err
+	}
+	return invoice, issued, s.ensureEInvoice(invoice)
+}
+
+// ExportMonth builds a ZIP with the FatturaPA XML of every invoice issued in a month, for
+// the accountant. It fails without a ZIP when any invoice does not pass validation.
+func (s *Service) ExportMonth(year int, month time.Month) ([]byte, int, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	var invoices []models.Invoice
+	if err := s.db.Where("issued_at >= ? AND issued_at < ?", start, start.AddDate(0, 1, 0)).
+		Order("year ASC, sequence ASC").Find(&invoices).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var errs []error
+	for i := range invoices {
+		if err := s.ensureEInvoice(&invoices[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, 0, errors.Join(errs...)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, invoice := range invoices {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     s.EInvoiceFileName(&invoice),
+			Method:   zip.Deflate,
+			Modified: invoice.IssuedAt,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, err := file.Write(invoice.EInvoice); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(invoices), nil
+}
+
+// EInvoiceFileName names the XML file of an invoice as the exchange system expects:
+// the transmitter's country and code, then a progressive of five characters
+func (s *Service) EInvoiceFileName(invoice *models.Invoice) string {
+	id := transmitter(s.config.Seller)
+	progressive := strings.ToUpper(strconv.FormatInt(int64(invoice.Year%10)*1000000+int64(invoice.Sequence), 36))
+	return fmt.Sprintf("%s%s_%05s.xml", id.IdPaese, id.IdCodice, progressive)
+}
+
+// ensureEInvoice generates and stores the XML of an invoice that has none yet
+func (s *Service) ensureEInvoice(invoice *models.Invoice) error {
+	if len(invoice.EInvoice) > 0 {
+		return nil
+	}
+
+	order, err := s.loadOrder(invoice.OrderID)
+	if err != nil {
+		return err
+	}
+	document := buildFattura(s.config.Seller, order, invoice)
+	if err := validateFattura(document); err != nil {
+		return err
+	}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	invoice.EInvoice = append([]byte(xml.Header), body...)
+
+	// Another export may have stored it meanwhile; the first one stays
+	return s.db.Model(&models.Invoice{}).Where("id = ? AND e_invoice IS NULL", invoice.ID).
+		Update("e_invoice", invoice.EInvoice).Error
+}
+
+// transmitter is who sends the file: the seller, identified by tax code or VAT number
+func transmitter(seller Seller) idFiscale {
+	id := splitVATID(seller.VATID, sellerCountry(seller))
+	if seller.TaxCode != "" {
+		id.IdCodice = strings.ToUpper(seller.TaxCode)
+	}
+	return id
+}
+
+// sellerCountry is the country the seller is established in, Italy by default
+func sellerCountry(seller Seller) string {
+	if seller.Country == "" {
+		return "IT"
+	}
+	return strings.ToUpper(seller.Country)
+}
+
+// buildFattura maps an invoiced order to a FatturaPA document
+func buildFattura(seller Seller, order *models.Order, invoice *models.Invoice) *fatturaElettronica {
+	country := sellerCountry(seller)
+	sellerID := splitVATID(seller.VATID, country)
+
+	address := parseAddress(order.BillingAddress)
+	if address.Street == "" {
+		address = parseAddress(order.ShippingAddress)
+	}
+	customerCountry := strings.ToUpper(address.Country)
+	if customerCountry == "" {
+		customerCountry = country
+	}
+	foreign := customerCountry != country
+
+	recipientCode := recipientCodeDefault
+	if foreign {
+		recipientCode = recipientCodeForeign
+	}
+
+	lines := fatturaLines(order, country)
+	return &fatturaElettronica{
+		Versione:       formatPrivati,
+		XmlnsDS:        "http://www.w3.org/2000/09/xmldsig#",
+		XmlnsP:         fatturaNamespace,
+		XmlnsXSI:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: fatturaSchemaLocation,
+		Header: fatturaHeader{
+			DatiTrasmissione: datiTrasmissione{
+				IdTrasmittente:      transmitter(seller),
+				ProgressivoInvio:    fmt.Sprintf("%02d%06d", invoice.Year%100, invoice.Sequence),
+				FormatoTrasmissione: formatPrivati,
+				CodiceDestinatario:  recipientCode,
+			},
+			CedentePrestatore: soggetto{
+				DatiAnagrafici: datiAnagrafici{
+					IdFiscaleIVA:  &sellerID,
+					CodiceFiscale: strings.ToUpper(seller.TaxCode),
+					Anagrafica:    anagrafica{Denominazione: latin1(seller.Name)},
+					RegimeFiscale: seller.TaxRegime,
+				},
+				Sede: sede{
+					Indirizzo: latin1(seller.Street),
+					CAP:       seller.ZipCode,
+					Comune:    latin1(seller.City),
+					Provincia: strings.ToUpper(seller.Province),
+					Nazione:   country,
+				},
+			},
+			CessionarioCommittente: customer(order, address, customerCountry, foreign),
+		},
+		Body: fatturaBody{
+			DatiGenerali: datiGenerali{DatiGeneraliDocumento: datiGeneraliDocumento{
+				TipoDocumento:          documentTypeInvoice,
+				Divisa:                 invoice.Currency,
+				Data:                   invoice.IssuedAt.Format("2006-01-02"),
+				Numero:                 invoice.Number,
+				ImportoTotaleDocumento: order.Total.Decimal(),
+			}},
+			DatiBeniServizi: datiBeniServizi{
+				DettaglioLinee: lines,
+				DatiRiepilogo:  summarize(lines),
+			},
+			DatiPagamento: &datiPagamento{
+				CondizioniPagamento: paymentTermsFull,
+				DettaglioPagamento: dettaglioPagamento{
+					ModalitaPagamento: paymentMethodCard,
+					ImportoPagamento:  order.Total.Decimal(),
+				},
+			},
+		},
+	}
+}
+
+// customer builds the CessionarioCommittente. Businesses are identified by VAT number,
+// Italian consumers by tax code; foreign consumers get the conventional foreign code.
+func customer(order *models.Order, address models.Address, country string, foreign bool) soggetto {
+	var data datiAnagrafici
+	if order.CustomerVATID != "" {
+		id := splitVATID(order.CustomerVATID, country)
+		data.IdFiscaleIVA = &id
+	}
+	if !foreign {
+		data.CodiceFiscale = order.CustomerTaxCode
+	} else if data.IdFiscaleIVA == nil {
+		data.IdFiscaleIVA = &idFiscale{IdPaese: country, IdCodice: foreignIDCode}
+	}
+
+	name := latin1(strings.TrimSpace(order.CustomerName))
+	if first, last, ok := strings.Cut(name, " "); ok && order.CustomerVATID == "" {
+		data.Anagrafica = anagrafica{Nome: first, Cognome: strings.TrimSpace(last)}
+	} else {
+		data.Anagrafica = anagrafica{Denominazione: name}
+	}
+
+	location := sede{
+		Indirizzo: latin1(address.Street),
+		CAP:       address.ZipCode,
+		Comune:    latin1(address.City),
+		Nazione:   country,
+	}
+	if foreign {
+		location.CAP = foreignZipCode
+	} else if state := strings.ToUpper(strings.TrimSpace(address.State)); len(state) == 2 {
+		location.Provincia = state
+	}
+	return soggetto{DatiAnagrafici: data, Sede: location}
+}
+
+// fatturaLines lists the order items and shipping with their net amounts. The order
+// discount is spread over the items the way the tax engine did at checkout, so each
+// line carries what was actually charged. Sales taxed in another EU country carry
+// the gross amount and no Italian VAT.
+func fatturaLines(order *models.Order, sellerCountry string) []dettaglioLinea {
+	currency := order.Currency
+	zero := models.NewMoney(0, currency)
+	foreign := order.TaxCountry != "" && order.TaxCountry != sellerCountry
+
+	subtotal, itemsTax := zero, zero
+	for _, item := range order.Items {
+		subtotal = subtotal.Add(item.TotalPrice)
+		itemsTax = itemsTax.Add(item.TaxAmount)
+	}
+
+	var lines []dettaglioLinea
+	add := func(description, sku string, quantity int, taxable, vat models.Money, rate int64) {
+		line := dettaglioLinea{
+			NumeroLinea: len(lines) + 1,
+			Descrizione: latin1(description),
+			Quantita:    fmt.Sprintf("%d.00", quantity),
+			amount:      taxable,
+			vat:         vat,
+			rate:        rate,
+		}
+		if order.PricesIncludeTax {
+			line.amount = taxable.Sub(vat)
+		}
+		switch {
+		case foreign && rate > 0:
+			line.amount, line.vat, line.rate, line.Natura = line.amount.Add(vat), zero, 0, "N7"
+		case foreign:
+			line.Natura = "N3.1"
+		case rate == 0:
+			line.Natura = "N2.2"
+		}
+		if sku != "" {
+			line.CodiceArticolo = &codiceArticolo{CodiceTipo: "SKU", CodiceValore: latin1(sku)}
+		}
+		line.PrezzoUnitario = unitPrice(line.amount, quantity)
+		line.PrezzoTotale = line.amount.Decimal()
+		line.AliquotaIVA = rateDecimal(line.rate)
+		lines = append(lines, line)
+	}
+
+	remaining := order.Discount
+	for i, item := range order.Items {
+		share := remaining
+		if i < len(order.Items)-1 && subtotal.IsPositive() {
+			share = order.Discount.MulRatio(item.TotalPrice.Amount, subtotal.Amount)
+			remaining = remaining.Sub(share)
+		}
+		add(itemName(item), item.SKU, item.Quantity, item.TotalPrice.Sub(share), item.TaxAmount, item.TaxRate)
+	}
+
+	if order.ShippingCost.IsPositive() {
+		description := "Spedizione"
+		if order.ShippingMethodName != "" {
+			description += " - " + order.ShippingMethodName
+		}
+		add(description, "", 1, order.ShippingCost, order.Tax.Sub(itemsTax), shippingRate(order))
+	}
+	return lines
+}
+
+// shippingRate is the VAT rate of the shipping cost. Orders placed before the rate was
+// recorded fall back to the rate of a standard-rated item.
+func shippingRate(order *models.Order) int64 {
+	if order.ShippingTaxRate > 0 {
+		return order.ShippingTaxRate
+	}
+	for _, item := range order.Items {
+		if item.TaxClass == models.TaxClassStandard || item.TaxClass == "" {
+			return item.TaxRate
+		}
+	}
+	return 0
+}
+
+// summarize totals the lines per VAT rate and nature, in order of appearance
+func summarize(lines []dettaglioLinea) []datiRiepilogo {
+	type key struct {
+		rate   int64
+		nature string
+	}
+	var keys []key
+	taxable := map[key]models.Money{}
+	vat := map[key]models.Money{}
+	for _, line := range lines {
+		k := key{line.rate, line.Natura}
+		if _, ok := taxable[k]; !ok {
+			keys = append(keys, k)
+		}
+		taxable[k] = taxable[k].Add(line.amount)
+		vat[k] = vat[k].Add(line.vat)
+	}
+
+	summary := make([]datiRiepilogo, len(keys))
+	for i, k := range keys {
+		summary[i] = datiRiepilogo{
+			AliquotaIVA:          rateDecimal(k.rate),
+			Natura:               k.nature,
+			ImponibileImporto:    taxable[k].Decimal(),
+			Imposta:              vat[k].Decimal(),
+			RiferimentoNormativo: natures[k.nature],
+		}
+		if k.nature == "" {
+			summary[i].EsigibilitaIVA = vatDueImmediately
+		}
+	}
+	return summary
+}
+
+// splitVATID splits a VAT number such as IT01234567890 into country and code. Numbers
+// without a country prefix belong to the fallback country.
+func splitVATID(vatID, fallbackCountry string) idFiscale {
+	vatID = strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(vatID))
+	if len(vatID) > 2 && isLetter(vatID[0]) && isLetter(vatID[1]) {
+		return idFiscale{IdPaese: vatID[:2], IdCodice: vatID[2:]}
+	}
+	return idFiscale{IdPaese: strings.ToUpper(fallbackCountry), IdCodice: vatID}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+// unitPrice divides a line amount by its quantity with up to eight decimals, as the
+// schema allows, so that quantity times unit price gives back the line amount
+func unitPrice(amount models.Money, quantity int) string {
+	if quantity <= 0 {
+		quantity = 1
+	}
+	price := new(big.Rat).SetFrac64(amount.Amount, 100*int64(quantity)).FloatString(8)
+	price = strings.TrimRight(price, "0")
+	if decimals := len(price) - strings.IndexByte(price, '.') - 1; decimals < 2 {
+		price += strings.Repeat("0", 2-decimals)
+	}
+	return price
+}
+
+// rateDecimal formats a rate in basis points as the schema's percentage, e.g. 2200 as "22.00"
+func rateDecimal(rate int64) string {
+	return fmt.Sprintf("%d.%02d", rate/100, rate%100)
+}
+
+// latin1 replaces the characters FatturaPA does not accept, which are those outside
+// Latin-1, and trims the result
+func latin1(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n', r == '\r':
+			return ' '
+		case r < 0x20, r > 0xff, r >= 0x7f && r < 0xa0:
+			return '?'
+		}
+		return r
+	}, s))
+}
@@ -0,0 +1,197 @@
+package invoice
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+var testSeller = Seller{
+	Name:      "Bottega d'Arte S.r.l.",
+	VATID:     "IT01234567890",
+	TaxRegime: "RF01",
+	Street:    "Via Roma 1",
+	ZipCode:   "00100",
+	City:      "Roma",
+	Province:  "RM",
+	Country:   "IT",
+}
+
+// fatturaOrder is an Italian order with VAT-inclusive prices, a discount and shipping
+func fatturaOrder() *models.Order {
+	return &models.Order{
+		OrderNumber:      "ORD-1",
+		CustomerName:     "Mario Rossi",
+		CustomerTaxCode:  "RSSMRA80A01H501U",
+		ShippingAddress:  `{"street":"Via Po 2","city":"Torino","state":"TO","zip_code":"10100","country":"IT"}`,
+		Subtotal:         models.EUR(13200),
+		Discount:         models.EUR(1000),
+		ShippingCost:     models.EUR(610),
+		ShippingTaxRate:  2200,
+		Tax:              models.EUR(2033 + 36 + 110),
+		Total:            models.EUR(12810),
+		Currency:         "EUR",
+		TaxCountry:       "IT",
+		PricesIncludeTax: true,
+		Items: []models.OrderItem{
+			// 12200 gross less a 924 discount share: 11276, of which 2033 VAT
+			{SKU: "PRINT-A3", ProductName: "Stampa", Quantity: 3, TotalPrice: models.EUR(12200), TaxRate: 2200, TaxAmount: models.EUR(2033), TaxClass: models.TaxClassStandard},
+			// 1000 gross less the remaining 76: 924, of which 36 VAT
+			{ProductName: "Libro", Quantity: 1, TotalPrice: models.EUR(1000), TaxRate: 400, TaxAmount: models.EUR(36), TaxClass: models.TaxClassReduced},
+		},
+	}
+}
+
+func testInvoice() *models.Invoice {
+	return &models.Invoice{Number: "INV-2025-000042", Year: 2025, Sequence: 42, Currency: "EUR", IssuedAt: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)}
+}
+
+func TestBuildFatturaDomestic(t *testing.T) {
+	doc := buildFattura(testSeller, fatturaOrder(), testInvoice())
+
+	if err := validateFattura(doc); err != nil {
+		t.Fatalf("validateFattura() error = %v", err)
+	}
+
+	lines := doc.Body.DatiBeniServizi.DettaglioLinee
+	if len(lines) != 3 {
+		t.Fatalf("lines = %d, want 3", len(lines))
+	}
+	if lines[0].PrezzoTotale != "92.43" || lines[0].PrezzoUnitario != "30.81" || lines[0].AliquotaIVA != "22.00" {
+		t.Errorf("item line = %+v", lines[0])
+	}
+	if lines[1].PrezzoTotale != "8.88" || lines[1].AliquotaIVA != "4.00" {
+		t.Errorf("reduced line = %+v", lines[1])
+	}
+	if lines[2].Descrizione != "Spedizione" || lines[2].PrezzoTotale != "5.00" {
+		t.Errorf("shipping line = %+v", lines[2])
+	}
+
+	summary := doc.Body.DatiBeniServizi.DatiRiepilogo
+	if len(summary) != 2 || summary[0].ImponibileImporto != "97.43" || summary[0].Imposta != "21.43" {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	buyer := doc.Header.CessionarioCommittente
+	if buyer.DatiAnagrafici.CodiceFiscale != "RSSMRA80A01H501U" || buyer.DatiAnagrafici.Anagrafica.Cognome != "Rossi" || buyer.Sede.Provincia != "TO" {
+		t.Errorf("customer = %+v", buyer)
+	}
+	if doc.Header.DatiTrasmissione.CodiceDestinatario != recipientCodeDefault || doc.Header.DatiTrasmissione.ProgressivoInvio != "25000042" {
+		t.Errorf("transmission = %+v", doc.Header.DatiTrasmissione)
+	}
+
+	out, err := xml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+	for _, want := range []string{`<p:FatturaElettronica versione="FPR12"`, "<Numero>INV-2025-000042</Numero>", "<ImportoTotaleDocumento>128.10</ImportoTotaleDocumento>"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("XML does not contain %s", want)
+		}
+	}
+	if strings.Contains(string(out), "amount") {
+		t.Errorf("XML contains unexported fields")
+	}
+}
+
+func TestBuildFatturaForeignEU(t *testing.T) {
+	order := fatturaOrder()
+	order.ShippingAddress = `{"street":"Hauptstr. 5","city":"Berlin","zip_code":"10115","country":"DE"}`
+	order.TaxCountry = "DE"
+	order.CustomerTaxCode = ""
+
+	doc := buildFattura(testSeller, order, testInvoice())
+	if err := validateFattura(doc); err != nil {
+		t.Fatalf("validateFattura() error = %v", err)
+	}
+
+	summary := doc.Body.DatiBeniServizi.DatiRiepilogo
+	if len(summary) != 1 || summary[0].Natura != "N7" || summary[0].ImponibileImporto != "128.10" || summary[0].Imposta != "0.00" {
+		t.Errorf("summary = %+v", summary)
+	}
+	buyer := doc.Header.CessionarioCommittente
+	if buyer.DatiAnagrafici.IdFiscaleIVA == nil || buyer.DatiAnagrafici.IdFiscaleIVA.IdCodice != foreignIDCode || buyer.Sede.CAP != foreignZipCode {
+		t.Errorf("customer = %+v", buyer)
+	}
+	if doc.Header.DatiTrasmissione.CodiceDestinatario != recipientCodeForeign {
+		t.Errorf("CodiceDestinatario = %s", doc.Header.DatiTrasmissione.CodiceDestinatario)
+	}
+}
+
+func TestBuildFatturaBusinessCustomer(t *testing.T) {
+	order := fatturaOrder()
+	order.CustomerName = "Galleria Srl"
+	order.CustomerTaxCode = ""
+	order.CustomerVATID = "IT09876543210"
+
+	doc := buildFattura(testSeller, order, testInvoice())
+	if err := validateFattura(doc); err != nil {
+		t.Fatalf("validateFattura() error = %v", err)
+	}
+	buyer := doc.Header.CessionarioCommittente.DatiAnagrafici
+	if buyer.IdFiscaleIVA.IdCodice != "09876543210" || buyer.Anagrafica.Denominazione != "Galleria Srl" {
+		t.Errorf("customer = %+v", buyer)
+	}
+}
+
+func TestValidateFatturaReportsProblems(t *testing.T) {
+	order := fatturaOrder()
+	order.CustomerTaxCode = ""
+	seller := testSeller
+	seller.ZipCode = ""
+
+	err := validateFattura(buildFattura(seller, order, testInvoice()))
+
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, ErrInvalidEInvoice) {
+		t.Fatalf("validateFattura() error = %v, want SchemaError", err)
+	}
+	for _, want := range []string{"CessionarioCommittente/DatiAnagrafici: IdFiscaleIVA or CodiceFiscale", "CedentePrestatore/Sede/CAP"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestValidateFatturaChecksAmounts(t *testing.T) {
+	doc := buildFattura(testSeller, fatturaOrder(), testInvoice())
+	doc.Body.DatiBeniServizi.DettaglioLinee[0].PrezzoTotale = "99.00"
+	doc.Body.DatiBeniServizi.DatiRiepilogo[1].Imposta = "5.00"
+
+	err := validateFattura(doc)
+	if err == nil {
+		t.Fatal("validateFattura() error = nil")
+	}
+	for _, want := range []string{"DettaglioLinee[1]/PrezzoTotale", "DatiRiepilogo[1]/ImponibileImporto", "DatiRiepilogo[2]/Imposta"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestUnitPrice(t *testing.T) {
+	for _, tc := range []struct {
+		cents    int64
+		quantity int
+		want     string
+	}{
+		{1000, 1, "10.00"},
+		{1000, 3, "3.33333333"},
+		{925, 2, "4.625"},
+	} {
+		if got := unitPrice(models.EUR(tc.cents), tc.quantity); got != tc.want {
+			t.Errorf("unitPrice(%d, %d) = %s, want %s", tc.cents, tc.quantity, got, tc.want)
+		}
+	}
+}
+
+func TestEInvoiceFileName(t *testing.T) {
+	s := NewService(nil, Config{Seller: testSeller})
+	if got := s.EInvoiceFileName(testInvoice()); got != "IT01234567890_2Z622.xml" {
+		t.Errorf("EInvoiceFileName() = %s", got)
+	}
+}
@@ -0,0 +1,216 @@
+package invoice
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+// Layout of the A4 documents, in points
+const (
+	marginLeft   = 50.0
+	marginRight  = pageWidth - 50
+	pageBottom   = pageHeight - 70
+	rowHeight    = 16.0
+	bodySize     = 9.0
+	dateLayout   = "02/01/2006"
+	descriptionW = 270.0
+)
+
+// renderInvoice lays out the invoice of an order
+func renderInvoice(seller Seller, order *models.Order, invoice *models.Invoice) []byte {
+	doc := &pdfDocument{}
+	page := doc.addPage()
+
+	y := header(page, seller, "INVOICE", []string{
+		"Invoice no. " + invoice.Number,
+		"Date: " + invoice.IssuedAt.Format(dateLayout),
+		"Order: " + order.OrderNumber,
+	})
+
+	address := parseAddress(order.BillingAddress)
+	if address.Street == "" {
+		address = parseAddress(order.ShippingAddress)
+	}
+	y = party(page, y, "Bill to", append([]string{order.CustomerName, order.CustomerEmail}, addressLines(address)...))
+
+	columns := func(page *pdfPage, y float64) float64 {
+		page.text(marginLeft, y, bodySize, true, "Description")
+		page.textRight(340, y, bodySize, true, "Qty")
+		page.textRight(420, y, bodySize, true, "Unit price")
+		page.textRight(465, y, bodySize, true, "VAT")
+		page.textRight(marginRight, y, bodySize, true, "Amount")
+		page.line(marginLeft, marginRight, y+5)
+		return y + rowHeight + 2
+	}
+	y = columns(page, y)
+
+	for _, item := range order.Items {
+		if y > pageBottom {
+			page = doc.addPage()
+			y = columns(page, 60)
+		}
+		page.text(marginLeft, y, bodySize, false, truncate(itemName(item), descriptionW, bodySize, false))
+		page.textRight(340, y, bodySize, false, fmt.Sprintf("%d", item.Quantity))
+		page.textRight(420, y, bodySize, false, item.UnitPrice.String())
+		page.textRight(465, y, bodySize, false, formatRate(item.TaxRate))
+		page.textRight(marginRight, y, bodySize, false, item.TotalPrice.String())
+		y += rowHeight
+	}
+	page.line(marginLeft, marginRight, y-rowHeight+5)
+
+	// Totals need six rows at most
+	if y+6*rowHeight > pageBottom {
+		page = doc.addPage()
+		y = 60
+	}
+	y += 4
+	total := func(label string, amount models.Money, bold bool) {
+		page.textRight(440, y, bodySize, bold, label)
+		page.textRight(marginRight, y, bodySize, bold, amount.String())
+		y += rowHeight
+	}
+	total("Subtotal", order.Subtotal, false)
+	if order.Discount.IsPositive() {
+		label := "Discount"
+		if order.DiscountCode != "" {
+			label += " (" + order.DiscountCode + ")"
+		}
+		total(label, order.Discount.Mul(-1), false)
+	}
+	if order.ShippingCost.IsPositive() || order.ShippingMethodName != "" {
+		total("Shipping", order.ShippingCost, false)
+	}
+	if order.PricesIncludeTax {
+		total("Total", order.Total, true)
+		total("of which VAT", order.Tax, false)
+	} else {
+		total("VAT", order.Tax, false)
+		total("Total", order.Total, true)
+	}
+
+	y += rowHeight
+	if order.PricesIncludeTax {
+		page.text(marginLeft, y, bodySize, false, "Prices include VAT.")
+	} else {
+		page.text(marginLeft, y, bodySize, false, "Prices exclude VAT.")
+	}
+	if order.TaxCountry != "" {
+		page.text(marginLeft, y+rowHeight, bodySize, false, "VAT rates of "+order.TaxCountry+" applied.")
+	}
+
+	return doc.bytes()
+}
+
+// renderPackingSlip lays out the packing slip of an order: items and shipping address,
+// without prices
+func renderPackingSlip(seller Seller, order *models.Order) []byte {
+	doc := &pdfDocument{}
+	page := doc.addPage()
+
+	details := []string{
+		"Order: " + order.OrderNumber,
+		"Date: " + order.CreatedAt.Format(dateLayout),
+	}
+	if order.ShippingMethodName != "" {
+		details = append(details, "Shipping: "+order.ShippingMethodName)
+	}
+	y := header(page, seller, "PACKING SLIP", details)
+
+	y = party(page, y, "Ship to", append([]string{order.CustomerName}, addressLines(parseAddress(order.ShippingAddress))...))
+
+	columns := func(page *pdfPage, y float64) float64 {
+		page.text(marginLeft, y, bodySize, true, "SKU")
+		page.text(160, y, bodySize, true, "Item")
+		page.textRight(marginRight, y, bodySize, true, "Qty")
+		page.line(marginLeft, marginRight, y+5)
+		return y + rowHeight + 2
+	}
+	y = columns(page, y)
+
+	for _, item := range order.Items {
+		if y > pageBottom {
+			page = doc.addPage()
+			y = columns(page, 60)
+		}
+		page.text(marginLeft, y, bodySize, false, truncate(item.SKU, 100, bodySize, false))
+		page.text(160, y, bodySize, false, truncate(itemName(item), 330, bodySize, false))
+		page.textRight(marginRight, y, bodySize, false, fmt.Sprintf("%d", item.Quantity))
+		y += rowHeight
+	}
+
+	return doc.bytes()
+}
+
+// header writes the seller on the left and the document title and details on the right.
+// It returns where the content below starts.
+func header(page *pdfPage, seller Seller, title string, details []string) float64 {
+	page.text(marginLeft, 60, 16, true, seller.Name)
+	y := 78.0
+	lines := append([]string(nil), seller.AddressLines...)
+	if seller.VATID != "" {
+		lines = append(lines, "VAT ID: "+seller.VATID)
+	}
+	if seller.Email != "" {
+		lines = append(lines, seller.Email)
+	}
+	for _, line := range lines {
+		page.text(marginLeft, y, bodySize, false, line)
+		y += 12
+	}
+
+	page.textRight(marginRight, 60, 18, true, title)
+	right := 80.0
+	for _, detail := range details {
+		page.textRight(marginRight, right, bodySize, false, detail)
+		right += 13
+	}
+
+	return max(y, right) + 30
+}
+
+// party writes a labelled block of lines, such as the customer to bill
+func party(page *pdfPage, y float64, label string, lines []string) float64 {
+	page.text(marginLeft, y, 10, true, label)
+	y += 14
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		page.text(marginLeft, y, bodySize, false, line)
+		y += 12
+	}
+	return y + 30
+}
+
+// parseAddress reads an address stored as JSON on the order
+func parseAddress(raw string) models.Address {
+	var address models.Address
+	if raw != "" {
+		json.Unmarshal([]byte(raw), &address)
+	}
+	return address
+}
+
+// addressLines formats an address for printing
+func addressLines(address models.Address) []string {
+	return []string{address.Street, strings.Join(strings.Fields(address.ZipCode+" "+address.City+" "+address.State), " "), address.Country}
+}
+
+// itemName is the product name with its variant
+func itemName(item models.OrderItem) string {
+	if item.VariantName != "" {
+		return item.ProductName + " - " + item.VariantName
+	}
+	return item.ProductName
+}
+
+// formatRate formats a rate in basis points as a percentage, e.g. 2200 as "22%"
+func formatRate(rate int64) string {
+	if rate%100 == 0 {
+		return fmt.Sprintf("%d%%", rate/100)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%02d", rate/100, rate%100), "0") + "%"
+}
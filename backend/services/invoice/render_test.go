@@ -0,0 +1,93 @@
+package invoice
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func testOrder() *models.Order {
+	return &models.Order{
+		OrderNumber:      "ORD-1",
+		CustomerName:     "Mario Rossi",
+		CustomerEmail:    "mario@example.com",
+		ShippingAddress:  `{"street":"Via Po 2","city":"Torino","zip_code":"10100","country":"IT"}`,
+		Subtotal:         models.EUR(12200),
+		Tax:              models.EUR(2200),
+		Total:            models.EUR(12200),
+		Currency:         "EUR",
+		PricesIncludeTax: true,
+		Items: []models.OrderItem{
+			{SKU: "PRINT-A3", ProductName: "Print (A3)", Quantity: 2, UnitPrice: models.EUR(6100), TotalPrice: models.EUR(12200), TaxRate: 2200},
+		},
+	}
+}
+
+func TestRenderInvoice(t *testing.T) {
+	invoice := &models.Invoice{Number: "INV-2025-000042", IssuedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	document := renderInvoice(Seller{Name: "Shop"}, testOrder(), invoice)
+
+	if !bytes.HasPrefix(document, []byte("%PDF-1.4")) || !bytes.HasSuffix(document, []byte("%%EOF\n")) {
+		t.Fatalf("document is not a PDF")
+	}
+	for _, want := range []string{"INV-2025-000042", "01/03/2025", "Print \\(A3\\)", "22%", "122.00", "Via Po 2"} {
+		if !bytes.Contains(document, []byte(want)) {
+			t.Errorf("invoice does not contain %q", want)
+		}
+	}
+}
+
+func TestRenderPackingSlipHasNoPrices(t *testing.T) {
+	document := renderPackingSlip(Seller{Name: "Shop"}, testOrder())
+
+	if !bytes.Contains(document, []byte("PRINT-A3")) || !bytes.Contains(document, []byte("10100 Torino")) {
+		t.Errorf("packing slip is missing the item or the address")
+	}
+	if bytes.Contains(document, []byte("61.00")) || bytes.Contains(document, []byte("122.00")) {
+		t.Errorf("packing slip contains prices")
+	}
+}
+
+func TestRenderInvoiceAddsPages(t *testing.T) {
+	order := testOrder()
+	for i := 0; i < 80; i++ {
+		order.Items = append(order.Items, order.Items[0])
+	}
+
+	document := renderInvoice(Seller{Name: "Shop"}, order, &models.Invoice{Number: "INV-1"})
+
+	if !bytes.Contains(document, []byte("/Count 3")) {
+		t.Errorf("invoice with 81 lines should have 3 pages")
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	got := escapeText("Città (€5) \\ 日")
+	want := "Citt\xe0 \\(\x805\\) \\\\ ?"
+	if got != want {
+		t.Errorf("escapeText() = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("Illustration ", 20)
+	got := truncate(long, 100, bodySize, false)
+	if !strings.HasSuffix(got, "…") || textWidth(got, bodySize, false) > 100 {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("Print", 100, bodySize, false); got != "Print" {
+		t.Errorf("truncate() = %q, want unchanged", got)
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	for rate, want := range map[int64]string{2200: "22%", 550: "5.5%", 0: "0%", 1025: "10.25%"} {
+		if got := formatRate(rate); got != want {
+			t.Errorf("formatRate(%d) = %q, want %q", rate, got, want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package invoice
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SchemaError lists where a FatturaPA document breaks the rules of the 1.2 schema
+type SchemaError struct {
+	Number   string
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("invoice %s is not a valid FatturaPA: %s", e.Number, strings.Join(e.Problems, "; "))
+}
+
+// Unwrap lets errors.Is match ErrInvalidEInvoice
+func (e *SchemaError) Unwrap() error {
+	return ErrInvalidEInvoice
+}
+
+// Simple types of the FatturaPA 1.2 XSD
+var (
+	countryPattern       = regexp.MustCompile(`^[A-Z]{2}$`)
+	currencyPattern      = regexp.MustCompile(`^[A-Z]{3}$`)
+	italianVATPattern    = regexp.MustCompile(`^[0-9]{11}$`)
+	taxCodePattern       = regexp.MustCompile(`^[A-Z0-9]{11,16}$`)
+	recipientCodePattern = regexp.MustCompile(`^[A-Z0-9]{6,7}$`)
+	progressivePattern   = regexp.MustCompile(`^[A-Za-z0-9]{1,10}$`)
+	zipCodePattern       = regexp.MustCompile(`^[0-9]{5}$`)
+	regimePattern        = regexp.MustCompile(`^RF(0[1-9]|1[0-9])$`)
+	amount2Pattern       = regexp.MustCompile(`^-?[0-9]{1,11}\.[0-9]{2}$`)
+	amount8Pattern       = regexp.MustCompile(`^-?[0-9]{1,11}\.[0-9]{2,8}$`)
+	quantityPattern      = regexp.MustCompile(`^[0-9]{1,12}\.[0-9]{2,8}$`)
+	ratePattern          = regexp.MustCompile(`^[0-9]{1,3}\.[0-9]{2}$`)
+	numberPattern        = regexp.MustCompile(`[0-9]`)
+)
+
+// Tolerances the exchange system applies to computed amounts
+var (
+	lineTolerance    = big.NewRat(1, 100) // Line total against unit price times quantity
+	summaryTolerance = big.NewRat(1, 1)   // VAT and taxable amount of each summary
+)
+
+// schemaCheck collects the problems of a document
+type schemaCheck struct {
+	problems []string
+}
+
+func (c *schemaCheck) fail(path, format string, args ...interface{}) {
+	c.problems = append(c.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *schemaCheck) match(path, value string, pattern *regexp.Regexp) {
+	if !pattern.MatchString(value) {
+		c.fail(path, "%q is not valid", value)
+	}
+}
+
+// text checks a Latin-1 string of min to max characters
+func (c *schemaCheck) text(path, value string, min, max int) {
+	length := utf8.RuneCountInString(value)
+	if length < min || length > max {
+		c.fail(path, "must be %d to %d characters, got %d", min, max, length)
+	}
+	for _, r := range value {
+		if r < 0x20 || r > 0xff || (r >= 0x7f && r < 0xa0) {
+			c.fail(path, "contains %q, outside Latin-1", r)
+			return
+		}
+	}
+}
+
+func (c *schemaCheck) idFiscale(path string, id *idFiscale) {
+	c.match(path+"/IdPaese", id.IdPaese, countryPattern)
+	if id.IdPaese == "IT" {
+		c.match(path+"/IdCodice", id.IdCodice, italianVATPattern)
+	} else {
+		c.text(path+"/IdCodice", id.IdCodice, 1, 28)
+	}
+}
+
+func (c *schemaCheck) anagrafica(path string, a anagrafica) {
+	switch {
+	case a.Denominazione != "" && (a.Nome != "" || a.Cognome != ""):
+		c.fail(path, "Denominazione excludes Nome and Cognome")
+	case a.Denominazione != "":
+		c.text(path+"/Denominazione", a.Denominazione, 1, 80)
+	default:
+		c.text(path+"/Nome", a.Nome, 1, 60)
+		c.text(path+"/Cognome", a.Cognome, 1, 60)
+	}
+}
+
+func (c *schemaCheck) sede(path string, s sede) {
+	c.text(path+"/Indirizzo", s.Indirizzo, 1, 60)
+	c.match(path+"/CAP", s.CAP, zipCodePattern)
+	c.text(path+"/Comune", s.Comune, 1, 60)
+	if s.Provincia != "" {
+		c.match(path+"/Provincia", s.Provincia, countryPattern)
+	}
+	c.match(path+"/Nazione", s.Nazione, countryPattern)
+}
+
+// validateFattura checks a document against the structure and simple types of the
+// FatturaPA 1.2 schema, and the amount checks the exchange system runs on receipt
+func validateFattura(doc *fatturaElettronica) error {
+	c := &schemaCheck{}
+
+	if doc.Versione != formatPrivati {
+		c.fail("versione", "must be %s", formatPrivati)
+	}
+
+	header := "FatturaElettronicaHeader"
+	transmission := doc.Header.DatiTrasmissione
+	c.match(header+"/DatiTrasmissione/IdTrasmittente/IdPaese", transmission.IdTrasmittente.IdPaese, countryPattern)
+	c.text(header+"/DatiTrasmissione/IdTrasmittente/IdCodice", transmission.IdTrasmittente.IdCodice, 1, 28)
+	c.match(header+"/DatiTrasmissione/ProgressivoInvio", transmission.ProgressivoInvio, progressivePattern)
+	if transmission.FormatoTrasmissione != formatPrivati {
+		c.fail(header+"/DatiTrasmissione/FormatoTrasmissione", "must be %s", formatPrivati)
+	}
+	c.match(header+"/DatiTrasmissione/CodiceDestinatario", transmission.CodiceDestinatario, recipientCodePattern)
+
+	seller := doc.Header.CedentePrestatore.DatiAnagrafici
+	path := header + "/CedentePrestatore/DatiAnagrafici"
+	if seller.IdFiscaleIVA == nil {
+		c.fail(path+"/IdFiscaleIVA", "is required")
+	} else {
+		c.idFiscale(path+"/IdFiscaleIVA", seller.IdFiscaleIVA)
+	}
+	if seller.CodiceFiscale != "" {
+		c.match(path+"/CodiceFiscale", seller.CodiceFiscale, taxCodePattern)
+	}
+	c.anagrafica(path+"/Anagrafica", seller.Anagrafica)
+	c.match(path+"/RegimeFiscale", seller.RegimeFiscale, regimePattern)
+	c.sede(header+"/CedentePrestatore/Sede", doc.Header.CedentePrestatore.Sede)
+
+	buyer := doc.Header.CessionarioCommittente.DatiAnagrafici
+	path = header + "/CessionarioCommittente/DatiAnagrafici"
+	if buyer.IdFiscaleIVA == nil && buyer.CodiceFiscale == "" {
+		c.fail(path, "IdFiscaleIVA or CodiceFiscale is required")
+	}
+	if buyer.IdFiscaleIVA != nil {
+		c.idFiscale(path+"/IdFiscaleIVA", buyer.IdFiscaleIVA)
+	}
+	if buyer.CodiceFiscale != "" {
+		c.match(path+"/CodiceFiscale", buyer.CodiceFiscale, taxCodePattern)
+	}
+	c.anagrafica(path+"/Anagrafica", buyer.Anagrafica)
+	c.sede(header+"/CessionarioCommittente/Sede", doc.Header.CessionarioCommittente.Sede)
+
+	body := "FatturaElettronicaBody"
+	general := doc.Body.DatiGenerali.DatiGeneraliDocumento
+	path = body + "/DatiGenerali/DatiGeneraliDocumento"
+	if general.TipoDocumento != documentTypeInvoice {
+		c.fail(path+"/TipoDocumento", "must be %s", documentTypeInvoice)
+	}
+	c.match(path+"/Divisa", general.Divisa, currencyPattern)
+	if _, err := time.Parse("2006-01-02", general.Data); err != nil {
+		c.fail(path+"/Data", "%q is not a date", general.Data)
+	}
+	c.text(path+"/Numero", general.Numero, 1, 20)
+	if !numberPattern.MatchString(general.Numero) {
+		c.fail(path+"/Numero", "must contain a digit")
+	}
+	c.match(path+"/ImportoTotaleDocumento", general.ImportoTotaleDocumento, amount2Pattern)
+
+	c.goods(body+"/DatiBeniServizi", doc.Body.DatiBeniServizi)
+
+	if payment := doc.Body.DatiPagamento; payment != nil {
+		c.match(body+"/DatiPagamento/DettaglioPagamento/ImportoPagamento", payment.DettaglioPagamento.ImportoPagamento, amount2Pattern)
+	}
+
+	if len(c.problems) > 0 {
+		return &SchemaError{Number: general.Numero, Problems: c.problems}
+	}
+	return nil
+}
+
+// goods checks the lines and that the summaries match them
+func (c *schemaCheck) goods(path string, goods datiBeniServizi) {
+	if len(goods.DettaglioLinee) == 0 {
+		c.fail(path+"/DettaglioLinee", "at least one line is required")
+	}
+
+	type key struct{ rate, nature string }
+	totals := map[key]*big.Rat{}
+	for i, line := range goods.DettaglioLinee {
+		linePath := fmt.Sprintf("%s/DettaglioLinee[%d]", path, i+1)
+		if line.NumeroLinea != i+1 {
+			c.fail(linePath+"/NumeroLinea", "must be %d", i+1)
+		}
+		if line.CodiceArticolo != nil {
+			c.text(linePath+"/CodiceArticolo/CodiceTipo", line.CodiceArticolo.CodiceTipo, 1, 35)
+			c.text(linePath+"/CodiceArticolo/CodiceValore", line.CodiceArticolo.CodiceValore, 1, 35)
+		}
+		c.text(linePath+"/Descrizione", line.Descrizione, 1, 1000)
+		c.match(linePath+"/Quantita", line.Quantita, quantityPattern)
+		c.match(linePath+"/PrezzoUnitario", line.PrezzoUnitario, amount8Pattern)
+		c.match(linePath+"/PrezzoTotale", line.PrezzoTotale, amount2Pattern)
+		c.rate(linePath, line.AliquotaIVA, line.Natura)
+
+		quantity, unit, total := decimal(line.Quantita), decimal(line.PrezzoUnitario), decimal(line.PrezzoTotale)
+		if quantity != nil && unit != nil && total != nil {
+			if !within(new(big.Rat).Mul(quantity, unit), total, lineTolerance) {
+				c.fail(linePath+"/PrezzoTotale", "%s is not PrezzoUnitario times Quantita", line.PrezzoTotale)
+			}
+			k := key{line.AliquotaIVA, line.Natura}
+			if totals[k] == nil {
+				totals[k] = new(big.Rat)
+			}
+			totals[k].Add(totals[k], total)
+		}
+	}
+
+	summarized := map[key]bool{}
+	for i, summary := range goods.DatiRiepilogo {
+		summaryPath := fmt.Sprintf("%s/DatiRiepilogo[%d]", path, i+1)
+		c.rate(summaryPath, summary.AliquotaIVA, summary.Natura)
+		c.match(summaryPath+"/ImponibileImporto", summary.ImponibileImporto, amount2Pattern)
+		c.match(summaryPath+"/Imposta", summary.Imposta, amount2Pattern)
+		if summary.Natura != "" && summary.RiferimentoNormativo == "" {
+			c.fail(summaryPath+"/RiferimentoNormativo", "is required with Natura")
+		}
+		if summary.RiferimentoNormativo != "" {
+			c.text(summaryPath+"/RiferimentoNormativo", summary.RiferimentoNormativo, 1, 100)
+		}
+
+		k := key{summary.AliquotaIVA, summary.Natura}
+		summarized[k] = true
+		rate, taxable, vat := decimal(summary.AliquotaIVA), decimal(summary.ImponibileImporto), decimal(summary.Imposta)
+		if rate == nil || taxable == nil || vat == nil {
+			continue
+		}
+		expected := new(big.Rat).Mul(taxable, rate)
+		expected.Quo(expected, big.NewRat(100, 1))
+		if !within(expected, vat, summaryTolerance) {
+			c.fail(summaryPath+"/Imposta", "%s is not ImponibileImporto at %s%%", summary.Imposta, summary.AliquotaIVA)
+		}
+		if lines := totals[k]; lines == nil || !within(lines, taxable, summaryTolerance) {
+			c.fail(summaryPath+"/ImponibileImporto", "%s does not match the lines at %s%%", summary.ImponibileImporto, summary.AliquotaIVA)
+		}
+	}
+	for k := range totals {
+		if !summarized[k] {
+			c.fail(path+"/DatiRiepilogo", "no summary for the lines at %s%% %s", k.rate, k.nature)
+		}
+	}
+}
+
+// rate checks a VAT rate and its nature: a zero rate needs a nature, other rates none
+func (c *schemaCheck) rate(path, rate, nature string) {
+	c.match(path+"/AliquotaIVA", rate, ratePattern)
+	zero := rate == "0.00"
+	switch {
+	case zero && nature == "":
+		c.fail(path+"/Natura", "is required when AliquotaIVA is 0")
+	case !zero && nature != "":
+		c.fail(path+"/Natura", "is only allowed when AliquotaIVA is 0")
+	case nature != "" && natures[nature] == "":
+		c.fail(path+"/Natura", "%q is not supported", nature)
+	}
+}
+
+// decimal parses a decimal of the document, or returns nil
+func decimal(s string) *big.Rat {
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+// within reports whether a and b differ by at most tolerance
+func within(a, b, tolerance *big.Rat) bool {
+	difference := new(big.Rat).Sub(a, b)
+	return difference.Abs(difference).Cmp(tolerance) <= 0
+}
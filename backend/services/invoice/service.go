@@ -0,0 +1,155 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/numbering"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrNotInvoiceable  = errors.New("order cannot be invoiced")
+	ErrInvoiceNotFound = errors.New("invoice not found")
+)
+
+// Seller holds the shop details printed on invoices and packing slips, and the
+// fiscal data of electronic invoices
+type Seller struct {
+	Name         string
+	AddressLines []string
+	VATID        string // With country prefix, e.g. IT01234567890
+	Email        string
+
+	TaxCode   string // Codice fiscale, when it differs from the VAT number
+	TaxRegime string // Regime fiscale, e.g. RF01
+	Street    string
+	ZipCode   string
+	City      string
+	Province  string // Two-letter Italian province code
+	Country   string
+}
+
+// Config holds invoicing settings
+type Config struct {
+	Seller  Seller
+	Numbers *numbering.Generator
+}
+
+// Service issues invoices and renders order documents
+type Service struct {
+	db     *gorm.DB
+	config Config
+}
+
+// NewService creates a new invoice service
+func NewService(db *gorm.DB, config Config) *Service {
+	return &Service{db: db, config: config}
+}
+
+// Issue returns the invoice of a paid order, issuing it with the next number of the year
+// the first time. It reports whether the invoice was issued by this call.
+func (s *Service) Issue(orderID uint) (*models.Invoice, bool, error) {
+	if invoice, err := s.Get(orderID); err == nil {
+		return invoice, false, nil
+	} else if !errors.Is(err, ErrInvoiceNotFound) {
+		return nil, false, err
+	}
+
+	order, err := s.loadOrder(orderID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !invoiceable(order.PaymentStatus) {
+		return nil, false, fmt.Errorf("%w: order %s is %s", ErrNotInvoiceable, order.OrderNumber, order.PaymentStatus)
+	}
+
+	var invoice models.Invoice
+	issued := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the order so that concurrent requests issue a single invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Order{}, orderID).Error; err != nil {
+			return err
+		}
+		err := tx.Where("order_id = ?", orderID).First(&invoice).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Taken inside the transaction, so invoice numbers have no gaps
+		now := time.Now()
+		number, err := s.config.Numbers.Next(tx, now)
+		if err != nil {
+			return err
+		}
+
+		invoice = models.Invoice{
+			OrderID:  order.ID,
+			Number:   number.Value,
+			Year:     number.Year,
+			Sequence: number.Sequence,
+			IssuedAt: now,
+			Total:    order.Total,
+			Currency: order.Currency,
+		}
+		invoice.Document = renderInvoice(s.config.Seller, order, &invoice)
+		issued = true
+		return tx.Create(&invoice).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &invoice, issued, nil
+}
+
+// Get gets the invoice issued for an order
+func (s *Service) Get(orderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	if err := s.db.Where("order_id = ?", orderID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// PackingSlip renders the packing slip of an order
+func (s *Service) PackingSlip(orderID uint) ([]byte, *models.Order, error) {
+	order, err := s.loadOrder(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return renderPackingSlip(s.config.Seller, order), order, nil
+}
+
+// loadOrder loads an order with its items in checkout order
+func (s *Service) loadOrder(orderID uint) (*models.Order, error) {
+	var order models.Order
+	if err := s.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// invoiceable reports whether an order with the payment status was paid
+func invoiceable(status models.PaymentStatus) bool {
+	switch status {
+	case models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	data, err := buildMessage("shop@example.com", Message{
+		To:      "mario@example.com",
+		Subject: "Verifica il tuo indirizzo è",
+		Body:    "Ciao\nclicca qui",
+	}, date)
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+
+	got := string(data)
+	for _, want := range []string{
+		"From: shop@example.com\r\n",
+		"To: mario@example.com\r\n",
+		"Subject: =?utf-8?q?Verifica_il_tuo_indirizzo_=C3=A8?=\r\n",
+		"Date: Sun, 01 Mar 2026 10:00:00 +0000\r\n",
+		"\r\n\r\nCiao\r\nclicca qui",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestBuildMessageRejectsHeaderInjection(t *testing.T) {
+	_, err := buildMessage("shop@example.com", Message{To: "a@example.com\r\nBcc: b@example.com", Subject: "Hi"}, time.Now())
+	if !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("buildMessage() error = %v, want ErrInvalidHeader", err)
+	}
+}
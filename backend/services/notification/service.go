@@ -3,6 +3,7 @@ package notification
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
 	"gorm.io/gorm"
@@ -46,7 +47,7 @@ func (s *Service) CreateLowStockNotification(productID uint, productName string,
 }
 
 // CreatePaymentFailedNotification creates a payment failed notification
-func (s *Service) CreatePaymentFailedNotification(orderNumber string, amount float64, reason string) error {
+func (s *Service) CreatePaymentFailedNotification(orderNumber string, amount models.Money, reason string) error {
 	payload := map[string]interface{}{
 		"order_number": orderNumber,
 		"amount":       amount,
@@ -59,7 +60,7 @@ func (s *Service) CreatePaymentFailedNotification(orderNumber string, amount flo
 		Type:     models.NotificationTypePaymentFailed,
 		Severity: models.NotificationSeverityError,
 		Title:    fmt.Sprintf("Payment Failed: Order %s", orderNumber),
-		Message:  fmt.Sprintf("Payment of €%.2f failed for order %s. Reason: %s", amount, orderNumber, reason),
+		Message:  fmt.Sprintf("Payment of %s failed for order %s. Reason: %s", amount, orderNumber, reason),
 		Payload:  string(payloadJSON),
 	}
 	
@@ -67,7 +68,7 @@ func (s *Service) CreatePaymentFailedNotification(orderNumber string, amount flo
 }
 
 // CreateOrderCreatedNotification creates an order created notification
-func (s *Service) CreateOrderCreatedNotification(orderNumber string, customerEmail string, total float64) error {
+func (s *Service) CreateOrderCreatedNotification(orderNumber string, customerEmail string, total models.Money) error {
 	payload := map[string]interface{}{
 		"order_number":   orderNumber,
 		"customer_email": customerEmail,
@@ -80,7 +81,7 @@ func (s *Service) CreateOrderCreatedNotification(orderNumber string, customerEma
 		Type:     models.NotificationTypeOrderCreated,
 		Severity: models.NotificationSeverityInfo,
 		Title:    fmt.Sprintf("New Order: %s", orderNumber),
-		Message:  fmt.Sprintf("New order from %s for €%.2f", customerEmail, total),
+		Message:  fmt.Sprintf("New order from %s for %s", customerEmail, total),
 		Payload:  string(payloadJSON),
 	}
 	
@@ -88,7 +89,7 @@ func (s *Service) CreateOrderCreatedNotification(orderNumber string, customerEma
 }
 
 // CreateOrderPaidNotification creates an order paid notification
-func (s *Service) CreateOrderPaidNotification(orderNumber string, total float64) error {
+func (s *Service) CreateOrderPaidNotification(orderNumber string, total models.Money) error {
 	payload := map[string]interface{}{
 		"order_number": orderNumber,
 		"total":        total,
@@ -100,7 +101,122 @@ func (s *Service) CreateOrderPaidNotification(orderNumber string, total float64)
 		Type:     models.NotificationTypeOrderPaid,
 		Severity: models.NotificationSeverityInfo,
 		Title:    fmt.Sprintf("Payment Received: Order %s", orderNumber),
-		Message:  fmt.Sprintf("Payment of €%.2f received for order %s", total, orderNumber),
+		Message:  fmt.Sprintf("Payment of %s received for order %s", total, orderNumber),
+		Payload:  string(payloadJSON),
+	}
+	
+	return s.Create(notif)
+}
+
+// CreateOrderCancelledNotification creates an order cancelled notification
+func (s *Service) CreateOrderCancelledNotification(orderNumber string, total models.Money, refunded bool, reason string) error {
+	payload := map[string]interface{}{
+		"order_number": orderNumber,
+		"total":        total,
+		"refunded":     refunded,
+		"reason":       reason,
+	}
+	
+	payloadJSON, _ := json.Marshal(payload)
+	
+	message := fmt.Sprintf("Order %s for %s was cancelled", orderNumber, total)
+	if refunded {
+		message += " and refunded"
+	}
+	if reason != "" {
+		message += ". Reason: " + reason
+	}
+	
+	notif := &models.Notification{
+		Type:     models.NotificationTypeOrderCancelled,
+		Severity: models.NotificationSeverityWarning,
+		Title:    fmt.Sprintf("Order Cancelled: %s", orderNumber),
+		Message:  message,
+		Payload:  string(payloadJSON),
+	}
+	
+	return s.Create(notif)
+}
+
+// CreateOrderExpiredNotification creates an order expired notification
+func (s *Service) CreateOrderExpiredNotification(orderNumber string, total models.Money, age time.Duration) error {
+	payload := map[string]interface{}{
+		"order_number": orderNumber,
+		"total":        total,
+		"age_minutes":  int(age.Minutes()),
+	}
+	
+	payloadJSON, _ := json.Marshal(payload)
+	
+	notif := &models.Notification{
+		Type:     models.NotificationTypeOrderExpired,
+		Severity: models.NotificationSeverityInfo,
+		Title:    fmt.Sprintf("Order Expired: %s", orderNumber),
+		Message:  fmt.Sprintf("Order %s for %s was not paid after %s and has expired; its stock was released", orderNumber, total, age.Round(time.Minute)),
+		Payload:  string(payloadJSON),
+	}
+	
+	return s.Create(notif)
+}
+
+// CreateLatePaymentNotification creates a notification for a payment received after its
+// order expired or was cancelled, which is refunded; a failed refund is left to the admin
+func (s *Service) CreateLatePaymentNotification(orderNumber string, amount models.Money, status models.PaymentStatus, refundErr error) error {
+	payload := map[string]interface{}{
+		"order_number": orderNumber,
+		"amount":       amount,
+		"status":       status,
+		"refunded":     refundErr == nil,
+	}
+	
+	severity := models.NotificationSeverityWarning
+	message := fmt.Sprintf("Payment of %s arrived after order %s was %s and was refunded", amount, orderNumber, status)
+	if refundErr != nil {
+		payload["error"] = refundErr.Error()
+		severity = models.NotificationSeverityError
+		message = fmt.Sprintf("Payment of %s arrived after order %s was %s and could not be refunded: %v. Refund it from the provider dashboard", amount, orderNumber, status, refundErr)
+	}
+	
+	payloadJSON, _ := json.Marshal(payload)
+	
+	notif := &models.Notification{
+		Type:     models.NotificationTypeLatePayment,
+		Severity: severity,
+		Title:    fmt.Sprintf("Late Payment: Order %s", orderNumber),
+		Message:  message,
+		Payload:  string(payloadJSON),
+	}
+	
+	return s.Create(notif)
+}
+
+// CreateReturnNotification creates a notification for a return request reaching a status
+func (s *Service) CreateReturnNotification(rmaNumber string, orderNumber string, status models.ReturnStatus, note string) error {
+	payload := map[string]interface{}{
+		"rma_number":   rmaNumber,
+		"order_number": orderNumber,
+		"status":       status,
+		"note":         note,
+	}
+	
+	payloadJSON, _ := json.Marshal(payload)
+	
+	// New requests wait for an admin decision
+	severity := models.NotificationSeverityInfo
+	if status == models.ReturnStatusRequested {
+		severity = models.NotificationSeverityWarning
+	}
+	
+	message := fmt.Sprintf("Return %s for order %s is now %s", rmaNumber, orderNumber, status)
+	if note != "" {
+		message += ". " + note
+	}
+	
+	notif := &models.Notification{
+		Type:     models.NotificationTypeReturn,
+		Severity: severity,
+		Title:    fmt.Sprintf("Return %s: %s", status, rmaNumber),
+		Message:  message,
 		Payload:  string(payloadJSON),
 	}
 	
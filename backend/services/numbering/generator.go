@@ -0,0 +1,161 @@
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// Names of the sequences used by the shop
+const (
+	SequenceOrder   = "order"
+	SequenceInvoice = "invoice"
+	SequenceRefund  = "refund"
+)
+
+var (
+	// ErrInvalidFormat is returned for formats without exactly one {SEQ} or with unknown tokens
+	ErrInvalidFormat = errors.New("invalid number format")
+	// ErrPeriodChanged is returned for a format that switches between yearly and continuous
+	// numbering, which would restart the counter at 1 and repeat numbers already taken
+	ErrPeriodChanged = errors.New("number format changes the numbering period")
+)
+
+// defaultWidth is the zero padding of {SEQ} without an explicit width
+const defaultWidth = 6
+
+var tokenPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// Format is a number layout such as "AMT-{YYYY}-{SEQ:6}". Tokens are {YYYY} and {YY}
+// for the year and {SEQ} or {SEQ:width} for the zero-padded sequence. Formats with a
+// year restart the sequence at 1 every year.
+type Format struct {
+	layout string
+	width  int
+	yearly bool
+}
+
+// ParseFormat checks a format and reads its sequence width
+func ParseFormat(layout string) (Format, error) {
+	format := Format{layout: layout, width: defaultWidth}
+	sequences := 0
+	for _, match := range tokenPattern.FindAllStringSubmatch(layout, -1) {
+		token := match[1]
+		switch {
+		case token == "YYYY", token == "YY":
+			format.yearly = true
+		case token == "SEQ":
+			sequences++
+		case strings.HasPrefix(token, "SEQ:"):
+			width, err := strconv.Atoi(strings.TrimPrefix(token, "SEQ:"))
+			if err != nil || width < 1 || width > 12 {
+				return Format{}, fmt.Errorf("%w: %q has a bad {SEQ} width", ErrInvalidFormat, layout)
+			}
+			format.width = width
+			sequences++
+		default:
+			return Format{}, fmt.Errorf("%w: %q has unknown token {%s}", ErrInvalidFormat, layout, token)
+		}
+	}
+	if sequences != 1 {
+		return Format{}, fmt.Errorf("%w: %q must contain one {SEQ}", ErrInvalidFormat, layout)
+	}
+	return format, nil
+}
+
+// Yearly reports whether the sequence restarts every year
+func (f Format) Yearly() bool {
+	return f.yearly
+}
+
+// Render formats the sequence number taken in a year
+func (f Format) Render(year, sequence int) string {
+	return tokenPattern.ReplaceAllStringFunc(f.layout, func(token string) string {
+		switch token {
+		case "{YYYY}":
+			return fmt.Sprintf("%04d", year)
+		case "{YY}":
+			return fmt.Sprintf("%02d", year%100)
+		default:
+			return fmt.Sprintf("%0*d", f.width, sequence)
+		}
+	})
+}
+
+// Number is a number handed out by a generator
+type Number struct {
+	Value    string
+	Year     int
+	Sequence int
+}
+
+// Generator hands out numbers of one kind from a counter row in number_sequences
+type Generator struct {
+	name   string
+	format Format
+}
+
+// NewGenerator creates a generator for a sequence name and format
+func NewGenerator(name, layout string) (*Generator, error) {
+	format, err := ParseFormat(layout)
+	if err != nil {
+		return nil, err
+	}
+	return &Generator{name: name, format: format}, nil
+}
+
+// CheckPeriod makes sure the format numbers in the same period as the counters already
+// in use: a yearly sequence cannot become continuous or the other way round. Run it at
+// startup, before any number is taken.
+func (g *Generator) CheckPeriod(db *gorm.DB) error {
+	var periods []int
+	if err := db.Model(&models.NumberSequence{}).Where("name = ?", g.name).Pluck("period", &periods).Error; err != nil {
+		return fmt.Errorf("failed to read %s counters: %w", g.name, err)
+	}
+	return checkPeriods(g.name, g.format, periods)
+}
+
+// checkPeriods compares the period of a format with the periods of the existing counters
+func checkPeriods(name string, format Format, periods []int) error {
+	for _, period := range periods {
+		continuous := period == 0
+		if continuous && format.yearly {
+			return fmt.Errorf("%w: %s numbers are continuous, so %q must not contain {YYYY} or {YY}", ErrPeriodChanged, name, format.layout)
+		}
+		if !continuous && !format.yearly {
+			return fmt.Errorf("%w: %s numbers restart every year, so %q must contain {YYYY} or {YY}", ErrPeriodChanged, name, format.layout)
+		}
+	}
+	return nil
+}
+
+// Next takes the next number at the given time. The increment is a single upsert, so
+// concurrent callers never get the same number. Inside a transaction the counter row
+// stays locked until it ends, which keeps numbers without gaps (needed for invoices)
+// at the cost of serializing callers; outside one, a rolled back caller leaves a gap.
+func (g *Generator) Next(db *gorm.DB, at time.Time) (Number, error) {
+	period := 0
+	if g.format.yearly {
+		period = at.Year()
+	}
+
+	var sequence models.NumberSequence
+	err := db.Raw(`INSERT INTO number_sequences (name, period, last_number) VALUES (?, ?, 1)
+		ON CONFLICT (name, period) DO UPDATE SET last_number = number_sequences.last_number + 1
+		RETURNING name, period, last_number`, g.name, period).Scan(&sequence).Error
+	if err != nil {
+		return Number{}, fmt.Errorf("failed to take %s number: %w", g.name, err)
+	}
+
+	return Number{
+		Value:    g.format.Render(at.Year(), sequence.LastNumber),
+		Year:     at.Year(),
+		Sequence: sequence.LastNumber,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package numbering
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for _, tc := range []struct {
+		layout string
+		yearly bool
+		year   int
+		seq    int
+		want   string
+	}{
+		{"AMT-{YYYY}-{SEQ:6}", true, 2026, 123, "AMT-2026-000123"},
+		{"R{YY}{SEQ:4}", true, 2026, 7, "R260007"},
+		{"ORD-{SEQ}", false, 2026, 42, "ORD-000042"},
+		{"{SEQ:2}", false, 2026, 1234, "1234"},
+	} {
+		format, err := ParseFormat(tc.layout)
+		if err != nil {
+			t.Fatalf("ParseFormat(%q) error = %v", tc.layout, err)
+		}
+		if format.Yearly() != tc.yearly {
+			t.Errorf("ParseFormat(%q).Yearly() = %v", tc.layout, format.Yearly())
+		}
+		if got := format.Render(tc.year, tc.seq); got != tc.want {
+			t.Errorf("Render(%q) = %q, want %q", tc.layout, got, tc.want)
+		}
+	}
+}
+
+func TestParseFormatInvalid(t *testing.T) {
+	for _, layout := range []string{"ORD-{YYYY}", "{SEQ}-{SEQ}", "{SEQ:0}", "{SEQ:x}", "{MM}-{SEQ}"} {
+		if _, err := ParseFormat(layout); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("ParseFormat(%q) error = %v, want ErrInvalidFormat", layout, err)
+		}
+	}
+}
+
+func TestCheckPeriods(t *testing.T) {
+	yearly, _ := ParseFormat("INV-{YYYY}-{SEQ}")
+	continuous, _ := ParseFormat("INV-{SEQ}")
+
+	tests := []struct {
+		format  Format
+		periods []int
+		wantErr bool
+	}{
+		{yearly, nil, false},
+		{continuous, nil, false},
+		{yearly, []int{2025, 2026}, false},
+		{continuous, []int{0}, false},
+		{continuous, []int{2025, 2026}, true},
+		{yearly, []int{0}, true},
+	}
+	for _, tc := range tests {
+		err := checkPeriods(SequenceInvoice, tc.format, tc.periods)
+		if tc.wantErr != errors.Is(err, ErrPeriodChanged) {
+			t.Errorf("checkPeriods(%q, %v) error = %v, want error %v", tc.format.layout, tc.periods, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package order
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// AccessSigner signs order numbers into tokens that let customers view their order
+// without an account. Tokens do not expire and only open the order they were made for.
+type AccessSigner struct {
+	secret []byte
+}
+
+// NewAccessSigner creates a signer with the server secret
+func NewAccessSigner(secret string) *AccessSigner {
+	return &AccessSigner{secret: []byte(secret)}
+}
+
+// Token returns the access token of an order
+func (a *AccessSigner) Token(orderNumber string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte("order-access:" + orderNumber))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks an access token against an order number
+func (a *AccessSigner) Verify(orderNumber, token string) bool {
+	return token != "" && hmac.Equal([]byte(a.Token(orderNumber)), []byte(token))
+}
+
+// GetByNumber gets an order by number with its items and shipments
+func (s *Service) GetByNumber(orderNumber string) (*models.Order, error) {
+	return s.findForCustomer(s.db.Where("order_number = ?", strings.TrimSpace(orderNumber)))
+}
+
+// FindForCustomer gets an order by number when the email is the customer's.
+// A wrong email reports ErrOrderNotFound, so lookups do not reveal which orders exist.
+func (s *Service) FindForCustomer(orderNumber, email string) (*models.Order, error) {
+	return s.findForCustomer(s.db.Where("order_number = ? AND LOWER(customer_email) = ?",
+		strings.TrimSpace(orderNumber), strings.ToLower(strings.TrimSpace(email))))
+}
+
+// ListForCustomer lists the orders of a customer account, newest first
+func (s *Service) ListForCustomer(userID uint, page, perPage int) ([]models.Order, int64, error) {
+	var orders []models.Order
+	var total int64
+
+	query := s.db.Model(&models.Order{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("shipped_at ASC, id ASC")
+	}).Preload("Shipments.Items").Preload("Refunds").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).Limit(perPage).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (s *Service) findForCustomer(query *gorm.DB) (*models.Order, error) {
+	var order models.Order
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Shipments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("shipped_at ASC, id ASC")
+	}).Preload("Shipments.Items").Preload("Refunds").First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CustomerView is what a customer sees of their order: no internal IDs, payment
+// references or admin notes
+type CustomerView struct {
+	OrderNumber       string                   `json:"order_number"`
+	CreatedAt         time.Time                `json:"created_at"`
+	CustomerName      string                   `json:"customer_name"`
+	PaymentStatus     models.PaymentStatus     `json:"payment_status"`
+	FulfillmentStatus models.FulfillmentStatus `json:"fulfillment_status"`
+	Items             []CustomerViewItem       `json:"items"`
+	Shipments         []CustomerViewShipment   `json:"shipments"`
+	ShippingAddress   *models.Address          `json:"shipping_address,omitempty"`
+	ShippingMethod    string                   `json:"shipping_method,omitempty"`
+	Subtotal          models.Money             `json:"subtotal"`
+	Discount          models.Money             `json:"discount"`
+	ShippingCost      models.Money             `json:"shipping_cost"`
+	Tax               models.Money             `json:"tax"`
+	Total             models.Money             `json:"total"`
+	Refunded          models.Money             `json:"refunded"`
+	Currency          string                   `json:"currency"`
+	PricesIncludeTax  bool                     `json:"prices_include_tax"`
+	AccessToken       string                   `json:"access_token,omitempty"`
+}
+
+// CustomerViewItem is an ordered item and how much of it was shipped
+type CustomerViewItem struct {
+	ProductName string       `json:"product_name"`
+	VariantName string       `json:"variant_name,omitempty"`
+	SKU         string       `json:"sku,omitempty"`
+	Quantity    int          `json:"quantity"`
+	Shipped     int          `json:"shipped"`
+	UnitPrice   models.Money `json:"unit_price"`
+	TotalPrice  models.Money `json:"total_price"`
+}
+
+// CustomerViewShipment is a parcel with its tracking
+type CustomerViewShipment struct {
+	Carrier        string                     `json:"carrier,omitempty"`
+	TrackingNumber string                     `json:"tracking_number,omitempty"`
+	TrackingURL    string                     `json:"tracking_url,omitempty"`
+	ShippedAt      time.Time                  `json:"shipped_at"`
+	Items          []CustomerViewShipmentItem `json:"items"`
+}
+
+// CustomerViewShipmentItem is the quantity of an item in a shipment
+type CustomerViewShipmentItem struct {
+	ProductName string `json:"product_name"`
+	VariantName string `json:"variant_name,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
+
+// NewCustomerView builds the customer's view of an order loaded with its items,
+// shipments and refunds
+func NewCustomerView(order *models.Order) *CustomerView {
+	view := &CustomerView{
+		OrderNumber:       order.OrderNumber,
+		CreatedAt:         order.CreatedAt,
+		CustomerName:      order.CustomerName,
+		PaymentStatus:     order.PaymentStatus,
+		FulfillmentStatus: order.FulfillmentStatus,
+		Items:             make([]CustomerViewItem, 0, len(order.Items)),
+		Shipments:         make([]CustomerViewShipment, 0, len(order.Shipments)),
+		ShippingMethod:    order.ShippingMethodName,
+		Subtotal:          order.Subtotal,
+		Discount:          order.Discount,
+		ShippingCost:      order.ShippingCost,
+		Tax:               order.Tax,
+		Total:             order.Total,
+		Refunded:          models.NewMoney(0, order.Currency),
+		Currency:          order.Currency,
+		PricesIncludeTax:  order.PricesIncludeTax,
+	}
+
+	if order.ShippingAddress != "" {
+		var address models.Address
+		if err := json.Unmarshal([]byte(order.ShippingAddress), &address); err == nil {
+			view.ShippingAddress = &address
+		}
+	}
+
+	shipped := models.ShippedQuantities(order.Shipments)
+	items := make(map[uint]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+		view.Items = append(view.Items, CustomerViewItem{
+			ProductName: item.ProductName,
+			VariantName: item.VariantName,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			Shipped:     shipped[item.ID],
+			UnitPrice:   item.UnitPrice,
+			TotalPrice:  item.TotalPrice,
+		})
+	}
+
+	for _, shipment := range order.Shipments {
+		parcel := CustomerViewShipment{
+			Carrier:        shipment.Carrier,
+			TrackingNumber: shipment.TrackingNumber,
+			TrackingURL:    shipment.TrackingURL,
+			ShippedAt:      shipment.ShippedAt,
+			Items:          make([]CustomerViewShipmentItem, 0, len(shipment.Items)),
+		}
+		for _, shipped := range shipment.Items {
+			item := items[shipped.OrderItemID]
+			parcel.Items = append(parcel.Items, CustomerViewShipmentItem{
+				ProductName: item.ProductName,
+				VariantName: item.VariantName,
+				Quantity:    shipped.Quantity,
+			})
+		}
+		view.Shipments = append(view.Shipments, parcel)
+	}
+
+	for _, refund := range order.Refunds {
+		view.Refunded = view.Refunded.Add(refund.Amount)
+	}
+
+	return view
+}
@@ -0,0 +1,66 @@
+package order
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestAccessSigner(t *testing.T) {
+	signer := NewAccessSigner("secret")
+	token := signer.Token("ORD-2026-000001")
+
+	if !signer.Verify("ORD-2026-000001", token) {
+		t.Errorf("Verify() rejected its own token")
+	}
+	if signer.Verify("ORD-2026-000002", token) {
+		t.Errorf("Verify() accepted the token of another order")
+	}
+	if NewAccessSigner("other").Verify("ORD-2026-000001", token) {
+		t.Errorf("Verify() accepted a token signed with another secret")
+	}
+	if signer.Verify("ORD-2026-000001", "") {
+		t.Errorf("Verify() accepted an empty token")
+	}
+}
+
+func TestCustomerViewHidesInternalFields(t *testing.T) {
+	order := &models.Order{
+		ID:                 7,
+		OrderNumber:        "ORD-1",
+		CustomerEmail:      "mario@example.com",
+		PaymentIntentID:    "pi_secret",
+		Notes:              "VIP",
+		ShippingAddress:    `{"street":"Via Po 2","city":"Torino","zip_code":"10100","country":"IT"}`,
+		ShippingMethodName: "Express",
+		Total:              models.EUR(5000),
+		Currency:           "EUR",
+		Items: []models.OrderItem{
+			{ID: 11, OrderID: 7, ProductName: "Print", Quantity: 2, TotalPrice: models.EUR(5000)},
+		},
+		Shipments: []models.Shipment{
+			{ID: 5, OrderID: 7, Carrier: "BRT", Items: []models.ShipmentItem{{ID: 9, OrderItemID: 11, Quantity: 1}}},
+		},
+		Refunds: []models.Refund{{ID: 4, Amount: models.EUR(1000)}},
+	}
+
+	view := NewCustomerView(order)
+	if view.Items[0].Shipped != 1 || view.Shipments[0].Items[0].ProductName != "Print" || view.Refunded.Amount != 1000 {
+		t.Errorf("view = %+v", view)
+	}
+	if view.ShippingAddress == nil || view.ShippingAddress.City != "Torino" {
+		t.Errorf("shipping address = %+v", view.ShippingAddress)
+	}
+
+	out, err := json.Marshal(view)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, hidden := range []string{`"id"`, `"order_id"`, `"product_id"`, "pi_secret", "VIP", "mario@example.com"} {
+		if strings.Contains(string(out), hidden) {
+			t.Errorf("view exposes %s", hidden)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"gorm.io/gorm"
+)
+
+var ErrCancelFailed = errors.New("cancellation failed")
+
+// CancelOrder cancels an order that has not shipped yet. The payment intent is voided
+// when unpaid; a paid order is fully refunded and the refund recorded like any other, so
+// RefundOrder never gives the money back again. Reserved stock goes back to the variants
+// and the discount code usage is given back. The order is claimed before the payment is
+// touched, so a payment webhook or the expiry job settling it meanwhile makes the
+// cancellation fail instead of restocking twice.
+func (s *Service) CancelOrder(id uint, reason string) error {
+	var order models.Order
+	if err := s.db.Preload("Items").Preload("Refunds.Items").First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrderNotFound
+		}
+		return err
+	}
+
+	// Check both state machines before touching the payment
+	if err := CanTransitionPayment(order.PaymentStatus, models.PaymentStatusCancelled); err != nil {
+		return err
+	}
+	if err := CanTransitionFulfillment(order.FulfillmentStatus, models.FulfillmentStatusCancelled); err != nil {
+		return err
+	}
+
+	note := reason
+	if note == "" {
+		note = "Order cancelled"
+	}
+
+	var response *payment.RefundResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the order in the statuses checked above; the row stays locked until commit
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND payment_status = ? AND fulfillment_status = ?", order.ID, order.PaymentStatus, order.FulfillmentStatus).
+			Updates(map[string]interface{}{
+				"payment_status":     models.PaymentStatusCancelled,
+				"fulfillment_status": models.FulfillmentStatusCancelled,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: order %s changed while being cancelled", ErrInvalidTransition, order.OrderNumber)
+		}
+
+		var err error
+		if response, err = s.voidPayment(&order); err != nil {
+			return err
+		}
+		if response != nil {
+			if err := s.recordCancelRefund(tx, &order, response, note); err != nil {
+				return err
+			}
+		}
+		if err := restock(tx, order.Items); err != nil {
+			return err
+		}
+		if err := transitionPayment(tx, &order, models.PaymentStatusCancelled, note); err != nil {
+			return err
+		}
+		if err := transitionFulfillment(tx, &order, models.FulfillmentStatusCancelled, note); err != nil {
+			return err
+		}
+		if err := tx.Save(&order).Error; err != nil {
+			return err
+		}
+		return releaseDiscount(tx, order.DiscountCode)
+	})
+	if err != nil && response != nil {
+		// The provider already refunded: the record is needed to reconcile by hand
+		return fmt.Errorf("refund %s of order %s was not recorded: %w", response.RefundID, order.OrderNumber, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.notifService.CreateOrderCancelledNotification(order.OrderNumber, order.Total, response != nil, reason)
+	return nil
+}
+
+// voidPayment cancels the payment intent of an unpaid order or fully refunds a paid one
+// under the order's next refund reference. It returns the refund, nil when no money was
+// refunded.
+func (s *Service) voidPayment(order *models.Order) (*payment.RefundResponse, error) {
+	if order.PaymentIntentID == "" {
+		return nil, nil
+	}
+
+	provider, err := s.payments.ByName(order.PaymentProvider)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCancelFailed, err)
+	}
+
+	if order.PaymentStatus == models.PaymentStatusPaid {
+		response, err := provider.Refund(order.PaymentIntentID, nil, refundReference(order))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+		return response, nil
+	}
+
+	if err := provider.CancelPayment(order.PaymentIntentID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCancelFailed, err)
+	}
+	return nil, nil
+}
+
+// recordCancelRefund records the refund of a cancelled order: everything left of its
+// total, covering every item
+func (s *Service) recordCancelRefund(tx *gorm.DB, order *models.Order, response *payment.RefundResponse, reason string) error {
+	items, err := refundItems(order, nil, true)
+	if err != nil {
+		return err
+	}
+	number, err := s.refundNumbers.Next(tx, time.Now())
+	if err != nil {
+		return err
+	}
+	refund := models.Refund{
+		OrderID:          order.ID,
+		Number:           number.Value,
+		Provider:         order.PaymentProvider,
+		ProviderRefundID: response.RefundID,
+		Amount:           order.Total.Sub(models.RefundedAmount(order.Refunds, order.Currency)),
+		Currency:         order.Currency,
+		Status:           response.Status,
+		Reason:           reason,
+		Items:            items,
+	}
+	if err := tx.Create(&refund).Error; err != nil {
+		return err
+	}
+	order.Refunds = append(order.Refunds, refund)
+	return nil
+}
+
+// restock puts the quantities of order items back on their variants
+func restock(tx *gorm.DB, items []models.OrderItem) error {
+	for _, item := range items {
+		if item.VariantID == nil {
+			continue
+		}
+		if err := tx.Model(&models.ProductVariant{}).
+			Where("id = ?", *item.VariantID).
+			Update("stock", gorm.Expr("stock + ?", item.Quantity)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseDiscount gives back a use of the discount code an order counted
+func releaseDiscount(tx *gorm.DB, code string) error {
+	if code == "" {
+		return nil
+	}
+	return tx.Model(&models.DiscountCode{}).
+		Where("code = ? AND used_count > 0", code).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
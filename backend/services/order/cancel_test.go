@@ -0,0 +1,47 @@
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+)
+
+func TestVoidPayment(t *testing.T) {
+	provider := payment.NewMockProvider("mock", 50, false)
+	s := &Service{payments: payment.NewRegistry(provider)}
+
+	intent, err := provider.CreatePaymentIntent(&payment.CreatePaymentIntentRequest{Amount: models.EUR(1000)})
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+
+	// Unpaid orders void the intent
+	pending := &models.Order{PaymentStatus: models.PaymentStatusPending, PaymentProvider: "mock", PaymentIntentID: intent.ID}
+	if refund, err := s.voidPayment(pending); err != nil || refund != nil {
+		t.Fatalf("voidPayment(pending) = %v, %v", refund, err)
+	}
+	if _, err := provider.GetPaymentIntent(intent.ID); !errors.Is(err, payment.ErrInvalidIntentID) {
+		t.Errorf("intent not cancelled: %v", err)
+	}
+	if _, err := s.voidPayment(pending); !errors.Is(err, ErrCancelFailed) {
+		t.Errorf("cancelling twice: error = %v, want ErrCancelFailed", err)
+	}
+
+	// Paid orders are refunded
+	paid := &models.Order{OrderNumber: "ORD-1", PaymentStatus: models.PaymentStatusPaid, PaymentProvider: "mock", PaymentIntentID: "mock_pi_paid"}
+	if refund, err := s.voidPayment(paid); err != nil || refund == nil {
+		t.Errorf("voidPayment(paid) = %v, %v", refund, err)
+	}
+
+	provider.SetShouldFail(true, "declined")
+	if _, err := s.voidPayment(paid); !errors.Is(err, ErrRefundFailed) {
+		t.Errorf("failed refund: error = %v, want ErrRefundFailed", err)
+	}
+
+	// Orders without an intent have nothing to void
+	if refund, err := s.voidPayment(&models.Order{PaymentStatus: models.PaymentStatusPending}); err != nil || refund != nil {
+		t.Errorf("voidPayment(no intent) = %v, %v", refund, err)
+	}
+}
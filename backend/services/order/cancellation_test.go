@@ -0,0 +1,109 @@
+//go:build integration
+
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"gorm.io/gorm"
+)
+
+// useDiscount creates a discount code used once, by the order
+func useDiscount(t *testing.T, db *gorm.DB, order *models.Order, code string) {
+	t.Helper()
+	discount := models.DiscountCode{Code: code, Type: "percentage", Value: 10, Currency: "EUR", UsedCount: 1, Active: true}
+	if err := db.Create(&discount).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(order).Update("discount_code", code).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertReleased checks that an order gave back its piece of stock and its discount use
+func assertReleased(t *testing.T, db *gorm.DB, variant *models.ProductVariant, code string) {
+	t.Helper()
+	var discount models.DiscountCode
+	db.Where("code = ?", code).First(&discount)
+	db.First(variant, variant.ID)
+	if variant.Stock != 1 || discount.UsedCount != 0 {
+		t.Errorf("stock = %d and discount used %d times, want 1 and 0", variant.Stock, discount.UsedCount)
+	}
+}
+
+func TestCancelUnpaidOrder(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+	useDiscount(t, db, order, "SPRING10")
+
+	if err := s.CancelOrder(order.ID, "Customer changed their mind"); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+
+	var got models.Order
+	db.Preload("Refunds").First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusCancelled || got.FulfillmentStatus != models.FulfillmentStatusCancelled {
+		t.Errorf("statuses = %s/%s, want cancelled/cancelled", got.PaymentStatus, got.FulfillmentStatus)
+	}
+	if len(got.Refunds) != 0 {
+		t.Errorf("refunds = %+v, want none for an unpaid order", got.Refunds)
+	}
+	if _, err := provider.GetPaymentIntent(order.PaymentIntentID); !errors.Is(err, payment.ErrInvalidIntentID) {
+		t.Errorf("payment intent not voided: %v", err)
+	}
+	assertReleased(t, db, variant, "SPRING10")
+
+	// A second cancellation changes nothing
+	if err := s.CancelOrder(order.ID, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second CancelOrder() error = %v, want ErrInvalidTransition", err)
+	}
+	assertReleased(t, db, variant, "SPRING10")
+}
+
+func TestCancelPaidOrderRecordsRefund(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+	useDiscount(t, db, order, "SPRING10")
+	if err := s.HandlePaymentSuccess("mock", order.PaymentIntentID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CancelOrder(order.ID, "Out of frames"); err != nil {
+		t.Fatalf("CancelOrder() error = %v", err)
+	}
+
+	var got models.Order
+	db.Preload("Refunds.Items").First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusCancelled {
+		t.Errorf("payment status = %s, want cancelled", got.PaymentStatus)
+	}
+	if len(got.Refunds) != 1 {
+		t.Fatalf("refunds = %+v, want one", got.Refunds)
+	}
+	refund := got.Refunds[0]
+	if refund.Number == "" || refund.Amount != models.EUR(2500) || len(refund.Items) != 1 || refund.Items[0].Quantity != 1 {
+		t.Errorf("refund = %+v, want the numbered refund of the whole order", refund)
+	}
+	assertReleased(t, db, variant, "SPRING10")
+
+	// The money cannot be given back a second time
+	if _, err := s.RefundOrder(order.ID, &RefundRequest{}); !errors.Is(err, ErrInvalidRefund) && !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("RefundOrder() after cancelling: error = %v, want the refund refused", err)
+	}
+	if err := s.CancelOrder(order.ID, ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second CancelOrder() error = %v, want ErrInvalidTransition", err)
+	}
+	var refunds int64
+	db.Model(&models.Refund{}).Where("order_id = ?", order.ID).Count(&refunds)
+	if refunds != 1 {
+		t.Errorf("%d refunds recorded, want 1", refunds)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package order
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+// createCart creates a cart holding one piece of the variant, loaded as checkout loads it
+func createCart(t *testing.T, db *gorm.DB, variant *models.ProductVariant, sessionToken string) *models.Cart {
+	t.Helper()
+	cart := models.Cart{
+		SessionToken: sessionToken,
+		Items:        []models.CartItem{{ProductID: variant.ProductID, VariantID: &variant.ID, Quantity: 1}},
+	}
+	if err := db.Create(&cart).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Preload("Items.Product").Preload("Items.Variant").First(&cart, cart.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &cart
+}
+
+func TestConcurrentCheckoutOfLastPiece(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	req := &models.CheckoutRequest{
+		Email:           "mario@example.com",
+		Name:            "Mario Rossi",
+		ShippingAddress: models.Address{Street: "Via Roma 1", City: "Milano", ZipCode: "20100", Country: "IT"},
+	}
+
+	for i := 0; i < 10; i++ {
+		variant := testdb.CreateVariant(t, db, fmt.Sprintf("PAINTING-%d", i), 1)
+		// Both carts were filled before either checked out, and both still see the piece
+		carts := []*models.Cart{
+			createCart(t, db, variant, fmt.Sprintf("session-a-%d", i)),
+			createCart(t, db, variant, fmt.Sprintf("session-b-%d", i)),
+		}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(carts))
+		for j, cart := range carts {
+			wg.Add(1)
+			go func(j int, cart *models.Cart) {
+				defer wg.Done()
+				_, _, errs[j] = s.CreateOrder(cart, req, nil, nil, provider)
+			}(j, cart)
+		}
+		wg.Wait()
+
+		var sold, outOfStock int
+		for _, err := range errs {
+			switch {
+			case err == nil:
+				sold++
+			case errors.Is(err, ErrInsufficientStock):
+				outOfStock++
+			default:
+				t.Fatalf("CreateOrder() error = %v", err)
+			}
+		}
+		if sold != 1 || outOfStock != 1 {
+			t.Errorf("%s: %d orders and %d out of stock, want one of each", variant.SKU, sold, outOfStock)
+		}
+
+		var orders int64
+		db.Model(&models.OrderItem{}).Where("variant_id = ?", variant.ID).Count(&orders)
+		db.First(variant, variant.ID)
+		if variant.Stock != 0 || orders != 1 {
+			t.Errorf("%s: stock %d with %d order lines, want stock 0 and one line", variant.SKU, variant.Stock, orders)
+		}
+	}
+}
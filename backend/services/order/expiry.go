@@ -0,0 +1,115 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"gorm.io/gorm"
+)
+
+// expiryBatchSize caps the number of pending orders checked per run
+const expiryBatchSize = 100
+
+// errOrderSettled means the order left pending before it could be claimed
+var errOrderSettled = errors.New("order is no longer pending")
+
+// ExpirePendingOrders checks the pending orders older than ttl against their payment
+// provider. Orders whose intent succeeded are marked paid; the others expire and release
+// their stock, unless the provider is still processing the payment.
+// It is meant to run periodically from the scheduler.
+func (s *Service) ExpirePendingOrders(ctx context.Context, ttl time.Duration) error {
+	var orders []models.Order
+	if err := s.db.Preload("Items").
+		Where("payment_status = ? AND created_at < ?", models.PaymentStatusPending, time.Now().Add(-ttl)).
+		Order("created_at ASC").
+		Limit(expiryBatchSize).
+		Find(&orders).Error; err != nil {
+		return err
+	}
+
+	failures := 0
+	for i := range orders {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.expireOrder(&orders[i]); err != nil {
+			failures++
+			log.Printf("Expiry check of order %s failed: %v", orders[i].OrderNumber, err)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d pending orders could not be checked", failures, len(orders))
+	}
+	return nil
+}
+
+// expireOrder settles a single stale pending order from its payment intent status
+func (s *Service) expireOrder(order *models.Order) error {
+	if order.PaymentIntentID != "" {
+		provider, err := s.payments.ByName(order.PaymentProvider)
+		if err != nil {
+			return err
+		}
+
+		intent, err := provider.GetPaymentIntent(order.PaymentIntentID)
+		switch {
+		case errors.Is(err, payment.ErrInvalidIntentID):
+			// The provider no longer knows the intent: nothing can be paid
+		case err != nil:
+			return err
+		case intent.Status == models.PaymentIntentStatusSucceeded:
+			// The success webhook was missed
+			return s.HandlePaymentSuccess(provider.Name(), order.PaymentIntentID)
+		case intent.Status == models.PaymentIntentStatusProcessing, intent.Status == "":
+			return nil
+		case intent.Status == models.PaymentIntentStatusPending:
+			if err := provider.CancelPayment(order.PaymentIntentID); err != nil {
+				return err
+			}
+		}
+	}
+
+	age := time.Since(order.CreatedAt)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the order so a payment webhook arriving meanwhile wins
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND payment_status = ?", order.ID, models.PaymentStatusPending).
+			Update("payment_status", models.PaymentStatusExpired)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderSettled
+		}
+
+		if err := restock(tx, order.Items); err != nil {
+			return err
+		}
+		note := fmt.Sprintf("Not paid within %s", age.Round(time.Minute))
+		if err := transitionPayment(tx, order, models.PaymentStatusExpired, note); err != nil {
+			return err
+		}
+		if err := transitionFulfillment(tx, order, models.FulfillmentStatusCancelled, note); err != nil {
+			return err
+		}
+		if err := tx.Save(order).Error; err != nil {
+			return err
+		}
+		return releaseDiscount(tx, order.DiscountCode)
+	})
+	if errors.Is(err, errOrderSettled) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.notifService.CreateOrderExpiredNotification(order.OrderNumber, order.Total, age)
+	return nil
+}
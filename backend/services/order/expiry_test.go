@@ -0,0 +1,151 @@
+//go:build integration
+
+package order
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/notification"
+	"github.com/Naim0996/art-management-tool/backend/services/numbering"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
+	"gorm.io/gorm"
+)
+
+// newTestService creates an order service on a test database with a mock provider
+func newTestService(t *testing.T, db *gorm.DB) (*Service, *payment.MockProvider) {
+	t.Helper()
+	provider := payment.NewMockProvider("mock", 50, false)
+	orderNumbers, err := numbering.NewGenerator(numbering.SequenceOrder, "ORD-{YYYY}-{SEQ:6}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refundNumbers, err := numbering.NewGenerator(numbering.SequenceRefund, "REF-{YYYY}-{SEQ:6}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxService := tax.NewService(db, tax.Config{})
+	return NewService(db, payment.NewRegistry(provider), taxService, notification.NewService(db), orderNumbers, refundNumbers), provider
+}
+
+// createStalePendingOrder creates a pending order for one piece of the variant, whose
+// stock was taken at checkout, with a payment intent still waiting for the customer
+func createStalePendingOrder(t *testing.T, db *gorm.DB, provider *payment.MockProvider, variant *models.ProductVariant, number string) *models.Order {
+	t.Helper()
+	intent, err := provider.CreatePaymentIntent(&payment.CreatePaymentIntentRequest{Amount: models.EUR(2500)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testdb.CreateOrder(t, db, models.Order{
+		OrderNumber:     number,
+		PaymentIntentID: intent.ID,
+		PaymentProvider: provider.Name(),
+		CreatedAt:       time.Now().Add(-2 * time.Hour),
+	}, variant)
+}
+
+func TestPaymentAfterExpiryIsRefunded(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+
+	if err := s.expireOrder(order); err != nil {
+		t.Fatalf("expireOrder() error = %v", err)
+	}
+	// The webhook is redelivered: the order is refunded once and never paid
+	for i := 0; i < 2; i++ {
+		if err := s.HandlePaymentSuccess("mock", order.PaymentIntentID); err != nil {
+			t.Fatalf("HandlePaymentSuccess() error = %v", err)
+		}
+	}
+
+	var got models.Order
+	db.Preload("Refunds").Preload("Events").First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusExpired {
+		t.Errorf("payment status = %s, want expired", got.PaymentStatus)
+	}
+	if len(got.Refunds) != 1 || got.Refunds[0].Amount != models.EUR(2500) {
+		t.Errorf("refunds = %+v, want one of the order total", got.Refunds)
+	}
+	last := got.Events[len(got.Events)-1]
+	if last.FromStatus != string(models.PaymentStatusExpired) || last.ToStatus != string(models.PaymentStatusExpired) {
+		t.Errorf("last event = %+v, want the late payment on the expired order", last)
+	}
+	db.First(variant, variant.ID)
+	if variant.Stock != 1 {
+		t.Errorf("stock = %d, want 1", variant.Stock)
+	}
+}
+
+func TestPaymentRacingExpiry(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+
+	for i := 0; i < 20; i++ {
+		variant := testdb.CreateVariant(t, db, fmt.Sprintf("PRINT-%d", i), 1)
+		order := createStalePendingOrder(t, db, provider, variant, fmt.Sprintf("ORD-%d", i))
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs[0] = s.expireOrder(order)
+		}()
+		go func() {
+			defer wg.Done()
+			errs[1] = s.HandlePaymentSuccess("mock", order.PaymentIntentID)
+		}()
+		wg.Wait()
+		if errs[0] != nil || errs[1] != nil {
+			t.Fatalf("expireOrder() = %v, HandlePaymentSuccess() = %v", errs[0], errs[1])
+		}
+
+		var got models.Order
+		db.Preload("Refunds").First(&got, order.ID)
+		db.First(variant, variant.ID)
+		switch got.PaymentStatus {
+		case models.PaymentStatusPaid:
+			if variant.Stock != 0 || len(got.Refunds) != 0 {
+				t.Errorf("%s paid with stock %d and %d refunds, want stock 0 and no refund", got.OrderNumber, variant.Stock, len(got.Refunds))
+			}
+		case models.PaymentStatusExpired:
+			if variant.Stock != 1 || len(got.Refunds) != 1 {
+				t.Errorf("%s expired with stock %d and %d refunds, want stock 1 and one refund", got.OrderNumber, variant.Stock, len(got.Refunds))
+			}
+		default:
+			t.Errorf("%s payment status = %s", got.OrderNumber, got.PaymentStatus)
+		}
+	}
+}
+
+func TestPaymentFailedReleasesStockAndDiscount(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+	useDiscount(t, db, order, "SPRING10")
+
+	// The webhook is redelivered, and the expiry job runs afterwards
+	for i := 0; i < 2; i++ {
+		if err := s.HandlePaymentFailed("mock", order.PaymentIntentID, "card declined"); err != nil {
+			t.Fatalf("HandlePaymentFailed() error = %v", err)
+		}
+	}
+	if err := s.expireOrder(order); err != nil {
+		t.Fatalf("expireOrder() error = %v", err)
+	}
+
+	var got models.Order
+	db.First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusFailed {
+		t.Errorf("payment status = %s, want failed", got.PaymentStatus)
+	}
+	assertReleased(t, db, variant, "SPRING10")
+}
@@ -0,0 +1,262 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRefund = errors.New("invalid refund")
+
+// RefundRequest describes a refund. The listed items are restocked; without an amount the
+// refund is worth what the customer paid for them. A request with neither refunds
+// everything left and restocks only what has not shipped.
+type RefundRequest struct {
+	Amount    *models.Money
+	Items     []models.RefundItem
+	Reason    string
+	Restocked bool // The items are already dealt with, e.g. by a return
+}
+
+// RefundOrder refunds a paid order through the provider that took the payment and records
+// the refund. The order becomes refunded once its whole total has been given back and
+// partially refunded until then. The order row stays locked from the check of what is
+// left to refund until the refund is recorded, so refunds sent together cannot give back
+// more than the total.
+func (s *Service) RefundOrder(id uint, req *RefundRequest) (*models.Refund, error) {
+	var refund models.Refund
+	var order models.Order
+	var response *payment.RefundResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&order, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+		if err := tx.Preload("Items").Preload("Shipments.Items").Preload("Refunds.Items").First(&order, id).Error; err != nil {
+			return err
+		}
+
+		full := req.Amount == nil && len(req.Items) == 0
+		items, err := refundItems(&order, req.Items, full)
+		if err != nil {
+			return err
+		}
+
+		remaining := order.Total.Sub(models.RefundedAmount(order.Refunds, order.Currency))
+		var amount models.Money
+		switch {
+		case full:
+			amount = remaining
+		case req.Amount == nil:
+			// Rounding of the item shares must not take the refunds past the total
+			amount = refundValue(&order, items).Min(remaining)
+		default:
+			amount = req.Amount.WithCurrency(order.Currency)
+			if amount.Cmp(remaining) > 0 {
+				return fmt.Errorf("%w: only %s of order %s is left to refund", ErrInvalidRefund, remaining, order.OrderNumber)
+			}
+		}
+		if !amount.IsPositive() {
+			return fmt.Errorf("%w: refund amount must be positive", ErrInvalidRefund)
+		}
+
+		status := models.PaymentStatusPartiallyRefunded
+		if amount.Cmp(remaining) == 0 {
+			status = models.PaymentStatusRefunded
+		}
+		if err := CanTransitionPayment(order.PaymentStatus, status); err != nil {
+			return err
+		}
+
+		provider, err := s.payments.ByName(order.PaymentProvider)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+
+		// A first refund of the whole total is requested without an amount, as a full refund
+		var requested *models.Money
+		if amount.Cmp(order.Total) != 0 {
+			requested = &amount
+		}
+		response, err = provider.Refund(order.PaymentIntentID, requested, refundReference(&order))
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+
+		refund = models.Refund{
+			OrderID:          order.ID,
+			Provider:         provider.Name(),
+			ProviderRefundID: response.RefundID,
+			Amount:           amount,
+			Currency:         order.Currency,
+			Status:           response.Status,
+			Reason:           strings.TrimSpace(req.Reason),
+			Items:            items,
+		}
+
+		number, err := s.refundNumbers.Next(tx, time.Now())
+		if err != nil {
+			return err
+		}
+		refund.Number = number.Value
+		if err := tx.Create(&refund).Error; err != nil {
+			return err
+		}
+		if !req.Restocked {
+			restocked := items
+			if full {
+				restocked = unshippedItems(&order, items)
+			}
+			if err := restock(tx, restockedItems(&order, restocked)); err != nil {
+				return err
+			}
+		}
+		note := fmt.Sprintf("Refunded %s through %s", amount, provider.Name())
+		if refund.Reason != "" {
+			note += ": " + refund.Reason
+		}
+		if err := transitionPayment(tx, &order, status, note); err != nil {
+			return err
+		}
+		return tx.Model(&order).Update("payment_status", status).Error
+	})
+	if err != nil && response != nil {
+		// The provider already refunded: the record is needed to reconcile by hand
+		return nil, fmt.Errorf("refund %s of order %s was not recorded: %w", response.RefundID, order.OrderNumber, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+// ListRefunds lists the refunds of an order, oldest first
+func (s *Service) ListRefunds(orderID uint) ([]models.Refund, error) {
+	if _, err := s.GetOrder(orderID); err != nil {
+		return nil, err
+	}
+
+	var refunds []models.Refund
+	if err := s.db.Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC, id ASC").
+		Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// refundItems checks the requested quantities against what is left to refund of each
+// order item. With all set, every quantity not refunded yet is returned instead.
+func refundItems(order *models.Order, requested []models.RefundItem, all bool) ([]models.RefundItem, error) {
+	refunded := models.RefundedQuantities(order.Refunds)
+	remaining := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.ID] = item.Quantity - refunded[item.ID]
+	}
+
+	if all {
+		var items []models.RefundItem
+		for _, item := range order.Items {
+			if remaining[item.ID] > 0 {
+				items = append(items, models.RefundItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		return items, nil
+	}
+
+	items := make([]models.RefundItem, 0, len(requested))
+	for _, item := range requested {
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d does not belong to order %s", ErrInvalidRefund, item.OrderItemID, order.OrderNumber)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of item %d must be positive", ErrInvalidRefund, item.OrderItemID)
+		}
+		if item.Quantity > left {
+			return nil, fmt.Errorf("%w: only %d of item %d left to refund", ErrInvalidRefund, left, item.OrderItemID)
+		}
+		remaining[item.OrderItemID] = left - item.Quantity
+		items = append(items, models.RefundItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	return items, nil
+}
+
+// refundValue works out what the customer paid for quantities of order items: their share
+// of the discounted subtotal, plus VAT when prices exclude it. Shipping is not included.
+func refundValue(order *models.Order, items []models.RefundItem) models.Money {
+	lines := make(map[uint]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		lines[item.ID] = item
+	}
+
+	value := models.NewMoney(0, order.Currency)
+	for _, item := range items {
+		line := lines[item.OrderItemID]
+		share := line.TotalPrice.MulRatio(int64(item.Quantity), int64(line.Quantity))
+		if order.Discount.IsPositive() && order.Subtotal.IsPositive() {
+			share = share.Sub(order.Discount.MulRatio(share.Amount, order.Subtotal.Amount))
+		}
+		if !order.PricesIncludeTax {
+			share = share.Add(line.TaxAmount.MulRatio(int64(item.Quantity), int64(line.Quantity)))
+		}
+		value = value.Add(share)
+	}
+	return value
+}
+
+// refundReference identifies the next refund of an order to the payment provider, so that
+// a refund retried after it reached the provider but was not recorded is not made twice
+func refundReference(order *models.Order) string {
+	return fmt.Sprintf("%s-%d", order.OrderNumber, len(order.Refunds)+1)
+}
+
+// unshippedItems caps refunded quantities at what is still on the shelf: the quantity of
+// each order item not shipped and not covered by an earlier refund
+func unshippedItems(order *models.Order, items []models.RefundItem) []models.RefundItem {
+	shipped := models.ShippedQuantities(order.Shipments)
+	refunded := models.RefundedQuantities(order.Refunds)
+	ordered := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		ordered[item.ID] = item.Quantity
+	}
+
+	var unshipped []models.RefundItem
+	for _, item := range items {
+		quantity := item.Quantity
+		if left := ordered[item.OrderItemID] - shipped[item.OrderItemID] - refunded[item.OrderItemID]; left < quantity {
+			quantity = left
+		}
+		if quantity > 0 {
+			unshipped = append(unshipped, models.RefundItem{OrderItemID: item.OrderItemID, Quantity: quantity})
+		}
+	}
+	return unshipped
+}
+
+// restockedItems returns the order items a refund covers, with the refunded quantities
+func restockedItems(order *models.Order, items []models.RefundItem) []models.OrderItem {
+	quantities := make(map[uint]int, len(items))
+	for _, item := range items {
+		quantities[item.OrderItemID] += item.Quantity
+	}
+
+	var restocked []models.OrderItem
+	for _, item := range order.Items {
+		if quantities[item.ID] > 0 {
+			item.Quantity = quantities[item.ID]
+			restocked = append(restocked, item)
+		}
+	}
+	return restocked
+}
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestRefundItems(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "ORD-1",
+		Items:       []models.OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}},
+		Refunds: []models.Refund{
+			{Items: []models.RefundItem{{OrderItemID: 1, Quantity: 1}}},
+		},
+	}
+
+	// A full refund covers whatever was not refunded yet
+	items, err := refundItems(order, nil, true)
+	if err != nil {
+		t.Fatalf("refundItems() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Quantity != 2 || items[1].Quantity != 1 {
+		t.Errorf("remaining items = %+v", items)
+	}
+
+	// An amount-only refund restocks nothing
+	if items, err := refundItems(order, nil, false); err != nil || len(items) != 0 {
+		t.Errorf("refundItems(no items) = %+v, %v", items, err)
+	}
+
+	invalid := [][]models.RefundItem{
+		{{OrderItemID: 1, Quantity: 3}},                                // More than left
+		{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 1, Quantity: 1}}, // Over the limit in total
+		{{OrderItemID: 9, Quantity: 1}},                                // Not in the order
+		{{OrderItemID: 2, Quantity: 0}},
+	}
+	for _, requested := range invalid {
+		if _, err := refundItems(order, requested, false); !errors.Is(err, ErrInvalidRefund) {
+			t.Errorf("refundItems(%+v) error = %v, want ErrInvalidRefund", requested, err)
+		}
+	}
+}
+
+func TestRefundValue(t *testing.T) {
+	order := &models.Order{
+		Currency: "EUR",
+		Subtotal: models.EUR(10000),
+		Discount: models.EUR(1000),
+		Items: []models.OrderItem{
+			{ID: 1, Quantity: 2, TotalPrice: models.EUR(6000), TaxAmount: models.EUR(1188)},
+			{ID: 2, Quantity: 1, TotalPrice: models.EUR(4000), TaxAmount: models.EUR(792)},
+		},
+	}
+	items := []models.RefundItem{{OrderItemID: 1, Quantity: 1}}
+
+	// Net prices: half the line, less 10% discount, plus half the VAT
+	if got := refundValue(order, items); got.Amount != 2700+594 {
+		t.Errorf("refundValue(net) = %d, want %d", got.Amount, 2700+594)
+	}
+
+	// Gross prices already include the VAT
+	order.PricesIncludeTax = true
+	if got := refundValue(order, items); got.Amount != 2700 {
+		t.Errorf("refundValue(gross) = %d, want 2700", got.Amount)
+	}
+
+	restocked := restockedItems(order, items)
+	if len(restocked) != 1 || restocked[0].ID != 1 || restocked[0].Quantity != 1 {
+		t.Errorf("restockedItems() = %+v", restocked)
+	}
+}
+
+func TestUnshippedItems(t *testing.T) {
+	order := &models.Order{
+		Items: []models.OrderItem{
+			{ID: 1, Quantity: 3},
+			{ID: 2, Quantity: 1},
+			{ID: 3, Quantity: 2},
+		},
+		Shipments: []models.Shipment{{Items: []models.ShipmentItem{
+			{OrderItemID: 1, Quantity: 1},
+			{OrderItemID: 2, Quantity: 1},
+		}}},
+		Refunds: []models.Refund{{Items: []models.RefundItem{{OrderItemID: 1, Quantity: 1}}}},
+	}
+	items := []models.RefundItem{
+		{OrderItemID: 1, Quantity: 2},
+		{OrderItemID: 2, Quantity: 1},
+		{OrderItemID: 3, Quantity: 2},
+	}
+
+	// One of item 1 shipped and one was refunded before; item 2 shipped entirely
+	got := unshippedItems(order, items)
+	want := []models.RefundItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 3, Quantity: 2}}
+	if len(got) != len(want) {
+		t.Fatalf("unshippedItems() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("unshippedItems()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
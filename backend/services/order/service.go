@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
 	"github.com/Naim0996/art-management-tool/backend/services/notification"
+	"github.com/Naim0996/art-management-tool/backend/services/numbering"
 	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/shipping"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -21,22 +26,31 @@ var (
 
 // Service handles order operations
 type Service struct {
-	db              *gorm.DB
-	paymentProvider payment.Provider
-	notifService    *notification.Service
+	db           *gorm.DB
+	payments     *payment.Registry
+	taxService   *tax.Service
+	notifService *notification.Service
+
+	orderNumbers  *numbering.Generator
+	refundNumbers *numbering.Generator
 }
 
 // NewService creates a new order service
-func NewService(db *gorm.DB, paymentProvider payment.Provider, notifService *notification.Service) *Service {
+func NewService(db *gorm.DB, payments *payment.Registry, taxService *tax.Service, notifService *notification.Service, orderNumbers, refundNumbers *numbering.Generator) *Service {
 	return &Service{
-		db:              db,
-		paymentProvider: paymentProvider,
-		notifService:    notifService,
+		db:            db,
+		payments:      payments,
+		taxService:    taxService,
+		notifService:  notifService,
+		orderNumbers:  orderNumbers,
+		refundNumbers: refundNumbers,
 	}
 }
 
-// CreateOrder creates an order from a cart with payment
-func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, discountCode *models.DiscountCode) (*models.Order, *models.PaymentIntent, error) {
+// CreateOrder creates an order from a cart with payment through the given provider.
+// The provider name is recorded on the order so that refunds and webhooks use the same provider.
+// The shipping quote is added to the total; nil means free shipping.
+func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, discountCode *models.DiscountCode, shippingQuote *shipping.Quote, provider payment.Provider) (*models.Order, *models.PaymentIntent, error) {
 	// Start transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -46,8 +60,9 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 	}()
 	
 	// Calculate totals
-	var subtotal float64
+	subtotal := models.NewMoney(0, models.DefaultCurrency)
 	var items []models.OrderItem
+	var taxLines []tax.Line
 	
 	for _, cartItem := range cart.Items {
 		if cartItem.Product == nil {
@@ -55,9 +70,8 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 			return nil, nil, fmt.Errorf("product not loaded for cart item %d", cartItem.ID)
 		}
 		
-		unitPrice := cartItem.Product.BasePrice
+		unitPrice := cartItem.UnitPrice()
 		if cartItem.Variant != nil {
-			unitPrice += cartItem.Variant.PriceAdjustment
 			
 			// Check and reserve stock
 			if cartItem.Variant.Stock < cartItem.Quantity {
@@ -65,17 +79,23 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 				return nil, nil, fmt.Errorf("%w: %s", ErrInsufficientStock, cartItem.Variant.Name)
 			}
 			
-			// Reserve stock
-			if err := tx.Model(&models.ProductVariant{}).
+			// Reserve stock. The cart's holds were checked outside this transaction, so
+			// the conditional update is what keeps two checkouts from selling the same piece.
+			result := tx.Model(&models.ProductVariant{}).
 				Where("id = ? AND stock >= ?", cartItem.Variant.ID, cartItem.Quantity).
-				Update("stock", gorm.Expr("stock - ?", cartItem.Quantity)).Error; err != nil {
+				Update("stock", gorm.Expr("stock - ?", cartItem.Quantity))
+			if result.Error != nil {
+				tx.Rollback()
+				return nil, nil, result.Error
+			}
+			if result.RowsAffected == 0 {
 				tx.Rollback()
-				return nil, nil, err
+				return nil, nil, fmt.Errorf("%w: %s", ErrInsufficientStock, cartItem.Variant.Name)
 			}
 		}
 		
-		totalPrice := unitPrice * float64(cartItem.Quantity)
-		subtotal += totalPrice
+		totalPrice := unitPrice.Mul(int64(cartItem.Quantity))
+		subtotal = subtotal.Add(totalPrice)
 		
 		variantName := ""
 		sku := cartItem.Product.SKU
@@ -93,19 +113,56 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 			Quantity:    cartItem.Quantity,
 			UnitPrice:   unitPrice,
 			TotalPrice:  totalPrice,
+			TaxClass:    cartItem.Product.TaxClass,
 		})
+		taxLines = append(taxLines, tax.Line{TaxClass: cartItem.Product.TaxClass, Amount: totalPrice})
 	}
 	
 	// Calculate discount
-	discount := 0.0
+	discount := models.NewMoney(0, subtotal.Currency)
+	var discountCodeName string
 	if discountCode != nil && discountCode.IsValid() {
 		discount = discountCode.CalculateDiscount(subtotal)
 	}
+	if discountCode != nil {
+		discountCodeName = discountCode.Code
+	}
 	
-	// Calculate tax (placeholder)
-	tax := 0.0
+	// Calculate VAT for the shipping country, on the discounted amounts
+	taxes, err := s.taxService.Calculate(req.ShippingAddress.Country, taxLines, discount)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	for i := range items {
+		items[i].TaxClass = taxes.Lines[i].TaxClass
+		items[i].TaxRate = taxes.Lines[i].Rate
+		items[i].TaxAmount = taxes.Lines[i].Tax
+	}
+	
+	// Shipping is taxed at the standard rate of the destination and is not discounted
+	shippingCost := models.NewMoney(0, subtotal.Currency)
+	shippingTax := models.NewMoney(0, subtotal.Currency)
+	var shippingTaxRate int64
+	var shippingCode, shippingName string
+	if shippingQuote != nil {
+		shippingCode = shippingQuote.Code
+		shippingName = shippingQuote.Name
+		shippingCost = shippingQuote.Price
+		
+		shippingTaxes, err := s.taxService.Calculate(taxes.Country, []tax.Line{{TaxClass: models.TaxClassStandard, Amount: shippingCost}}, models.Money{})
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+		shippingTax = shippingTaxes.Tax
+		shippingTaxRate = shippingTaxes.Lines[0].Rate
+	}
 	
-	total := subtotal + tax - discount
+	total := taxes.Total.Add(shippingCost)
+	if !taxes.PricesIncludeTax {
+		total = total.Add(shippingTax)
+	}
 	
 	// Serialize addresses
 	shippingJSON, _ := json.Marshal(req.ShippingAddress)
@@ -114,26 +171,42 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 		billingJSON, _ = json.Marshal(req.BillingAddress)
 	}
 	
-	// Generate order number
-	orderNumber := fmt.Sprintf("ORD-%d", time.Now().Unix())
+	// Taken outside the transaction so that checkouts do not wait on each other;
+	// a failed checkout leaves a gap in the order numbers
+	number, err := s.orderNumbers.Next(s.db, time.Now())
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	orderNumber := number.Value
 	
 	// Create order
 	order := models.Order{
-		OrderNumber:       orderNumber,
-		UserID:            cart.UserID,
-		CustomerEmail:     req.Email,
-		CustomerName:      req.Name,
-		Subtotal:          subtotal,
-		Tax:               tax,
-		Discount:          discount,
-		Total:             total,
-		Currency:          "EUR",
-		PaymentStatus:     models.PaymentStatusPending,
-		PaymentMethod:     string(req.PaymentMethod),
-		FulfillmentStatus: models.FulfillmentStatusUnfulfilled,
-		ShippingAddress:   string(shippingJSON),
-		BillingAddress:    string(billingJSON),
-		Items:             items,
+		OrderNumber:        orderNumber,
+		UserID:             cart.UserID,
+		CustomerEmail:      req.Email,
+		CustomerName:       req.Name,
+		CustomerTaxCode:    normalizeTaxID(req.TaxCode),
+		CustomerVATID:      normalizeTaxID(req.VATID),
+		Subtotal:           subtotal,
+		Tax:                taxes.Tax.Add(shippingTax),
+		Discount:           taxes.Discount,
+		DiscountCode:       discountCodeName,
+		ShippingCost:       shippingCost,
+		ShippingTaxRate:    shippingTaxRate,
+		Total:              total,
+		Currency:           total.Currency,
+		TaxCountry:         taxes.Country,
+		PricesIncludeTax:   taxes.PricesIncludeTax,
+		PaymentStatus:      models.PaymentStatusPending,
+		PaymentMethod:      string(req.PaymentMethod),
+		PaymentProvider:    provider.Name(),
+		ShippingMethod:     shippingCode,
+		ShippingMethodName: shippingName,
+		FulfillmentStatus:  models.FulfillmentStatusUnfulfilled,
+		ShippingAddress:    string(shippingJSON),
+		BillingAddress:     string(billingJSON),
+		Items:              items,
 	}
 	
 	if err := tx.Create(&order).Error; err != nil {
@@ -141,6 +214,23 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 		return nil, nil, err
 	}
 	
+	// The stock is taken now: the cart's holds are released with the same commit
+	if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.StockReservation{}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	
+	// Start the timeline with the initial status
+	if err := tx.Create(&models.OrderEvent{
+		OrderID:  order.ID,
+		Type:     models.OrderEventPayment,
+		ToStatus: string(order.PaymentStatus),
+		Note:     fmt.Sprintf("Order created with %s", provider.Name()),
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	
 	// Create payment intent
 	paymentItems := make([]models.PaymentItem, len(items))
 	for i, item := range items {
@@ -153,7 +243,6 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 	
 	paymentReq := &payment.CreatePaymentIntentRequest{
 		Amount:      total,
-		Currency:    "EUR",
 		CustomerRef: req.Email,
 		Items:       paymentItems,
 		Metadata: map[string]string{
@@ -163,7 +252,7 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 		Description: fmt.Sprintf("Order %s", orderNumber),
 	}
 	
-	paymentIntent, err := s.paymentProvider.CreatePaymentIntent(paymentReq)
+	paymentIntent, err := provider.CreatePaymentIntent(paymentReq)
 	if err != nil {
 		tx.Rollback()
 		
@@ -183,7 +272,7 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		// Try to cancel payment
-		s.paymentProvider.CancelPayment(paymentIntent.ID)
+		provider.CancelPayment(paymentIntent.ID)
 		return nil, nil, err
 	}
 	
@@ -198,17 +287,56 @@ func (s *Service) CreateOrder(cart *models.Cart, req *models.CheckoutRequest, di
 	return &order, paymentIntent, nil
 }
 
-// HandlePaymentSuccess handles successful payment webhook
-func (s *Service) HandlePaymentSuccess(paymentIntentID string) error {
+// findByPaymentIntent finds the order paid with a provider's payment intent.
+// Orders created before the provider was recorded have an empty payment_provider.
+func (s *Service) findByPaymentIntent(providerName, paymentIntentID string) (*models.Order, error) {
 	var order models.Order
-	err := s.db.Where("payment_intent_id = ?", paymentIntentID).First(&order).Error
+	err := s.db.Preload("Items").
+		Where("payment_intent_id = ? AND (payment_provider = ? OR payment_provider = '')", paymentIntentID, providerName).
+		First(&order).Error
+	if err != nil {
+		return nil, fmt.Errorf("order not found for %s payment intent %s: %w", providerName, paymentIntentID, err)
+	}
+	return &order, nil
+}
+
+// HandlePaymentSuccess handles successful payment webhook from the named provider.
+// It is idempotent so that redelivered or replayed events are harmless. The order is
+// claimed while still pending, so an expiry or cancellation that released its stock
+// first wins: the late payment is refunded instead of marking the order paid.
+func (s *Service) HandlePaymentSuccess(providerName, paymentIntentID string) error {
+	order, err := s.findByPaymentIntent(providerName, paymentIntentID)
 	if err != nil {
-		return fmt.Errorf("order not found for payment intent %s: %w", paymentIntentID, err)
+		return err
 	}
 	
-	order.PaymentStatus = models.PaymentStatusPaid
+	if order.PaymentStatus != models.PaymentStatusPending {
+		return s.refundLatePayment(order)
+	}
 	
-	if err := s.db.Save(&order).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND payment_status = ?", order.ID, models.PaymentStatusPending).
+			Update("payment_status", models.PaymentStatusPaid)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderSettled
+		}
+		
+		if err := transitionPayment(tx, order, models.PaymentStatusPaid, fmt.Sprintf("Payment confirmed by %s", providerName)); err != nil {
+			return err
+		}
+		return tx.Save(order).Error
+	})
+	if errors.Is(err, errOrderSettled) {
+		if err := s.db.First(order, order.ID).Error; err != nil {
+			return err
+		}
+		return s.refundLatePayment(order)
+	}
+	if err != nil {
 		return err
 	}
 	
@@ -218,39 +346,116 @@ func (s *Service) HandlePaymentSuccess(paymentIntentID string) error {
 	return nil
 }
 
-// HandlePaymentFailed handles failed payment webhook
-func (s *Service) HandlePaymentFailed(paymentIntentID string, reason string) error {
-	var order models.Order
-	err := s.db.Where("payment_intent_id = ?", paymentIntentID).First(&order).Error
+// refundLatePayment gives back a payment that succeeded after its order expired, was
+// cancelled or failed, since the order's stock was already released. The refund is
+// recorded on the order, so a redelivered event finds it and does nothing. A refund the
+// provider rejects is left to the admin rather than failing the webhook, which would
+// only be retried with the same result.
+func (s *Service) refundLatePayment(order *models.Order) error {
+	switch order.PaymentStatus {
+	case models.PaymentStatusExpired, models.PaymentStatusCancelled, models.PaymentStatusFailed:
+	default:
+		// Paid or refunded already
+		return nil
+	}
+	
+	var refunds int64
+	if err := s.db.Model(&models.Refund{}).Where("order_id = ?", order.ID).Count(&refunds).Error; err != nil {
+		return err
+	}
+	if refunds > 0 {
+		return nil
+	}
+	
+	provider, err := s.payments.ByName(order.PaymentProvider)
 	if err != nil {
-		return fmt.Errorf("order not found for payment intent %s: %w", paymentIntentID, err)
+		return err
 	}
 	
-	// Start transaction to release stock
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
+	response, refundErr := provider.Refund(order.PaymentIntentID, nil, "late-"+order.OrderNumber)
+	note := fmt.Sprintf("Payment received by %s after the order was %s", provider.Name(), order.PaymentStatus)
+	if refundErr != nil {
+		note += fmt.Sprintf("; refund failed: %v", refundErr)
+	} else {
+		note += fmt.Sprintf("; refunded %s", order.Total)
+	}
 	
-	// Release reserved stock
-	for _, item := range order.Items {
-		if item.VariantID != nil {
-			tx.Model(&models.ProductVariant{}).
-				Where("id = ?", *item.VariantID).
-				Update("stock", gorm.Expr("stock + ?", item.Quantity))
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if refundErr == nil {
+			number, err := s.refundNumbers.Next(tx, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&models.Refund{
+				OrderID:          order.ID,
+				Number:           number.Value,
+				Provider:         provider.Name(),
+				ProviderRefundID: response.RefundID,
+				Amount:           order.Total,
+				Currency:         order.Currency,
+				Status:           response.Status,
+				Reason:           "Payment received after the order was " + string(order.PaymentStatus),
+			}).Error; err != nil {
+				return err
+			}
 		}
+		return tx.Create(&models.OrderEvent{
+			OrderID:    order.ID,
+			Type:       models.OrderEventPayment,
+			FromStatus: string(order.PaymentStatus),
+			ToStatus:   string(order.PaymentStatus),
+			Note:       note,
+		}).Error
+	})
+	if err != nil {
+		return err
 	}
 	
-	order.PaymentStatus = models.PaymentStatusFailed
-	
-	if err := tx.Save(&order).Error; err != nil {
-		tx.Rollback()
+	s.notifService.CreateLatePaymentNotification(order.OrderNumber, order.Total, order.PaymentStatus, refundErr)
+	return nil
+}
+
+// HandlePaymentFailed handles failed payment webhook from the named provider.
+// Only pending orders are marked failed, and the order is claimed before its stock and
+// discount code use are released, so a cancellation or expiry running meanwhile never
+// releases them twice.
+func (s *Service) HandlePaymentFailed(providerName, paymentIntentID string, reason string) error {
+	order, err := s.findByPaymentIntent(providerName, paymentIntentID)
+	if err != nil {
 		return err
 	}
 	
-	if err := tx.Commit().Error; err != nil {
+	if order.PaymentStatus != models.PaymentStatusPending {
+		return nil
+	}
+	
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND payment_status = ?", order.ID, models.PaymentStatusPending).
+			Update("payment_status", models.PaymentStatusFailed)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderSettled
+		}
+		
+		// Release reserved stock
+		if err := restock(tx, order.Items); err != nil {
+			return err
+		}
+		if err := transitionPayment(tx, order, models.PaymentStatusFailed, reason); err != nil {
+			return err
+		}
+		if err := tx.Save(order).Error; err != nil {
+			return err
+		}
+		return releaseDiscount(tx, order.DiscountCode)
+	})
+	if errors.Is(err, errOrderSettled) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 	
@@ -263,7 +468,13 @@ func (s *Service) HandlePaymentFailed(paymentIntentID string, reason string) err
 // GetOrder gets an order by ID
 func (s *Service) GetOrder(id uint) (*models.Order, error) {
 	var order models.Order
-	err := s.db.Preload("Items").First(&order, id).Error
+	err := s.db.Preload("Items").
+		Preload("Shipments.Items").
+		Preload("Refunds.Items").
+		Preload("Events", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		First(&order, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrOrderNotFound
@@ -323,68 +534,82 @@ func (s *Service) ListOrders(filters *OrderFilters) ([]models.Order, int64, erro
 	return orders, total, nil
 }
 
-// UpdateFulfillmentStatus updates the fulfillment status
+// UpdateFulfillmentStatus sets the fulfillment status of an order by hand. The status
+// must match what the order's shipments say, so it only repairs an order whose status
+// got out of step with them; shipping goes through CreateShipment and cancelling through
+// CancelOrder, which also deal with the payment, stock and discount.
 func (s *Service) UpdateFulfillmentStatus(id uint, status models.FulfillmentStatus) error {
-	var order models.Order
-	if err := s.db.First(&order, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrOrderNotFound
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&order, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
 		}
-		return err
-	}
-	
-	order.FulfillmentStatus = status
-	return s.db.Save(&order).Error
+		if err := tx.Preload("Items").Preload("Shipments.Items").First(&order, id).Error; err != nil {
+			return err
+		}
+		
+		if err := CanTransitionFulfillment(order.FulfillmentStatus, status); err != nil {
+			return err
+		}
+		if status == models.FulfillmentStatusCancelled {
+			return fmt.Errorf("%w: order %s must be cancelled, not set to cancelled", ErrInvalidTransition, order.OrderNumber)
+		}
+		if shipped := models.FulfillmentStatusFor(order.Items, order.Shipments); status != shipped {
+			return fmt.Errorf("%w: the shipments of order %s make it %s", ErrInvalidTransition, order.OrderNumber, shipped)
+		}
+		
+		if err := transitionFulfillment(tx, &order, status, "Status set manually"); err != nil {
+			return err
+		}
+		return tx.Model(&order).Update("fulfillment_status", status).Error
+	})
 }
 
-// RefundOrder refunds an order
-func (s *Service) RefundOrder(id uint, amount *float64) error {
+// UpdatePaymentStatus marks a pending order as paid by hand, e.g. for a bank transfer.
+// Every other payment status has its own path, which also voids or refunds the payment
+// and gives back the stock and discount: CancelOrder, RefundOrder, the payment webhooks
+// and the expiry job.
+func (s *Service) UpdatePaymentStatus(id uint, status models.PaymentStatus) error {
 	var order models.Order
-	if err := s.db.Preload("Items").First(&order, id).Error; err != nil {
+	if err := s.db.First(&order, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrOrderNotFound
 		}
 		return err
 	}
 	
-	if order.PaymentStatus != models.PaymentStatusPaid {
-		return fmt.Errorf("cannot refund order that is not paid")
+	if err := CanTransitionPayment(order.PaymentStatus, status); err != nil {
+		return err
 	}
-	
-	// Process refund through payment provider
-	_, err := s.paymentProvider.Refund(order.PaymentIntentID, amount)
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	if status != models.PaymentStatusPaid {
+		return &TransitionError{Type: models.OrderEventPayment, From: string(order.PaymentStatus), To: string(status)}
 	}
 	
-	// Start transaction to restore stock
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the order so a webhook, cancellation or expiry running meanwhile wins
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND payment_status = ?", order.ID, models.PaymentStatusPending).
+			Update("payment_status", models.PaymentStatusPaid)
+		if result.Error != nil {
+			return result.Error
 		}
-	}()
-	
-	// Restore stock
-	for _, item := range order.Items {
-		if item.VariantID != nil {
-			tx.Model(&models.ProductVariant{}).
-				Where("id = ?", *item.VariantID).
-				Update("stock", gorm.Expr("stock + ?", item.Quantity))
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: order %s changed while being marked paid", ErrInvalidTransition, order.OrderNumber)
 		}
-	}
-	
-	order.PaymentStatus = models.PaymentStatusRefunded
-	
-	if err := tx.Save(&order).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-	
-	if err := tx.Commit().Error; err != nil {
+		
+		if err := transitionPayment(tx, &order, models.PaymentStatusPaid, "Status set manually"); err != nil {
+			return err
+		}
+		return tx.Save(&order).Error
+	})
+	if err != nil {
 		return err
 	}
 	
+	s.notifService.CreateOrderPaidNotification(order.OrderNumber, order.Total)
 	return nil
 }
 
@@ -407,12 +632,7 @@ func DefaultFilters() *OrderFilters {
 	}
 }
 
-// GetPaymentProvider returns the current payment provider
-func (s *Service) GetPaymentProvider() payment.Provider {
-	return s.paymentProvider
-}
-
-// SetPaymentProvider sets the payment provider (useful for dynamic provider selection)
-func (s *Service) SetPaymentProvider(provider payment.Provider) {
-	s.paymentProvider = provider
+// normalizeTaxID removes spaces, dots and dashes from a tax code or VAT number and upper-cases it
+func normalizeTaxID(id string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", ".", "", "-", "").Replace(id))
 }
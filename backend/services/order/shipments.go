@@ -0,0 +1,185 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShipmentNotFound = errors.New("shipment not found")
+	ErrInvalidShipment  = errors.New("invalid shipment")
+)
+
+// ListShipments lists the shipments of an order, oldest first
+func (s *Service) ListShipments(orderID uint) ([]models.Shipment, error) {
+	if _, err := s.GetOrder(orderID); err != nil {
+		return nil, err
+	}
+
+	var shipments []models.Shipment
+	if err := s.db.Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("shipped_at ASC, id ASC").
+		Find(&shipments).Error; err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// GetShipment gets a shipment of an order
+func (s *Service) GetShipment(orderID, shipmentID uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	if err := s.db.Preload("Items").
+		Where("order_id = ?", orderID).
+		First(&shipment, shipmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShipmentNotFound
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// CreateShipment records a shipment for a paid order and updates its fulfillment status.
+// A shipment without items covers everything not shipped or refunded yet.
+func (s *Service) CreateShipment(orderID uint, shipment *models.Shipment) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Preload("Items").Preload("Shipments.Items").Preload("Refunds.Items").First(&order, orderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+
+		if order.PaymentStatus != models.PaymentStatusPaid && order.PaymentStatus != models.PaymentStatusPartiallyRefunded {
+			return fmt.Errorf("%w: order %s is not paid", ErrInvalidShipment, order.OrderNumber)
+		}
+
+		var returns []models.ReturnRequest
+		if err := tx.Preload("Items").
+			Where("order_id = ? AND status = ?", order.ID, models.ReturnStatusRefunded).
+			Find(&returns).Error; err != nil {
+			return err
+		}
+
+		items, err := shipmentItems(&order, returns, shipment.Items)
+		if err != nil {
+			return err
+		}
+
+		shipment.ID = 0
+		shipment.OrderID = order.ID
+		shipment.Items = items
+		normalizeShipment(shipment)
+
+		if err := tx.Create(shipment).Error; err != nil {
+			return err
+		}
+
+		order.Shipments = append(order.Shipments, *shipment)
+		return updateFulfillment(tx, &order, fmt.Sprintf("Shipment %d created", shipment.ID))
+	})
+}
+
+// UpdateShipment saves the carrier and tracking details of a shipment; its items cannot change
+func (s *Service) UpdateShipment(shipment *models.Shipment) error {
+	normalizeShipment(shipment)
+	return s.db.Omit("Items").Save(shipment).Error
+}
+
+// DeleteShipment deletes a shipment and updates the order's fulfillment status
+func (s *Service) DeleteShipment(orderID, shipmentID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("shipment_id = ?", shipmentID).Delete(&models.ShipmentItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("order_id = ?", orderID).Delete(&models.Shipment{}, shipmentID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrShipmentNotFound
+		}
+
+		var order models.Order
+		if err := tx.Preload("Items").Preload("Shipments.Items").First(&order, orderID).Error; err != nil {
+			return err
+		}
+		return updateFulfillment(tx, &order, fmt.Sprintf("Shipment %d deleted", shipmentID))
+	})
+}
+
+// shipmentItems checks the requested quantities against what is left to ship of each
+// order item: neither shipped nor refunded. The refunded returns of the order tell which
+// refunded quantities came back after shipping, so they are not counted twice. No
+// requested items means all remaining quantities.
+func shipmentItems(order *models.Order, returns []models.ReturnRequest, requested []models.ShipmentItem) ([]models.ShipmentItem, error) {
+	shipped := models.ShippedQuantities(order.Shipments)
+	refunded := models.RefundedQuantities(order.Refunds)
+	for _, ret := range returns {
+		for _, item := range ret.Items {
+			refunded[item.OrderItemID] -= item.Quantity
+		}
+	}
+	remaining := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.ID] = item.Quantity - shipped[item.ID] - max(refunded[item.ID], 0)
+	}
+
+	if len(requested) == 0 {
+		for _, item := range order.Items {
+			if remaining[item.ID] > 0 {
+				requested = append(requested, models.ShipmentItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, fmt.Errorf("%w: all items have already been shipped or refunded", ErrInvalidShipment)
+		}
+		return requested, nil
+	}
+
+	items := make([]models.ShipmentItem, 0, len(requested))
+	for _, item := range requested {
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d does not belong to order %s", ErrInvalidShipment, item.OrderItemID, order.OrderNumber)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of item %d must be positive", ErrInvalidShipment, item.OrderItemID)
+		}
+		if item.Quantity > left {
+			return nil, fmt.Errorf("%w: only %d of item %d left to ship", ErrInvalidShipment, max(left, 0), item.OrderItemID)
+		}
+		remaining[item.OrderItemID] = left - item.Quantity
+		items = append(items, models.ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	return items, nil
+}
+
+// normalizeShipment trims the tracking details and defaults the ship date to now
+func normalizeShipment(shipment *models.Shipment) {
+	shipment.Carrier = strings.TrimSpace(shipment.Carrier)
+	shipment.TrackingNumber = strings.TrimSpace(shipment.TrackingNumber)
+	shipment.TrackingURL = strings.TrimSpace(shipment.TrackingURL)
+	if shipment.ShippedAt.IsZero() {
+		shipment.ShippedAt = time.Now()
+	}
+}
+
+// updateFulfillment stores the fulfillment status worked out from the order's shipments
+func updateFulfillment(tx *gorm.DB, order *models.Order, note string) error {
+	status := models.FulfillmentStatusFor(order.Items, order.Shipments)
+	if status == order.FulfillmentStatus {
+		return nil
+	}
+	if err := transitionFulfillment(tx, order, status, note); err != nil {
+		return err
+	}
+	return tx.Model(order).Update("fulfillment_status", status).Error
+}
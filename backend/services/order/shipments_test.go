@@ -0,0 +1,81 @@
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestShipmentItems(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "ORD-1",
+		Items:       []models.OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}},
+		Shipments: []models.Shipment{
+			{Items: []models.ShipmentItem{{OrderItemID: 1, Quantity: 1}}},
+		},
+	}
+
+	// No items ships whatever is left
+	items, err := shipmentItems(order, nil, nil)
+	if err != nil {
+		t.Fatalf("shipmentItems() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Quantity != 2 || items[1].Quantity != 1 {
+		t.Errorf("remaining items = %+v", items)
+	}
+
+	invalid := [][]models.ShipmentItem{
+		{{OrderItemID: 1, Quantity: 3}},                                // More than left
+		{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 1, Quantity: 1}}, // Over the limit in total
+		{{OrderItemID: 9, Quantity: 1}},                                // Not in the order
+		{{OrderItemID: 2, Quantity: 0}},
+	}
+	for _, requested := range invalid {
+		if _, err := shipmentItems(order, nil, requested); !errors.Is(err, ErrInvalidShipment) {
+			t.Errorf("shipmentItems(%+v) error = %v, want ErrInvalidShipment", requested, err)
+		}
+	}
+
+	order.Shipments = append(order.Shipments, models.Shipment{Items: items})
+	if _, err := shipmentItems(order, nil, nil); !errors.Is(err, ErrInvalidShipment) {
+		t.Errorf("fully shipped order: error = %v", err)
+	}
+}
+
+func TestShipmentItemsAfterRefunds(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "ORD-1",
+		Items:       []models.OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 2}},
+		Shipments: []models.Shipment{
+			{Items: []models.ShipmentItem{{OrderItemID: 2, Quantity: 1}}},
+		},
+		Refunds: []models.Refund{
+			{Items: []models.RefundItem{{OrderItemID: 1, Quantity: 1}}}, // Refunded before shipping
+			{Items: []models.RefundItem{{OrderItemID: 2, Quantity: 1}}}, // The shipped piece, returned
+		},
+	}
+	returns := []models.ReturnRequest{
+		{Status: models.ReturnStatusRefunded, Items: []models.ReturnItem{{OrderItemID: 2, Quantity: 1}}},
+	}
+
+	items, err := shipmentItems(order, returns, nil)
+	if err != nil {
+		t.Fatalf("shipmentItems() error = %v", err)
+	}
+	if len(items) != 2 || items[0].Quantity != 2 || items[1].Quantity != 1 {
+		t.Errorf("remaining items = %+v, want 2 of item 1 and the unshipped 1 of item 2", items)
+	}
+	if _, err := shipmentItems(order, returns, []models.ShipmentItem{{OrderItemID: 1, Quantity: 3}}); !errors.Is(err, ErrInvalidShipment) {
+		t.Errorf("shipping a refunded piece: error = %v, want ErrInvalidShipment", err)
+	}
+
+	// Without the return, the refund of item 2 is taken as one of the unshipped piece
+	items, err = shipmentItems(order, nil, nil)
+	if err != nil {
+		t.Fatalf("shipmentItems() error = %v", err)
+	}
+	if len(items) != 1 || items[0].OrderItemID != 1 {
+		t.Errorf("remaining items = %+v, want only item 1", items)
+	}
+}
@@ -0,0 +1,136 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrUnknownStatus     = errors.New("unknown order status")
+)
+
+// TransitionError reports a status change the state machine does not allow
+type TransitionError struct {
+	Type models.OrderEventType
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot change %s status from %s to %s", e.Type, e.From, e.To)
+}
+
+// Unwrap lets errors.Is match ErrInvalidTransition
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// paymentTransitions lists the payment statuses reachable from each status.
+// Failed, refunded, cancelled and expired are final. Partially refunded orders stay so
+// on each further partial refund, which keeps every refund in the timeline.
+var paymentTransitions = map[models.PaymentStatus][]models.PaymentStatus{
+	models.PaymentStatusPending: {
+		models.PaymentStatusPaid,
+		models.PaymentStatusFailed,
+		models.PaymentStatusCancelled,
+		models.PaymentStatusExpired,
+	},
+	models.PaymentStatusPaid: {
+		models.PaymentStatusRefunded,
+		models.PaymentStatusPartiallyRefunded,
+		models.PaymentStatusCancelled,
+	},
+	models.PaymentStatusPartiallyRefunded: {
+		models.PaymentStatusPartiallyRefunded,
+		models.PaymentStatusRefunded,
+	},
+	models.PaymentStatusFailed:    {},
+	models.PaymentStatusRefunded:  {},
+	models.PaymentStatusCancelled: {},
+	models.PaymentStatusExpired:   {},
+}
+
+// fulfillmentTransitions lists the fulfillment statuses reachable from each status.
+// Moving back is allowed so that a shipment recorded by mistake can be deleted;
+// orders can only be cancelled before anything ships, and only by CancelOrder or the
+// expiry job, never by setting the status by hand.
+var fulfillmentTransitions = map[models.FulfillmentStatus][]models.FulfillmentStatus{
+	models.FulfillmentStatusUnfulfilled: {
+		models.FulfillmentStatusPartiallyFulfilled,
+		models.FulfillmentStatusFulfilled,
+		models.FulfillmentStatusCancelled,
+	},
+	models.FulfillmentStatusPartiallyFulfilled: {
+		models.FulfillmentStatusFulfilled,
+		models.FulfillmentStatusUnfulfilled,
+	},
+	models.FulfillmentStatusFulfilled: {
+		models.FulfillmentStatusPartiallyFulfilled,
+		models.FulfillmentStatusUnfulfilled,
+	},
+	models.FulfillmentStatusCancelled: {},
+}
+
+// CanTransitionPayment checks that an order may move between two payment statuses
+func CanTransitionPayment(from, to models.PaymentStatus) error {
+	if _, ok := paymentTransitions[to]; !ok {
+		return fmt.Errorf("%w: payment status %q", ErrUnknownStatus, to)
+	}
+	for _, next := range paymentTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &TransitionError{Type: models.OrderEventPayment, From: string(from), To: string(to)}
+}
+
+// CanTransitionFulfillment checks that an order may move between two fulfillment statuses
+func CanTransitionFulfillment(from, to models.FulfillmentStatus) error {
+	if _, ok := fulfillmentTransitions[to]; !ok {
+		return fmt.Errorf("%w: fulfillment status %q", ErrUnknownStatus, to)
+	}
+	for _, next := range fulfillmentTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &TransitionError{Type: models.OrderEventFulfillment, From: string(from), To: string(to)}
+}
+
+// transitionPayment moves the order to a payment status and records the event.
+// The caller saves the order in the same transaction.
+func transitionPayment(tx *gorm.DB, order *models.Order, to models.PaymentStatus, note string) error {
+	if err := CanTransitionPayment(order.PaymentStatus, to); err != nil {
+		return err
+	}
+	event := models.OrderEvent{
+		OrderID:    order.ID,
+		Type:       models.OrderEventPayment,
+		FromStatus: string(order.PaymentStatus),
+		ToStatus:   string(to),
+		Note:       note,
+	}
+	order.PaymentStatus = to
+	return tx.Create(&event).Error
+}
+
+// transitionFulfillment moves the order to a fulfillment status and records the event.
+// The caller saves the order in the same transaction.
+func transitionFulfillment(tx *gorm.DB, order *models.Order, to models.FulfillmentStatus, note string) error {
+	if err := CanTransitionFulfillment(order.FulfillmentStatus, to); err != nil {
+		return err
+	}
+	event := models.OrderEvent{
+		OrderID:    order.ID,
+		Type:       models.OrderEventFulfillment,
+		FromStatus: string(order.FulfillmentStatus),
+		ToStatus:   string(to),
+		Note:       note,
+	}
+	order.FulfillmentStatus = to
+	return tx.Create(&event).Error
+}
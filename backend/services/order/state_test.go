@@ -0,0 +1,63 @@
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestCanTransitionPayment(t *testing.T) {
+	tests := []struct {
+		from, to models.PaymentStatus
+		wantErr  error
+	}{
+		{models.PaymentStatusPending, models.PaymentStatusPaid, nil},
+		{models.PaymentStatusPending, models.PaymentStatusCancelled, nil},
+		{models.PaymentStatusPending, models.PaymentStatusExpired, nil},
+		{models.PaymentStatusPaid, models.PaymentStatusRefunded, nil},
+		{models.PaymentStatusExpired, models.PaymentStatusPaid, ErrInvalidTransition},
+		{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded, nil},
+		{models.PaymentStatusPartiallyRefunded, models.PaymentStatusPartiallyRefunded, nil},
+		{models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded, nil},
+		{models.PaymentStatusPartiallyRefunded, models.PaymentStatusCancelled, ErrInvalidTransition},
+		{models.PaymentStatusPending, models.PaymentStatusRefunded, ErrInvalidTransition},
+		{models.PaymentStatusRefunded, models.PaymentStatusPaid, ErrInvalidTransition},
+		{models.PaymentStatusFailed, models.PaymentStatusPaid, ErrInvalidTransition},
+		{models.PaymentStatusPending, "completed", ErrUnknownStatus},
+	}
+
+	for _, tt := range tests {
+		err := CanTransitionPayment(tt.from, tt.to)
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s -> %s: error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+
+	var transitionErr *TransitionError
+	if err := CanTransitionPayment(models.PaymentStatusPaid, models.PaymentStatusPending); !errors.As(err, &transitionErr) || transitionErr.To != "pending" {
+		t.Errorf("expected a TransitionError, got %v", err)
+	}
+}
+
+func TestCanTransitionFulfillment(t *testing.T) {
+	tests := []struct {
+		from, to models.FulfillmentStatus
+		wantErr  error
+	}{
+		{models.FulfillmentStatusUnfulfilled, models.FulfillmentStatusPartiallyFulfilled, nil},
+		{models.FulfillmentStatusPartiallyFulfilled, models.FulfillmentStatusFulfilled, nil},
+		{models.FulfillmentStatusFulfilled, models.FulfillmentStatusPartiallyFulfilled, nil},
+		{models.FulfillmentStatusUnfulfilled, models.FulfillmentStatusCancelled, nil},
+		{models.FulfillmentStatusFulfilled, models.FulfillmentStatusCancelled, ErrInvalidTransition},
+		{models.FulfillmentStatusCancelled, models.FulfillmentStatusFulfilled, ErrInvalidTransition},
+		{models.FulfillmentStatusUnfulfilled, "shipped", ErrUnknownStatus},
+	}
+
+	for _, tt := range tests {
+		err := CanTransitionFulfillment(tt.from, tt.to)
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s -> %s: error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestUpdatePaymentStatusOnlyMarksPaid(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+
+	for _, status := range []models.PaymentStatus{models.PaymentStatusFailed, models.PaymentStatusExpired, models.PaymentStatusCancelled, models.PaymentStatusRefunded} {
+		if err := s.UpdatePaymentStatus(order.ID, status); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("UpdatePaymentStatus(%s) error = %v, want ErrInvalidTransition", status, err)
+		}
+	}
+	if err := s.UpdatePaymentStatus(order.ID, models.PaymentStatusPaid); err != nil {
+		t.Fatalf("UpdatePaymentStatus(paid) error = %v", err)
+	}
+
+	var got models.Order
+	db.First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusPaid {
+		t.Errorf("payment status = %s, want paid", got.PaymentStatus)
+	}
+}
+
+func TestUpdateFulfillmentStatusFollowsShipments(t *testing.T) {
+	db := testdb.Open(t)
+	s, provider := newTestService(t, db)
+	variant := testdb.CreateVariant(t, db, "PRINT-A3", 1)
+	order := createStalePendingOrder(t, db, provider, variant, "ORD-1")
+	if err := s.HandlePaymentSuccess("mock", order.PaymentIntentID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither cancelling by hand nor claiming a shipment that was not recorded
+	for _, status := range []models.FulfillmentStatus{models.FulfillmentStatusCancelled, models.FulfillmentStatusFulfilled} {
+		if err := s.UpdateFulfillmentStatus(order.ID, status); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("UpdateFulfillmentStatus(%s) error = %v, want ErrInvalidTransition", status, err)
+		}
+	}
+
+	// A status out of step with the shipments is put back
+	if err := s.CreateShipment(order.ID, &models.Shipment{Carrier: "UPS"}); err != nil {
+		t.Fatalf("CreateShipment() error = %v", err)
+	}
+	db.Model(&models.Order{}).Where("id = ?", order.ID).Update("fulfillment_status", models.FulfillmentStatusUnfulfilled)
+	if err := s.UpdateFulfillmentStatus(order.ID, models.FulfillmentStatusFulfilled); err != nil {
+		t.Fatalf("UpdateFulfillmentStatus(fulfilled) error = %v", err)
+	}
+
+	var got models.Order
+	db.First(&got, order.ID)
+	if got.PaymentStatus != models.PaymentStatusPaid || got.FulfillmentStatus != models.FulfillmentStatusFulfilled {
+		t.Errorf("statuses = %s/%s, want paid/fulfilled", got.PaymentStatus, got.FulfillmentStatus)
+	}
+}
@@ -53,7 +53,6 @@ func (e *EtsyProvider) CreatePaymentIntent(request *CreatePaymentIntentRequest)
 	return &models.PaymentIntent{
 		ID:           intentID,
 		Amount:       request.Amount,
-		Currency:     request.Currency,
 		CustomerRef:  request.CustomerRef,
 		Items:        request.Items,
 		Metadata:     request.Metadata,
@@ -83,7 +82,7 @@ func (e *EtsyProvider) CancelPayment(paymentIntentID string) error {
 
 // Refund processes a refund through Etsy
 // For Etsy, refunds must be processed through Etsy's seller dashboard
-func (e *EtsyProvider) Refund(transactionID string, amount *float64) (*RefundResponse, error) {
+func (e *EtsyProvider) Refund(transactionID string, amount *models.Money, reference string) (*RefundResponse, error) {
 	// Note: Etsy refunds must be processed through the Etsy seller interface
 	// This returns an error indicating manual processing is required
 	
@@ -95,10 +94,10 @@ func (e *EtsyProvider) GetPaymentIntent(paymentIntentID string) (*models.Payment
 	// In production, this would query Etsy's Receipt API
 	// to get the current status of the transaction
 	
+	// The status stays empty: Etsy orders are paid on Etsy and never expire here
 	return &models.PaymentIntent{
-		ID:       paymentIntentID,
-		Amount:   0,
-		Currency: "USD",
+		ID:     paymentIntentID,
+		Amount: models.NewMoney(0, "USD"),
 	}, nil
 }
 
@@ -45,7 +45,7 @@ func (m *MockProvider) CreatePaymentIntent(request *CreatePaymentIntentRequest)
 	intent := &models.PaymentIntent{
 		ID:           intentID,
 		Amount:       request.Amount,
-		Currency:     request.Currency,
+		Status:       models.PaymentIntentStatusPending,
 		CustomerRef:  request.CustomerRef,
 		Items:        request.Items,
 		Metadata:     request.Metadata,
@@ -61,10 +61,10 @@ func (m *MockProvider) CreatePaymentIntent(request *CreatePaymentIntentRequest)
 
 // ConfirmPayment confirms a mock payment
 func (m *MockProvider) ConfirmPayment(paymentIntentID string) error {
-	m.mu.RLock()
-	_, exists := m.intents[paymentIntentID]
-	m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	
+	intent, exists := m.intents[paymentIntentID]
 	if !exists {
 		return ErrInvalidIntentID
 	}
@@ -73,6 +73,7 @@ func (m *MockProvider) ConfirmPayment(paymentIntentID string) error {
 		return fmt.Errorf("%w: %s", ErrPaymentFailed, m.failureMessage)
 	}
 	
+	intent.Status = models.PaymentIntentStatusSucceeded
 	return nil
 }
 
@@ -90,22 +91,27 @@ func (m *MockProvider) CancelPayment(paymentIntentID string) error {
 }
 
 // Refund processes a mock refund
-func (m *MockProvider) Refund(transactionID string, amount *float64) (*RefundResponse, error) {
+func (m *MockProvider) Refund(transactionID string, amount *models.Money, reference string) (*RefundResponse, error) {
 	if m.shouldFail {
 		return nil, fmt.Errorf("%w: %s", ErrRefundFailed, m.failureMessage)
 	}
 	
 	refundID := fmt.Sprintf("mock_refund_%s", uuid.New().String())
 	
-	refundAmount := 0.0
+	refundAmount := models.NewMoney(0, models.DefaultCurrency)
 	if amount != nil {
 		refundAmount = *amount
+	} else {
+		m.mu.RLock()
+		if intent, exists := m.intents[transactionID]; exists {
+			refundAmount = intent.Amount
+		}
+		m.mu.RUnlock()
 	}
 	
 	return &RefundResponse{
 		RefundID:      refundID,
 		Amount:        refundAmount,
-		Currency:      "EUR",
 		Status:        "succeeded",
 		TransactionID: transactionID,
 	}, nil
@@ -0,0 +1,451 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+// PayPalProvider is a PayPal payment provider backed by the PayPal Orders v2 API.
+// A payment intent is a PayPal order: the buyer approves it on PayPal and
+// ConfirmPayment captures it.
+type PayPalProvider struct {
+	clientID     string
+	clientSecret string
+	webhookID    string
+	baseURL      string
+	httpClient   *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// PayPalConfig holds configuration for the PayPal provider
+type PayPalConfig struct {
+	ClientID     string
+	ClientSecret string
+	WebhookID    string
+	BaseURL      string
+	Timeout      time.Duration
+}
+
+// NewPayPalProvider creates a new PayPal payment provider
+func NewPayPalProvider(config PayPalConfig) (*PayPalProvider, error) {
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, errors.New("paypal client ID and secret are required")
+	}
+
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api-m.sandbox.paypal.com"
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	return &PayPalProvider{
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		webhookID:    config.WebhookID,
+		baseURL:      strings.TrimRight(config.BaseURL, "/"),
+		httpClient:   &http.Client{Timeout: config.Timeout},
+	}, nil
+}
+
+// paypalAmount is a PayPal money object
+type paypalAmount struct {
+	CurrencyCode string `json:"currency_code"`
+	Value        string `json:"value"`
+}
+
+// paypalLink is a HATEOAS link returned by PayPal
+type paypalLink struct {
+	Href string `json:"href"`
+	Rel  string `json:"rel"`
+}
+
+// paypalCapture is a capture inside a PayPal order
+type paypalCapture struct {
+	ID     string       `json:"id"`
+	Status string       `json:"status"`
+	Amount paypalAmount `json:"amount"`
+}
+
+// paypalOrder is the subset of the PayPal order object we use
+type paypalOrder struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	PurchaseUnits []struct {
+		ReferenceID string       `json:"reference_id"`
+		CustomID    string       `json:"custom_id"`
+		Amount      paypalAmount `json:"amount"`
+		Payments    struct {
+			Captures []paypalCapture `json:"captures"`
+		} `json:"payments"`
+	} `json:"purchase_units"`
+	Links []paypalLink `json:"links"`
+}
+
+// paypalRefund is the subset of the PayPal refund object we use
+type paypalRefund struct {
+	ID     string       `json:"id"`
+	Status string       `json:"status"`
+	Amount paypalAmount `json:"amount"`
+}
+
+// CreatePaymentIntent creates a PayPal order; the approval URL is returned as ClientSecret
+func (p *PayPalProvider) CreatePaymentIntent(request *CreatePaymentIntentRequest) (*models.PaymentIntent, error) {
+	if err := ValidateAmount(p, request.Amount); err != nil {
+		return nil, err
+	}
+
+	amount := request.Amount
+	if amount.Currency == "" {
+		amount = amount.WithCurrency(models.DefaultCurrency)
+	}
+
+	purchaseUnit := map[string]interface{}{
+		"amount": paypalAmount{
+			CurrencyCode: amount.Currency,
+			Value:        formatPayPalAmount(amount),
+		},
+		"description": request.Description,
+	}
+	if ref := request.Metadata["order_number"]; ref != "" {
+		purchaseUnit["reference_id"] = ref
+	}
+	if id := request.Metadata["order_id"]; id != "" {
+		purchaseUnit["custom_id"] = id
+	}
+
+	body := map[string]interface{}{
+		"intent":         "CAPTURE",
+		"purchase_units": []interface{}{purchaseUnit},
+	}
+
+	var order paypalOrder
+	if err := p.do(http.MethodPost, "/v2/checkout/orders", body, idempotencyKey("order", request.Metadata["order_number"]), &order); err != nil {
+		return nil, err
+	}
+
+	return &models.PaymentIntent{
+		ID:           order.ID,
+		Amount:       amount,
+		CustomerRef:  request.CustomerRef,
+		Items:        request.Items,
+		Metadata:     request.Metadata,
+		ClientSecret: order.approveURL(), // The approval URL serves as the "secret"
+	}, nil
+}
+
+// ConfirmPayment captures an approved PayPal order
+func (p *PayPalProvider) ConfirmPayment(paymentIntentID string) error {
+	if paymentIntentID == "" {
+		return ErrInvalidIntentID
+	}
+
+	// Using the order ID as request ID makes repeated captures of the same order idempotent
+	return p.do(http.MethodPost, "/v2/checkout/orders/"+url.PathEscape(paymentIntentID)+"/capture",
+		map[string]interface{}{}, "capture-"+paymentIntentID, nil)
+}
+
+// CancelPayment cancels a payment intent
+// PayPal has no cancel endpoint for orders: unapproved orders simply expire
+func (p *PayPalProvider) CancelPayment(paymentIntentID string) error {
+	if paymentIntentID == "" {
+		return ErrInvalidIntentID
+	}
+	return nil
+}
+
+// Refund refunds the capture of a PayPal order, fully or partially when amount is set
+func (p *PayPalProvider) Refund(transactionID string, amount *models.Money, reference string) (*RefundResponse, error) {
+	order, err := p.getOrder(transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	capture := order.completedCapture()
+	if capture == nil {
+		return nil, fmt.Errorf("%w: order %s has no completed capture", ErrRefundFailed, transactionID)
+	}
+
+	body := map[string]interface{}{}
+	if amount != nil {
+		if !amount.IsPositive() {
+			return nil, ErrInvalidAmount
+		}
+		body["amount"] = paypalAmount{
+			CurrencyCode: capture.Amount.CurrencyCode,
+			Value:        formatPayPalAmount(amount.WithCurrency(capture.Amount.CurrencyCode)),
+		}
+	}
+
+	var refund paypalRefund
+	if err := p.do(http.MethodPost, "/v2/payments/captures/"+url.PathEscape(capture.ID)+"/refund", body, idempotencyKey("refund", reference), &refund); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+	}
+
+	refundAmount, _ := models.ParseMoney(refund.Amount.Value, refund.Amount.CurrencyCode)
+	return &RefundResponse{
+		RefundID:      refund.ID,
+		Amount:        refundAmount,
+		Status:        strings.ToLower(refund.Status),
+		TransactionID: transactionID,
+	}, nil
+}
+
+// GetPaymentIntent retrieves a PayPal order
+func (p *PayPalProvider) GetPaymentIntent(paymentIntentID string) (*models.PaymentIntent, error) {
+	order, err := p.getOrder(paymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent := &models.PaymentIntent{
+		ID:           order.ID,
+		Status:       paypalIntentStatus(order.Status),
+		ClientSecret: order.approveURL(),
+		Metadata:     map[string]string{"status": order.Status},
+	}
+	if len(order.PurchaseUnits) > 0 {
+		unit := order.PurchaseUnits[0]
+		intent.Amount, _ = models.ParseMoney(unit.Amount.Value, unit.Amount.CurrencyCode)
+		intent.Metadata["order_number"] = unit.ReferenceID
+		intent.Metadata["order_id"] = unit.CustomID
+	}
+	return intent, nil
+}
+
+// paypalIntentStatus maps a PayPal order status
+func paypalIntentStatus(status string) models.PaymentIntentStatus {
+	switch status {
+	case "COMPLETED":
+		return models.PaymentIntentStatusSucceeded
+	case "APPROVED":
+		// The buyer approved; the capture follows from the webhook
+		return models.PaymentIntentStatusProcessing
+	case "VOIDED":
+		return models.PaymentIntentStatusCanceled
+	default: // CREATED, SAVED, PAYER_ACTION_REQUIRED
+		return models.PaymentIntentStatusPending
+	}
+}
+
+// getOrder fetches the raw PayPal order
+func (p *PayPalProvider) getOrder(orderID string) (*paypalOrder, error) {
+	if orderID == "" {
+		return nil, ErrInvalidIntentID
+	}
+
+	var order paypalOrder
+	if err := p.do(http.MethodGet, "/v2/checkout/orders/"+url.PathEscape(orderID), nil, "", &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// SupportsZeroAmount - PayPal does not support zero amount orders
+func (p *PayPalProvider) SupportsZeroAmount() bool {
+	return false
+}
+
+// GetMinimumAmount returns PayPal's minimum amount (1 cent)
+func (p *PayPalProvider) GetMinimumAmount() int64 {
+	return 1
+}
+
+// Name returns the provider name
+func (p *PayPalProvider) Name() string {
+	return "paypal"
+}
+
+// VerifyWebhookSignature verifies a PayPal webhook through the
+// verify-webhook-signature API using the PAYPAL-* transmission headers
+func (p *PayPalProvider) VerifyWebhookSignature(headers http.Header, payload []byte) error {
+	if p.webhookID == "" {
+		return fmt.Errorf("%w: webhook ID not configured", ErrInvalidSignature)
+	}
+
+	transmissionSig := headers.Get("PAYPAL-TRANSMISSION-SIG")
+	if transmissionSig == "" {
+		return ErrMissingSignature
+	}
+
+	if !json.Valid(payload) {
+		return fmt.Errorf("%w: invalid payload", ErrInvalidSignature)
+	}
+
+	body := map[string]interface{}{
+		"auth_algo":         headers.Get("PAYPAL-AUTH-ALGO"),
+		"cert_url":          headers.Get("PAYPAL-CERT-URL"),
+		"transmission_id":   headers.Get("PAYPAL-TRANSMISSION-ID"),
+		"transmission_sig":  transmissionSig,
+		"transmission_time": headers.Get("PAYPAL-TRANSMISSION-TIME"),
+		"webhook_id":        p.webhookID,
+		"webhook_event":     json.RawMessage(payload),
+	}
+
+	var result struct {
+		VerificationStatus string `json:"verification_status"`
+	}
+	if err := p.do(http.MethodPost, "/v1/notifications/verify-webhook-signature", body, "", &result); err != nil {
+		return err
+	}
+
+	if result.VerificationStatus != "SUCCESS" {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// token returns a cached OAuth access token, fetching a new one when expired
+func (p *PayPalProvider) token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.tokenExpiry) {
+		return p.accessToken, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.baseURL+"/v1/oauth2/token", strings.NewReader("grant_type=client_credentials"))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: paypal token request returned status %d", ErrProviderError, resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("%w: invalid paypal token response: %v", ErrProviderError, err)
+	}
+
+	p.accessToken = tokenResp.AccessToken
+	// Refresh a minute early so a token never expires mid-request
+	p.tokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
+
+// do sends an authenticated JSON request to the PayPal API and decodes the response into out.
+// requestID is sent as PayPal-Request-Id, PayPal's idempotency key.
+func (p *PayPalProvider) do(method, path string, body interface{}, requestID string, out interface{}) error {
+	token, err := p.token()
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrProviderError, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, p.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if requestID != "" {
+		req.Header.Set("PayPal-Request-Id", requestID)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Force a new token on the next request
+		p.mu.Lock()
+		p.accessToken = ""
+		p.mu.Unlock()
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Name    string `json:"name"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("%w: %s", ErrInvalidIntentID, apiErr.Message)
+			}
+			return fmt.Errorf("%w: paypal %s: %s", ErrProviderError, apiErr.Name, apiErr.Message)
+		}
+		return fmt.Errorf("%w: paypal returned status %d", ErrProviderError, resp.StatusCode)
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("%w: invalid paypal response: %v", ErrProviderError, err)
+		}
+	}
+
+	return nil
+}
+
+// approveURL returns the link the buyer follows to approve the order
+func (o *paypalOrder) approveURL() string {
+	for _, link := range o.Links {
+		if link.Rel == "approve" || link.Rel == "payer-action" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// completedCapture returns the first completed capture of the order, if any
+func (o *paypalOrder) completedCapture() *paypalCapture {
+	for _, unit := range o.PurchaseUnits {
+		for i := range unit.Payments.Captures {
+			if unit.Payments.Captures[i].Status == "COMPLETED" {
+				return &unit.Payments.Captures[i]
+			}
+		}
+	}
+	return nil
+}
+
+// formatPayPalAmount formats an amount as the decimal string PayPal expects
+func formatPayPalAmount(amount models.Money) string {
+	if zeroDecimalCurrencies[strings.ToLower(amount.Currency)] {
+		return strconv.FormatInt(toStripeAmount(amount), 10)
+	}
+	return amount.Decimal()
+}
@@ -0,0 +1,236 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func newTestPayPalProvider(t *testing.T, handler http.HandlerFunc) *PayPalProvider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/oauth2/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "client" || pass != "secret" {
+				t.Errorf("unexpected basic auth %q:%q", user, pass)
+			}
+			fmt.Fprint(w, `{"access_token":"tok_123","expires_in":32400}`)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tok_123" {
+			t.Errorf("Authorization = %q", got)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewPayPalProvider(PayPalConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		WebhookID:    "WH-1",
+		BaseURL:      server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewPayPalProvider() error = %v", err)
+	}
+	return provider
+}
+
+func TestPayPalCreatePaymentIntent(t *testing.T) {
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/checkout/orders" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("PayPal-Request-Id") == "" {
+			t.Error("missing PayPal-Request-Id header")
+		}
+		var body struct {
+			Intent        string `json:"intent"`
+			PurchaseUnits []struct {
+				ReferenceID string       `json:"reference_id"`
+				Amount      paypalAmount `json:"amount"`
+			} `json:"purchase_units"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Intent != "CAPTURE" || len(body.PurchaseUnits) != 1 {
+			t.Fatalf("unexpected body: %+v", body)
+		}
+		unit := body.PurchaseUnits[0]
+		if unit.ReferenceID != "ORD-1" || unit.Amount.Value != "19.99" || unit.Amount.CurrencyCode != "EUR" {
+			t.Errorf("unexpected purchase unit: %+v", unit)
+		}
+		fmt.Fprint(w, `{"id":"5O190127TN364715T","status":"CREATED","links":[{"href":"https://www.paypal.com/checkoutnow?token=5O190127TN364715T","rel":"approve"}]}`)
+	})
+
+	intent, err := provider.CreatePaymentIntent(&CreatePaymentIntentRequest{
+		Amount:   models.NewMoney(1999, "eur"),
+		Metadata: map[string]string{"order_number": "ORD-1"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+	if intent.ID != "5O190127TN364715T" || intent.ClientSecret != "https://www.paypal.com/checkoutnow?token=5O190127TN364715T" {
+		t.Errorf("unexpected intent: %+v", intent)
+	}
+}
+
+func TestPayPalCachesAccessToken(t *testing.T) {
+	tokenCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/oauth2/token" {
+			tokenCalls++
+			fmt.Fprint(w, `{"access_token":"tok_123","expires_in":32400}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"ORDER","status":"CREATED"}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewPayPalProvider(PayPalConfig{ClientID: "client", ClientSecret: "secret", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := provider.GetPaymentIntent("ORDER"); err != nil {
+			t.Fatalf("GetPaymentIntent() error = %v", err)
+		}
+	}
+	if tokenCalls != 1 {
+		t.Errorf("expected 1 token request, got %d", tokenCalls)
+	}
+}
+
+func TestPayPalConfirmPaymentCaptures(t *testing.T) {
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/checkout/orders/ORDER/capture" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id":"ORDER","status":"COMPLETED"}`)
+	})
+
+	if err := provider.ConfirmPayment("ORDER"); err != nil {
+		t.Fatalf("ConfirmPayment() error = %v", err)
+	}
+}
+
+func TestPayPalPartialRefund(t *testing.T) {
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/checkout/orders/ORDER":
+			fmt.Fprint(w, `{"id":"ORDER","status":"COMPLETED","purchase_units":[{"payments":{"captures":[{"id":"CAP-1","status":"COMPLETED","amount":{"currency_code":"EUR","value":"50.00"}}]}}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/payments/captures/CAP-1/refund":
+			if got := r.Header.Get("PayPal-Request-Id"); got != "refund-ORD-1-1" {
+				t.Errorf("PayPal-Request-Id = %q, want refund-ORD-1-1", got)
+			}
+			var body struct {
+				Amount paypalAmount `json:"amount"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Amount.Value != "12.50" || body.Amount.CurrencyCode != "EUR" {
+				t.Errorf("unexpected refund amount: %+v", body.Amount)
+			}
+			fmt.Fprint(w, `{"id":"REF-1","status":"COMPLETED","amount":{"currency_code":"EUR","value":"12.50"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	amount := models.NewMoney(1250, "EUR")
+	refund, err := provider.Refund("ORDER", &amount, "ORD-1-1")
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if refund.RefundID != "REF-1" || refund.Amount.Amount != 1250 || refund.Status != "completed" {
+		t.Errorf("unexpected refund: %+v", refund)
+	}
+}
+
+func TestPayPalRefundWithoutCapture(t *testing.T) {
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"ORDER","status":"APPROVED","purchase_units":[{}]}`)
+	})
+
+	if _, err := provider.Refund("ORDER", nil, "ORD-1-1"); !errors.Is(err, ErrRefundFailed) {
+		t.Errorf("expected ErrRefundFailed, got %v", err)
+	}
+}
+
+func TestPayPalGetPaymentIntentNotFound(t *testing.T) {
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"name":"RESOURCE_NOT_FOUND","message":"The specified resource does not exist."}`)
+	})
+
+	if _, err := provider.GetPaymentIntent("missing"); !errors.Is(err, ErrInvalidIntentID) {
+		t.Errorf("expected ErrInvalidIntentID, got %v", err)
+	}
+}
+
+func TestPayPalVerifyWebhookSignature(t *testing.T) {
+	status := "SUCCESS"
+	provider := newTestPayPalProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/notifications/verify-webhook-signature" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			WebhookID       string          `json:"webhook_id"`
+			TransmissionSig string          `json:"transmission_sig"`
+			WebhookEvent    json.RawMessage `json:"webhook_event"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.WebhookID != "WH-1" || body.TransmissionSig != "sig" || string(body.WebhookEvent) != `{"id":"WH-EVENT"}` {
+			t.Errorf("unexpected verification body: %+v", body)
+		}
+		fmt.Fprintf(w, `{"verification_status":%q}`, status)
+	})
+
+	headers := http.Header{}
+	headers.Set("PAYPAL-TRANSMISSION-SIG", "sig")
+	headers.Set("PAYPAL-TRANSMISSION-ID", "tx-1")
+	payload := []byte(`{"id":"WH-EVENT"}`)
+
+	if err := provider.VerifyWebhookSignature(headers, payload); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	status = "FAILURE"
+	if err := provider.VerifyWebhookSignature(headers, payload); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if err := provider.VerifyWebhookSignature(http.Header{}, payload); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestFormatPayPalAmount(t *testing.T) {
+	if got := formatPayPalAmount(models.NewMoney(1990, "EUR")); got != "19.90" {
+		t.Errorf("formatPayPalAmount(19.9, EUR) = %q, want 19.90", got)
+	}
+	if got := formatPayPalAmount(models.NewMoney(50000, "JPY")); got != "500" {
+		t.Errorf("formatPayPalAmount(500, JPY) = %q, want 500", got)
+	}
+}
+
+func TestPayPalIntentStatus(t *testing.T) {
+	statuses := map[string]models.PaymentIntentStatus{
+		"CREATED":   models.PaymentIntentStatusPending,
+		"APPROVED":  models.PaymentIntentStatusProcessing,
+		"COMPLETED": models.PaymentIntentStatusSucceeded,
+		"VOIDED":    models.PaymentIntentStatusCanceled,
+	}
+
+	for paypalStatus, want := range statuses {
+		if got := paypalIntentStatus(paypalStatus); got != want {
+			t.Errorf("paypalIntentStatus(%s) = %s, want %s", paypalStatus, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package payment
 import (
 	"errors"
 	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/google/uuid"
 )
 
 var (
@@ -26,7 +27,9 @@ type Provider interface {
 	CancelPayment(paymentIntentID string) error
 	
 	// Refund refunds a payment
-	Refund(transactionID string, amount *float64) (*RefundResponse, error)
+	// A nil amount refunds the full payment; reference identifies the refund so that
+	// retrying the same refund never refunds twice
+	Refund(transactionID string, amount *models.Money, reference string) (*RefundResponse, error)
 	
 	// GetPaymentIntent retrieves a payment intent
 	GetPaymentIntent(paymentIntentID string) (*models.PaymentIntent, error)
@@ -43,8 +46,7 @@ type Provider interface {
 
 // CreatePaymentIntentRequest represents a payment intent creation request
 type CreatePaymentIntentRequest struct {
-	Amount      float64
-	Currency    string
+	Amount      models.Money
 	CustomerRef string
 	Items       []models.PaymentItem
 	Metadata    map[string]string
@@ -54,28 +56,34 @@ type CreatePaymentIntentRequest struct {
 // RefundResponse represents a refund response
 type RefundResponse struct {
 	RefundID     string
-	Amount       float64
-	Currency     string
+	Amount       models.Money
 	Status       string
 	TransactionID string
 }
 
 // ValidateAmount validates if the amount is acceptable for the provider
-func ValidateAmount(provider Provider, amount float64) error {
-	if amount < 0 {
+func ValidateAmount(provider Provider, amount models.Money) error {
+	if amount.IsNegative() {
 		return ErrInvalidAmount
 	}
 	
-	// Convert to cents for comparison
-	amountCents := int64(amount * 100)
-	
-	if amountCents == 0 && !provider.SupportsZeroAmount() {
+	if amount.IsZero() && !provider.SupportsZeroAmount() {
 		return ErrZeroAmountNotSupported
 	}
 	
-	if amountCents < provider.GetMinimumAmount() {
+	if amount.Amount < provider.GetMinimumAmount() {
 		return ErrInvalidAmount
 	}
 	
 	return nil
 }
+
+// idempotencyKey derives the idempotency key of an operation on a resource, so that a
+// repeated call for the same resource is recognized by the provider. Without an id only
+// the retries of a single call share the key.
+func idempotencyKey(operation, id string) string {
+	if id == "" {
+		return operation + "-" + uuid.New().String()
+	}
+	return operation + "-" + id
+}
@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+var (
+	ErrPaymentMethodUnavailable = errors.New("payment method not available")
+	ErrUnknownProvider          = errors.New("unknown payment provider")
+)
+
+// Registry maps payment methods to the providers that handle them.
+// Providers are also indexed by Name so that refunds and webhooks can be routed
+// back to the provider recorded on an order.
+type Registry struct {
+	mu              sync.RWMutex
+	defaultProvider Provider
+	byMethod        map[models.PaymentMethod]Provider
+	byName          map[string]Provider
+}
+
+// NewRegistry creates a registry whose default provider handles checkouts
+// without an explicit payment method
+func NewRegistry(defaultProvider Provider) *Registry {
+	r := &Registry{
+		defaultProvider: defaultProvider,
+		byMethod:        make(map[models.PaymentMethod]Provider),
+		byName:          make(map[string]Provider),
+	}
+	r.byName[defaultProvider.Name()] = defaultProvider
+	return r
+}
+
+// Register makes provider handle the given payment methods
+func (r *Registry) Register(provider Provider, methods ...models.PaymentMethod) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byName[provider.Name()] = provider
+	for _, method := range methods {
+		r.byMethod[method] = provider
+	}
+}
+
+// Default returns the default provider
+func (r *Registry) Default() Provider {
+	return r.defaultProvider
+}
+
+// ForMethod returns the provider for a checkout payment method.
+// An empty method resolves to the default provider.
+func (r *Registry) ForMethod(method models.PaymentMethod) (Provider, error) {
+	if method == "" {
+		return r.defaultProvider, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.byMethod[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPaymentMethodUnavailable, method)
+	}
+	return provider, nil
+}
+
+// ByName returns the provider with the given Name.
+// An empty name resolves to the default provider, for orders created before
+// the provider was recorded.
+func (r *Registry) ByName(name string) (Provider, error) {
+	if name == "" {
+		return r.defaultProvider, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
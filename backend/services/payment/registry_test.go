@@ -0,0 +1,48 @@
+package payment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestRegistry(t *testing.T) {
+	card := NewMockProvider("mock", 1, false)
+	etsy := NewEtsyProvider("shop", "https://example.com/shop", "")
+
+	registry := NewRegistry(card)
+	registry.Register(card, models.PaymentMethodCreditCard, models.PaymentMethodStripe)
+	registry.Register(etsy, models.PaymentMethodEtsy)
+
+	tests := []struct {
+		method  models.PaymentMethod
+		want    Provider
+		wantErr error
+	}{
+		{"", card, nil},
+		{models.PaymentMethodCreditCard, card, nil},
+		{models.PaymentMethodEtsy, etsy, nil},
+		{models.PaymentMethodPayPal, nil, ErrPaymentMethodUnavailable},
+	}
+
+	for _, tt := range tests {
+		got, err := registry.ForMethod(tt.method)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ForMethod(%q) error = %v, want %v", tt.method, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ForMethod(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+
+	if got, err := registry.ByName("etsy"); err != nil || got != etsy {
+		t.Errorf("ByName(etsy) = %v, %v", got, err)
+	}
+	if got, err := registry.ByName(""); err != nil || got != card {
+		t.Errorf("ByName(\"\") should fall back to the default provider, got %v, %v", got, err)
+	}
+	if _, err := registry.ByName("paypal"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("ByName(paypal) error = %v, want ErrUnknownProvider", err)
+	}
+}
@@ -1,27 +1,118 @@
 package payment
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
-	"github.com/google/uuid"
 )
 
-// StripeProvider is a Stripe payment provider
-// This is a stub implementation that simulates Stripe behavior
-// In production, integrate with github.com/stripe/stripe-go
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// zeroDecimalCurrencies are charged by Stripe in whole units instead of cents
+var zeroDecimalCurrencies = map[string]bool{
+	"bif": true, "clp": true, "djf": true, "gnf": true, "jpy": true, "kmf": true,
+	"krw": true, "mga": true, "pyg": true, "rwf": true, "ugx": true, "vnd": true,
+	"vuv": true, "xaf": true, "xof": true, "xpf": true,
+}
+
+// StripeProvider is a Stripe payment provider backed by the Stripe REST API
 type StripeProvider struct {
-	apiKey     string
-	webhookKey string
+	apiKey           string
+	webhookSecret    string
+	baseURL          string
+	httpClient       *http.Client
+	webhookTolerance time.Duration
+	maxRetries       int
+	retryDelay       time.Duration
+	now              func() time.Time
+}
+
+// StripeConfig holds configuration for the Stripe provider
+type StripeConfig struct {
+	APIKey           string
+	WebhookSecret    string
+	BaseURL          string
+	Timeout          time.Duration
+	WebhookTolerance time.Duration
+	MaxRetries       int
 }
 
 // NewStripeProvider creates a new Stripe payment provider
-func NewStripeProvider() *StripeProvider {
-	return &StripeProvider{
-		apiKey:     os.Getenv("STRIPE_API_KEY"),
-		webhookKey: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+func NewStripeProvider(config StripeConfig) (*StripeProvider, error) {
+	if config.APIKey == "" {
+		return nil, errors.New("stripe API key is required")
+	}
+
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.stripe.com"
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	if config.WebhookTolerance == 0 {
+		config.WebhookTolerance = 5 * time.Minute
 	}
+
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 2
+	}
+
+	return &StripeProvider{
+		apiKey:           config.APIKey,
+		webhookSecret:    config.WebhookSecret,
+		baseURL:          strings.TrimRight(config.BaseURL, "/"),
+		httpClient:       &http.Client{Timeout: config.Timeout},
+		webhookTolerance: config.WebhookTolerance,
+		maxRetries:       config.MaxRetries,
+		retryDelay:       500 * time.Millisecond,
+		now:              time.Now,
+	}, nil
+}
+
+// stripePaymentIntent is the subset of the Stripe PaymentIntent object we use
+type stripePaymentIntent struct {
+	ID           string            `json:"id"`
+	Amount       int64             `json:"amount"`
+	Currency     string            `json:"currency"`
+	Status       string            `json:"status"`
+	ClientSecret string            `json:"client_secret"`
+	Customer     string            `json:"customer"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+// stripeRefund is the subset of the Stripe Refund object we use
+type stripeRefund struct {
+	ID            string `json:"id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	PaymentIntent string `json:"payment_intent"`
+}
+
+// stripeError is the error envelope returned by the Stripe API
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
 }
 
 // CreatePaymentIntent creates a payment intent with Stripe
@@ -29,85 +120,138 @@ func (s *StripeProvider) CreatePaymentIntent(request *CreatePaymentIntentRequest
 	if err := ValidateAmount(s, request.Amount); err != nil {
 		return nil, err
 	}
-	
-	// In production, use Stripe SDK:
-	// params := &stripe.PaymentIntentParams{
-	//     Amount:   stripe.Int64(int64(request.Amount * 100)),
-	//     Currency: stripe.String(request.Currency),
-	//     ...
-	// }
-	// pi, err := paymentintent.New(params)
-	
-	// For now, simulate Stripe behavior
-	intentID := fmt.Sprintf("pi_%s", uuid.New().String())
-	clientSecret := fmt.Sprintf("%s_secret_%s", intentID, uuid.New().String())
-	
+
+	amount := request.Amount
+	if amount.Currency == "" {
+		amount = amount.WithCurrency(models.DefaultCurrency)
+	}
+	currency := strings.ToLower(amount.Currency)
+
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(toStripeAmount(amount), 10))
+	form.Set("currency", currency)
+	form.Set("automatic_payment_methods[enabled]", "true")
+	if request.Description != "" {
+		form.Set("description", request.Description)
+	}
+	if request.CustomerRef != "" {
+		form.Set("receipt_email", request.CustomerRef)
+	}
+	for key, value := range request.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	var pi stripePaymentIntent
+	key := idempotencyKey("intent", request.Metadata["order_number"])
+	if err := s.do(http.MethodPost, "/v1/payment_intents", form, key, &pi); err != nil {
+		return nil, err
+	}
+
 	return &models.PaymentIntent{
-		ID:           intentID,
-		Amount:       request.Amount,
-		Currency:     request.Currency,
+		ID:           pi.ID,
+		Amount:       fromStripeAmount(pi.Amount, pi.Currency),
 		CustomerRef:  request.CustomerRef,
 		Items:        request.Items,
-		Metadata:     request.Metadata,
-		ClientSecret: clientSecret,
+		Metadata:     pi.Metadata,
+		ClientSecret: pi.ClientSecret,
 	}, nil
 }
 
 // ConfirmPayment confirms a payment intent
 func (s *StripeProvider) ConfirmPayment(paymentIntentID string) error {
-	// In production:
-	// pi, err := paymentintent.Confirm(paymentIntentID, nil)
-	
-	// Simulate confirmation
-	return nil
+	if paymentIntentID == "" {
+		return ErrInvalidIntentID
+	}
+
+	return s.do(http.MethodPost, "/v1/payment_intents/"+url.PathEscape(paymentIntentID)+"/confirm", url.Values{}, idempotencyKey("confirm", paymentIntentID), nil)
 }
 
 // CancelPayment cancels a payment intent
 func (s *StripeProvider) CancelPayment(paymentIntentID string) error {
-	// In production:
-	// pi, err := paymentintent.Cancel(paymentIntentID, nil)
-	
-	return nil
-}
-
-// Refund processes a refund
-func (s *StripeProvider) Refund(transactionID string, amount *float64) (*RefundResponse, error) {
-	// In production:
-	// params := &stripe.RefundParams{
-	//     PaymentIntent: stripe.String(transactionID),
-	// }
-	// if amount != nil {
-	//     params.Amount = stripe.Int64(int64(*amount * 100))
-	// }
-	// refund, err := refund.New(params)
-	
-	refundID := fmt.Sprintf("re_%s", uuid.New().String())
-	refundAmount := 0.0
+	if paymentIntentID == "" {
+		return ErrInvalidIntentID
+	}
+
+	return s.do(http.MethodPost, "/v1/payment_intents/"+url.PathEscape(paymentIntentID)+"/cancel", url.Values{}, idempotencyKey("cancel", paymentIntentID), nil)
+}
+
+// Refund refunds a payment intent, fully or partially when amount is set
+func (s *StripeProvider) Refund(transactionID string, amount *models.Money, reference string) (*RefundResponse, error) {
+	if transactionID == "" {
+		return nil, ErrInvalidIntentID
+	}
+
+	form := url.Values{}
+	form.Set("payment_intent", transactionID)
 	if amount != nil {
-		refundAmount = *amount
+		if !amount.IsPositive() {
+			return nil, ErrInvalidAmount
+		}
+		// The refund currency is the intent's currency, which is needed to convert the amount
+		pi, err := s.getIntent(transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
+		}
+		form.Set("amount", strconv.FormatInt(toStripeAmount(amount.WithCurrency(pi.Currency)), 10))
+	}
+
+	var refund stripeRefund
+	if err := s.do(http.MethodPost, "/v1/refunds", form, idempotencyKey("refund", reference), &refund); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRefundFailed, err)
 	}
-	
+
 	return &RefundResponse{
-		RefundID:      refundID,
-		Amount:        refundAmount,
-		Currency:      "EUR",
-		Status:        "succeeded",
-		TransactionID: transactionID,
+		RefundID:      refund.ID,
+		Amount:        fromStripeAmount(refund.Amount, refund.Currency),
+		Status:        refund.Status,
+		TransactionID: refund.PaymentIntent,
 	}, nil
 }
 
 // GetPaymentIntent retrieves a payment intent
 func (s *StripeProvider) GetPaymentIntent(paymentIntentID string) (*models.PaymentIntent, error) {
-	// In production:
-	// pi, err := paymentintent.Get(paymentIntentID, nil)
-	
+	pi, err := s.getIntent(paymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.PaymentIntent{
-		ID:       paymentIntentID,
-		Amount:   0,
-		Currency: "EUR",
+		ID:           pi.ID,
+		Amount:       fromStripeAmount(pi.Amount, pi.Currency),
+		Status:       stripeIntentStatus(pi.Status),
+		CustomerRef:  pi.Customer,
+		Metadata:     pi.Metadata,
+		ClientSecret: pi.ClientSecret,
 	}, nil
 }
 
+// stripeIntentStatus maps a Stripe payment intent status
+func stripeIntentStatus(status string) models.PaymentIntentStatus {
+	switch status {
+	case "succeeded":
+		return models.PaymentIntentStatusSucceeded
+	case "processing", "requires_capture":
+		return models.PaymentIntentStatusProcessing
+	case "canceled":
+		return models.PaymentIntentStatusCanceled
+	default: // requires_payment_method, requires_confirmation, requires_action
+		return models.PaymentIntentStatusPending
+	}
+}
+
+// getIntent fetches the raw Stripe payment intent
+func (s *StripeProvider) getIntent(paymentIntentID string) (*stripePaymentIntent, error) {
+	if paymentIntentID == "" {
+		return nil, ErrInvalidIntentID
+	}
+
+	var pi stripePaymentIntent
+	if err := s.do(http.MethodGet, "/v1/payment_intents/"+url.PathEscape(paymentIntentID), nil, "", &pi); err != nil {
+		return nil, err
+	}
+	return &pi, nil
+}
+
 // SupportsZeroAmount - Stripe does not support zero amount charges
 func (s *StripeProvider) SupportsZeroAmount() bool {
 	return false
@@ -123,15 +267,153 @@ func (s *StripeProvider) Name() string {
 	return "stripe"
 }
 
-// VerifyWebhookSignature verifies a Stripe webhook signature
+// VerifyWebhookSignature verifies a Stripe-Signature header ("t=<unix>,v1=<hex hmac>,...")
+// against the payload, rejecting timestamps outside the configured tolerance
 func (s *StripeProvider) VerifyWebhookSignature(payload []byte, signature string) error {
-	// In production:
-	// event, err := webhook.ConstructEvent(payload, signature, s.webhookKey)
-	
-	// For now, just validate that signature exists
 	if signature == "" {
-		return fmt.Errorf("missing webhook signature")
+		return ErrMissingSignature
+	}
+
+	if s.webhookSecret == "" {
+		return fmt.Errorf("%w: webhook secret not configured", ErrInvalidSignature)
+	}
+
+	var timestamp int64
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			t, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+			}
+			timestamp = t
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return fmt.Errorf("%w: missing timestamp or v1 signature", ErrInvalidSignature)
+	}
+
+	age := s.now().Sub(time.Unix(timestamp, 0))
+	if age > s.webhookTolerance || age < -s.webhookTolerance {
+		return ErrSignatureExpired
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.webhookSecret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// do sends a request to the Stripe API and decodes the JSON response into out.
+// POST requests carry the idempotency key of their operation, which is reused across
+// retries, so a request retried after a network error or 5xx, or repeated by the
+// caller, never charges or refunds twice.
+func (s *StripeProvider) do(method, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.retryDelay * time.Duration(attempt))
+		}
+
+		retry, err := s.send(method, path, form, idempotencyKey, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// send performs a single Stripe API request and reports whether it may be retried
+func (s *StripeProvider) send(method, path string, form url.Values, idempotencyKey string, out interface{}) (bool, error) {
+	var body io.Reader
+	if form != nil && method != http.MethodGet {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequest(method, s.baseURL+path, body)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", ErrProviderError, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		var apiErr stripeError
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Message != "" {
+			if resp.StatusCode == http.StatusNotFound {
+				return false, fmt.Errorf("%w: %s", ErrInvalidIntentID, apiErr.Error.Message)
+			}
+			return retry, fmt.Errorf("%w: stripe %s: %s", ErrProviderError, apiErr.Error.Type, apiErr.Error.Message)
+		}
+		return retry, fmt.Errorf("%w: stripe returned status %d", ErrProviderError, resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return false, fmt.Errorf("%w: invalid stripe response: %v", ErrProviderError, err)
+		}
+	}
+
+	return false, nil
+}
+
+// toStripeAmount converts Money to the integer amount Stripe expects, which is in
+// whole units for zero-decimal currencies and in hundredths otherwise
+func toStripeAmount(amount models.Money) int64 {
+	if zeroDecimalCurrencies[strings.ToLower(amount.Currency)] {
+		return models.NewMoney(amount.Amount, amount.Currency).MulRatio(1, 100).Amount
+	}
+	return amount.Amount
+}
+
+// fromStripeAmount converts a Stripe amount back to Money
+func fromStripeAmount(amount int64, currency string) models.Money {
+	if zeroDecimalCurrencies[strings.ToLower(currency)] {
+		return models.NewMoney(amount*100, currency)
 	}
-	
-	return nil
+	return models.NewMoney(amount, currency)
 }
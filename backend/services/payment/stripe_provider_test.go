@@ -0,0 +1,257 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func newTestStripeProvider(t *testing.T, handler http.HandlerFunc) *StripeProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	provider, err := NewStripeProvider(StripeConfig{
+		APIKey:        "sk_test_123",
+		WebhookSecret: "whsec_test",
+		BaseURL:       server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewStripeProvider() error = %v", err)
+	}
+	provider.retryDelay = time.Millisecond
+	return provider
+}
+
+func TestStripeCreatePaymentIntent(t *testing.T) {
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/payment_intents" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk_test_123" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.Header.Get("Idempotency-Key") == "" {
+			t.Error("missing Idempotency-Key header")
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.PostForm.Get("amount"); got != "1999" {
+			t.Errorf("amount = %q, want 1999", got)
+		}
+		if got := r.PostForm.Get("currency"); got != "eur" {
+			t.Errorf("currency = %q, want eur", got)
+		}
+		if got := r.PostForm.Get("metadata[order_number]"); got != "ORD-1" {
+			t.Errorf("metadata[order_number] = %q", got)
+		}
+		fmt.Fprint(w, `{"id":"pi_123","amount":1999,"currency":"eur","status":"requires_payment_method","client_secret":"pi_123_secret","metadata":{"order_number":"ORD-1"}}`)
+	})
+
+	intent, err := provider.CreatePaymentIntent(&CreatePaymentIntentRequest{
+		Amount:   models.NewMoney(1999, "EUR"),
+		Metadata: map[string]string{"order_number": "ORD-1"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+	if intent.ID != "pi_123" || intent.ClientSecret != "pi_123_secret" || intent.Amount != models.NewMoney(1999, "EUR") {
+		t.Errorf("unexpected intent: %+v", intent)
+	}
+}
+
+func TestStripeRetriesWithSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"type":"api_error","message":"try again"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"pi_123","amount":500,"currency":"eur"}`)
+	})
+
+	if _, err := provider.CreatePaymentIntent(&CreatePaymentIntentRequest{Amount: models.NewMoney(500, "eur")}); err != nil {
+		t.Fatalf("CreatePaymentIntent() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("expected two attempts with the same idempotency key, got %v", keys)
+	}
+}
+
+func TestStripeDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprint(w, `{"error":{"type":"card_error","message":"Your card was declined."}}`)
+	})
+
+	_, err := provider.CreatePaymentIntent(&CreatePaymentIntentRequest{Amount: models.NewMoney(500, "eur")})
+	if !errors.Is(err, ErrProviderError) {
+		t.Fatalf("expected ErrProviderError, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestStripePartialRefund(t *testing.T) {
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/payment_intents/pi_123":
+			fmt.Fprint(w, `{"id":"pi_123","amount":5000,"currency":"eur"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/refunds":
+			r.ParseForm()
+			if got := r.PostForm.Get("payment_intent"); got != "pi_123" {
+				t.Errorf("payment_intent = %q", got)
+			}
+			if got := r.PostForm.Get("amount"); got != "1250" {
+				t.Errorf("amount = %q, want 1250", got)
+			}
+			fmt.Fprint(w, `{"id":"re_1","amount":1250,"currency":"eur","status":"succeeded","payment_intent":"pi_123"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	amount := models.NewMoney(1250, "EUR")
+	refund, err := provider.Refund("pi_123", &amount, "ORD-1-1")
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if refund.RefundID != "re_1" || refund.Amount.Amount != 1250 || refund.Status != "succeeded" {
+		t.Errorf("unexpected refund: %+v", refund)
+	}
+}
+
+func TestStripeRepeatedRefundReusesIdempotencyKey(t *testing.T) {
+	var keys []string
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		fmt.Fprint(w, `{"id":"re_1","amount":5000,"currency":"eur","status":"succeeded","payment_intent":"pi_123"}`)
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.Refund("pi_123", nil, "ORD-1-1"); err != nil {
+			t.Fatalf("Refund() error = %v", err)
+		}
+	}
+	if len(keys) != 2 || keys[0] != "refund-ORD-1-1" || keys[1] != keys[0] {
+		t.Errorf("expected both refunds to use refund-ORD-1-1, got %v", keys)
+	}
+}
+
+func TestStripeCancelPayment(t *testing.T) {
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/payment_intents/pi_123/cancel" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id":"pi_123","status":"canceled"}`)
+	})
+
+	if err := provider.CancelPayment("pi_123"); err != nil {
+		t.Fatalf("CancelPayment() error = %v", err)
+	}
+}
+
+func TestStripeGetPaymentIntentNotFound(t *testing.T) {
+	provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":{"type":"invalid_request_error","message":"No such payment_intent"}}`)
+	})
+
+	if _, err := provider.GetPaymentIntent("pi_missing"); !errors.Is(err, ErrInvalidIntentID) {
+		t.Errorf("expected ErrInvalidIntentID, got %v", err)
+	}
+}
+
+func TestStripeVerifyWebhookSignature(t *testing.T) {
+	provider, err := NewStripeProvider(StripeConfig{APIKey: "sk_test", WebhookSecret: "whsec_test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	provider.now = func() time.Time { return now }
+
+	payload := []byte(`{"type":"payment_intent.succeeded"}`)
+	sign := func(ts int64, secret string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "%d.%s", ts, payload)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		payload []byte
+		wantErr error
+	}{
+		{"valid", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sign(now.Unix(), "whsec_test")), payload, nil},
+		{"valid among several", fmt.Sprintf("t=%d,v1=deadbeef,v1=%s,v0=old", now.Unix(), sign(now.Unix(), "whsec_test")), payload, nil},
+		{"missing", "", payload, ErrMissingSignature},
+		{"wrong secret", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sign(now.Unix(), "other")), payload, ErrInvalidSignature},
+		{"tampered payload", fmt.Sprintf("t=%d,v1=%s", now.Unix(), sign(now.Unix(), "whsec_test")), []byte(`{}`), ErrInvalidSignature},
+		{"too old", fmt.Sprintf("t=%d,v1=%s", now.Unix()-600, sign(now.Unix()-600, "whsec_test")), payload, ErrSignatureExpired},
+		{"no timestamp", "v1=" + sign(now.Unix(), "whsec_test"), payload, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provider.VerifyWebhookSignature(tt.payload, tt.header)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestToStripeAmount(t *testing.T) {
+	if got := toStripeAmount(models.NewMoney(29, "eur")); got != 29 {
+		t.Errorf("toStripeAmount(0.29 EUR) = %d, want 29", got)
+	}
+	if got := toStripeAmount(models.NewMoney(50000, "JPY")); got != 500 {
+		t.Errorf("toStripeAmount(500 JPY) = %d, want 500", got)
+	}
+	if got := fromStripeAmount(500, "jpy"); got.Amount != 50000 || got.Currency != "JPY" {
+		t.Errorf("fromStripeAmount(500, jpy) = %+v", got)
+	}
+}
+
+func TestStripeGetPaymentIntentStatus(t *testing.T) {
+	statuses := map[string]models.PaymentIntentStatus{
+		"requires_payment_method": models.PaymentIntentStatusPending,
+		"requires_action":         models.PaymentIntentStatusPending,
+		"processing":              models.PaymentIntentStatusProcessing,
+		"succeeded":               models.PaymentIntentStatusSucceeded,
+		"canceled":                models.PaymentIntentStatusCanceled,
+	}
+
+	for stripeStatus, want := range statuses {
+		provider := newTestStripeProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"id":"pi_123","amount":1000,"currency":"eur","status":%q}`, stripeStatus)
+		})
+
+		intent, err := provider.GetPaymentIntent("pi_123")
+		if err != nil {
+			t.Fatalf("GetPaymentIntent() error = %v", err)
+		}
+		if intent.Status != want {
+			t.Errorf("status %s: got %s, want %s", stripeStatus, intent.Status, want)
+		}
+	}
+}
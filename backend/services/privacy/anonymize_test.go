@@ -0,0 +1,96 @@
+//go:build integration
+
+package privacy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/invoice"
+	"github.com/Naim0996/art-management-tool/backend/services/notification"
+)
+
+func TestAnonymizeScrubsMentions(t *testing.T) {
+	db := testdb.Open(t)
+	s := NewService(db, invoice.NewService(db, invoice.Config{}))
+
+	variant := testdb.CreateVariant(t, db, "PRIV-PRINT", 2)
+	order := testdb.CreateOrder(t, db, models.Order{OrderNumber: "ORD-PRIV-1", PaymentIntentID: "pi_priv_1"}, variant)
+
+	ret := models.ReturnRequest{RMANumber: "RMA-PRIV-1", OrderID: order.ID, Status: models.ReturnStatusRequested, Reason: "Mario Rossi from Via Roma 1: the print arrived torn"}
+	if err := db.Create(&ret).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	notifications := notification.NewService(db)
+	if err := notifications.CreateOrderCreatedNotification(order.OrderNumber, order.CustomerEmail, order.Total); err != nil {
+		t.Fatal(err)
+	}
+	if err := notifications.CreateReturnNotification(ret.RMANumber, order.OrderNumber, ret.Status, ret.Reason); err != nil {
+		t.Fatal(err)
+	}
+
+	// A Stripe body names the payment intent and carries the billing details
+	payload := fmt.Sprintf(`{"type":"payment_intent.succeeded","data":{"object":{"id":%q,"receipt_email":%q,
+		"billing_details":{"name":"Mario Rossi","address":{"line1":"Via Roma 1","city":"Milano","country":"IT"}}}}}`,
+		order.PaymentIntentID, strings.ToUpper(order.CustomerEmail))
+	event := models.WebhookEvent{Provider: "stripe", EventID: "evt_priv_1", EventType: "payment_intent.succeeded", Payload: payload, Status: models.WebhookEventStatusProcessed}
+	if err := db.Create(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	auditService := audit.NewService(db)
+	auditService.Record("admin", audit.EntityOrder, order.ID, audit.ActionCreate, nil, order)
+	auditService.Record("admin", audit.EntityReturn, ret.ID, audit.ActionCreate, nil, ret)
+
+	export, err := s.Export("Mario@Example.com")
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(export.Returns) != 1 || len(export.Notifications) != 2 || len(export.WebhookEvents) != 1 || len(export.AuditLogs) != 2 {
+		t.Fatalf("Export() = %d returns, %d notifications, %d webhook events, %d audit entries, want 1, 2, 1, 2",
+			len(export.Returns), len(export.Notifications), len(export.WebhookEvents), len(export.AuditLogs))
+	}
+
+	if _, err := s.Anonymize("mario@example.com"); err != nil {
+		t.Fatalf("Anonymize() error = %v", err)
+	}
+
+	var texts []string
+	var stored []models.Notification
+	db.Find(&stored)
+	for _, notif := range stored {
+		texts = append(texts, notif.Message, notif.Payload)
+	}
+	var events []models.WebhookEvent
+	db.Find(&events)
+	for _, event := range events {
+		texts = append(texts, event.Payload)
+	}
+	var returns []models.ReturnRequest
+	db.Find(&returns)
+	for _, ret := range returns {
+		texts = append(texts, ret.Reason)
+	}
+	var entries []models.AuditLog
+	db.Find(&entries)
+	for _, entry := range entries {
+		texts = append(texts, entry.Diff)
+	}
+
+	for _, text := range texts {
+		for _, leaked := range []string{"mario@example.com", "mario rossi", "via roma", "milano"} {
+			if strings.Contains(strings.ToLower(text), leaked) {
+				t.Errorf("%q is still stored in %s", leaked, text)
+			}
+		}
+	}
+
+	if len(events) != 1 || !strings.Contains(events[0].Payload, order.PaymentIntentID) || !strings.Contains(events[0].Payload, `"IT"`) {
+		t.Errorf("webhook payload = %v, want the payment intent and country kept", events)
+	}
+}
@@ -0,0 +1,143 @@
+package privacy
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// personalFields are the JSON keys under which audit diffs, notification payloads and
+// provider webhook bodies carry customer details or free text written by the customer
+var personalFields = map[string]bool{
+	"customer_email":     true,
+	"customer_name":      true,
+	"customer_tax_code":  true,
+	"customer_vat_id":    true,
+	"buyer_email":        true,
+	"buyer_name":         true,
+	"message_from_buyer": true,
+	"email":              true,
+	"email_address":      true,
+	"receipt_email":      true,
+	"name":               true,
+	"full_name":          true,
+	"given_name":         true,
+	"surname":            true,
+	"phone":              true,
+	"notes":              true,
+	"note":               true,
+	"reason":             true,
+}
+
+// addressFields hold an address, either as an object or as the JSON stored on orders
+var addressFields = map[string]bool{
+	"address":          true,
+	"shipping_address": true,
+	"billing_address":  true,
+}
+
+// countryFields are the parts of an address object that are kept
+var countryFields = map[string]bool{
+	"country":      true,
+	"country_code": true,
+}
+
+// removedText replaces customer text cut from notification messages
+const removedText = "[removed]"
+
+// scrubber removes the customer details of one erasure from stored documents
+type scrubber struct {
+	emails *regexp.Regexp
+}
+
+// newScrubber creates a scrubber replacing any of the emails, ignoring case
+func newScrubber(emails []string) *scrubber {
+	quoted := make([]string, len(emails))
+	for i, email := range emails {
+		quoted[i] = regexp.QuoteMeta(email)
+	}
+	return &scrubber{emails: regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))}
+}
+
+// text replaces the emails in free text
+func (s *scrubber) text(value string) string {
+	return s.emails.ReplaceAllString(value, AnonymizedEmail)
+}
+
+// document clears the personal fields of a JSON document, reduces its addresses to the
+// country and replaces the emails anywhere else. It also returns the text values it
+// cleared, so that they can be cut from a message repeating them. A document that is
+// not valid JSON is replaced with an empty object.
+func (s *scrubber) document(raw string) (string, []string) {
+	if raw == "" {
+		return "", nil
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return "{}", nil
+	}
+
+	var removed []string
+	doc = s.value(doc, &removed)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "{}", nil
+	}
+	return string(data), removed
+}
+
+// notification scrubs a notification payload and cuts the cleared values from its message
+func (s *scrubber) notification(message, payload string) (string, string) {
+	payload, removed := s.document(payload)
+	message = s.text(message)
+	for _, value := range removed {
+		message = strings.ReplaceAll(message, value, removedText)
+	}
+	return message, payload
+}
+
+// value scrubs a decoded JSON value, collecting the cleared text
+func (s *scrubber) value(value interface{}, removed *[]string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			switch text, isText := field.(string); {
+			case addressFields[key] && isText:
+				v[key] = scrubAddress(text)
+			case addressFields[key]:
+				v[key] = scrubAddressObject(field)
+			case personalFields[key] && isText:
+				if text != "" {
+					*removed = append(*removed, text)
+				}
+				v[key] = ""
+			default:
+				v[key] = s.value(field, removed)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.value(item, removed)
+		}
+		return v
+	case string:
+		return s.text(v)
+	default:
+		return v
+	}
+}
+
+// scrubAddressObject keeps only the country of an address object
+func scrubAddressObject(value interface{}) interface{} {
+	address, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	for key := range address {
+		if !countryFields[key] {
+			delete(address, key)
+		}
+	}
+	return address
+}
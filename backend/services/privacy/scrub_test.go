@@ -0,0 +1,61 @@
+package privacy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestScrubberDocument(t *testing.T) {
+	s := newScrubber([]string{"mario@example.com"})
+	raw := `{
+		"before": {"customer_email": "Mario@Example.com", "shipping_address": "{\"street\":\"Via Roma 1\",\"country\":\"IT\"}", "status": "pending"},
+		"data": {"object": {"billing_details": {"name": "Mario Rossi", "address": {"line1": "Via Roma 1", "city": "Milano", "country": "IT"}}, "description": "Paid by mario@example.com"}},
+		"items": [{"quantity": 2}]
+	}`
+
+	got, removed := s.document(raw)
+	for _, leaked := range []string{"mario@example.com", "Mario", "Via Roma", "Milano"} {
+		if strings.Contains(strings.ToLower(got), strings.ToLower(leaked)) {
+			t.Errorf("document() = %s, still contains %q", got, leaked)
+		}
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &doc); err != nil {
+		t.Fatalf("document() returned invalid JSON: %v", err)
+	}
+	before := doc["before"].(map[string]interface{})
+	if before["status"] != "pending" || before["shipping_address"] != `{"street":"","city":"","state":"","zip_code":"","country":"IT"}` {
+		t.Errorf("document() before = %v, want the status and country kept", before)
+	}
+	address := doc["data"].(map[string]interface{})["object"].(map[string]interface{})["billing_details"].(map[string]interface{})["address"]
+	if country := address.(map[string]interface{})["country"]; country != "IT" {
+		t.Errorf("document() billing country = %v, want IT", country)
+	}
+	if len(removed) != 2 {
+		t.Errorf("document() removed = %v, want the email and the name", removed)
+	}
+
+	if got, _ := s.document("not json"); got != "{}" {
+		t.Errorf("document(invalid) = %q, want {}", got)
+	}
+}
+
+func TestScrubberNotification(t *testing.T) {
+	s := newScrubber([]string{"mario@example.com"})
+
+	message, payload := s.notification("Return R-1 for order ORD-1 is now requested. The print arrived torn",
+		`{"rma_number":"R-1","order_number":"ORD-1","status":"requested","note":"The print arrived torn"}`)
+	if message != "Return R-1 for order ORD-1 is now requested. [removed]" {
+		t.Errorf("notification() message = %q", message)
+	}
+	if strings.Contains(payload, "torn") || !strings.Contains(payload, "ORD-1") {
+		t.Errorf("notification() payload = %s", payload)
+	}
+
+	message, _ = s.notification("New order from MARIO@example.com for 25.00 EUR", `{"order_number":"ORD-2","customer_email":"MARIO@example.com"}`)
+	if message != "New order from "+AnonymizedEmail+" for 25.00 EUR" {
+		t.Errorf("notification() message = %q", message)
+	}
+}
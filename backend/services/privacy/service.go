@@ -0,0 +1,469 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/audit"
+	"github.com/Naim0996/art-management-tool/backend/services/invoice"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOpenOrders     = errors.New("customer has paid orders still to be shipped")
+	ErrInvoicePending = errors.New("invoice could not be archived")
+)
+
+// Placeholders written over the customer data of anonymized orders and receipts
+const (
+	AnonymizedEmail = "anonymized@invalid"
+	AnonymizedName  = "Anonymized"
+)
+
+// Service finds, exports and anonymizes the personal data tied to an email address
+// (GDPR access and erasure requests)
+type Service struct {
+	db       *gorm.DB
+	invoices *invoice.Service
+}
+
+// NewService creates a new privacy service
+func NewService(db *gorm.DB, invoices *invoice.Service) *Service {
+	return &Service{db: db, invoices: invoices}
+}
+
+// Export is everything the shop holds about an email address
+type Export struct {
+	Email         string                   `json:"email"`
+	ExportedAt    time.Time                `json:"exported_at"`
+	Customer      *models.Customer         `json:"customer,omitempty"`
+	Addresses     []models.CustomerAddress `json:"addresses"`
+	Sessions      []models.CustomerSession `json:"sessions"`
+	Carts         []models.Cart            `json:"carts"`
+	Orders        []models.Order           `json:"orders"`
+	Returns       []models.ReturnRequest   `json:"returns"`
+	Invoices      []models.Invoice         `json:"invoices"`
+	EtsyReceipts  []models.EtsyReceipt     `json:"etsy_receipts"`
+	Notifications []models.Notification    `json:"notifications"`
+	WebhookEvents []models.WebhookEvent    `json:"webhook_events"`
+	AuditLogs     []models.AuditLog        `json:"audit_logs"`
+}
+
+// Summary identifies the records a request touched without repeating the email, for the
+// audit log
+type Summary struct {
+	EmailHash    string   `json:"email_hash"` // SHA-256 of the normalized email
+	CustomerID   *uint    `json:"customer_id,omitempty"`
+	Orders       []string `json:"orders"`
+	EtsyReceipts []int64  `json:"etsy_receipts"`
+}
+
+// Summary summarizes the export
+func (e *Export) Summary() *Summary {
+	summary := &Summary{
+		EmailHash:    HashEmail(e.Email),
+		Orders:       make([]string, 0, len(e.Orders)),
+		EtsyReceipts: make([]int64, 0, len(e.EtsyReceipts)),
+	}
+	if e.Customer != nil {
+		summary.CustomerID = &e.Customer.ID
+	}
+	for _, order := range e.Orders {
+		summary.Orders = append(summary.Orders, order.OrderNumber)
+	}
+	for _, receipt := range e.EtsyReceipts {
+		summary.EtsyReceipts = append(summary.EtsyReceipts, receipt.EtsyReceiptID)
+	}
+	return summary
+}
+
+// Export collects the customer account, carts, orders with their returns and invoices,
+// and Etsy receipts tied to an email, with the admin notifications, payment webhook
+// events and audit entries about them. Soft-deleted records are included. An email the
+// shop knows nothing about gives an empty export.
+func (s *Service) Export(email string) (*Export, error) {
+	email = normalizeEmail(email)
+	if err := validateEmail(email); err != nil {
+		return nil, err
+	}
+
+	export := &Export{Email: email, ExportedAt: time.Now()}
+
+	var customer models.Customer
+	err := s.db.Unscoped().Where("email = ?", email).First(&customer).Error
+	if err == nil {
+		export.Customer = &customer
+		if err := s.db.Where("customer_id = ?", customer.ID).Find(&export.Addresses).Error; err != nil {
+			return nil, err
+		}
+		if err := s.db.Where("customer_id = ?", customer.ID).Find(&export.Sessions).Error; err != nil {
+			return nil, err
+		}
+		if err := s.db.Where("user_id = ?", customer.ID).Preload("Items").Find(&export.Carts).Error; err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.db.Unscoped().Where("LOWER(buyer_email) = ?", email).Order("id ASC").Find(&export.EtsyReceipts).Error; err != nil {
+		return nil, err
+	}
+
+	orders, err := s.findOrders(email, export.Customer, export.EtsyReceipts)
+	if err != nil {
+		return nil, err
+	}
+	export.Orders = orders
+
+	orderIDs := make([]uint, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+	}
+	if len(orderIDs) > 0 {
+		if err := s.db.Where("order_id IN ?", orderIDs).Preload("Items").Order("id ASC").Find(&export.Returns).Error; err != nil {
+			return nil, err
+		}
+		if err := s.db.Where("order_id IN ?", orderIDs).Order("id ASC").Find(&export.Invoices).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.findMentions(export); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// emails returns the addresses of the export: the one requested and those the orders
+// of the customer account were placed with
+func (e *Export) emails() []string {
+	seen := map[string]bool{e.Email: true}
+	emails := []string{e.Email}
+	for _, order := range e.Orders {
+		email := normalizeEmail(order.CustomerEmail)
+		if email != "" && email != AnonymizedEmail && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// findMentions finds the notifications, webhook events and audit entries that mention
+// the emails of the export or are about its orders, returns and Etsy receipts
+func (s *Service) findMentions(export *Export) error {
+	emails := export.emails()
+
+	var orderIDs, returnIDs, receiptIDs []uint
+	var orderNumbers, rmaNumbers, references []string
+	for _, order := range export.Orders {
+		orderIDs = append(orderIDs, order.ID)
+		orderNumbers = append(orderNumbers, order.OrderNumber)
+		if order.PaymentIntentID != "" {
+			references = append(references, order.PaymentIntentID)
+		}
+	}
+	for _, ret := range export.Returns {
+		returnIDs = append(returnIDs, ret.ID)
+		rmaNumbers = append(rmaNumbers, ret.RMANumber)
+	}
+	for _, receipt := range export.EtsyReceipts {
+		receiptIDs = append(receiptIDs, receipt.ID)
+	}
+
+	condition, args := mentionCondition("message", emails)
+	notifications := s.db.Where(condition, args...)
+	condition, args = mentionCondition("payload::text", emails)
+	notifications = notifications.Or(condition, args...)
+	if len(orderNumbers) > 0 {
+		notifications = notifications.Or("payload->>'order_number' IN ?", orderNumbers)
+	}
+	if len(rmaNumbers) > 0 {
+		notifications = notifications.Or("payload->>'rma_number' IN ?", rmaNumbers)
+	}
+	if err := notifications.Order("id ASC").Find(&export.Notifications).Error; err != nil {
+		return err
+	}
+
+	// Provider bodies mention the payment intent of the order rather than its number
+	condition, args = mentionCondition("payload::text", append(emails, references...))
+	if err := s.db.Where(condition, args...).Order("id ASC").Find(&export.WebhookEvents).Error; err != nil {
+		return err
+	}
+
+	condition, args = mentionCondition("diff::text", emails)
+	entries := s.db.Where(condition, args...)
+	for entityType, ids := range map[string][]uint{
+		audit.EntityOrder:       orderIDs,
+		audit.EntityReturn:      returnIDs,
+		audit.EntityEtsyReceipt: receiptIDs,
+	} {
+		if len(ids) > 0 {
+			entries = entries.Or("entity_type = ? AND entity_id IN ?", entityType, ids)
+		}
+	}
+	return entries.Order("id ASC").Find(&export.AuditLogs).Error
+}
+
+// mentionCondition matches rows whose column contains any of the values, ignoring case
+func mentionCondition(column string, values []string) (string, []interface{}) {
+	conditions := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		conditions[i] = "POSITION(? IN LOWER(" + column + ")) > 0"
+		args[i] = strings.ToLower(value)
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// archiveFile is a file of an export ZIP
+type archiveFile struct {
+	name     string
+	content  []byte
+	modified time.Time
+}
+
+// Archive packs an export as a ZIP with data.json and the PDF of every invoice
+func (e *Export) Archive() ([]byte, error) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []archiveFile{{"data.json", data, e.ExportedAt}}
+	for _, inv := range e.Invoices {
+		if len(inv.Document) > 0 {
+			name := "invoices/" + strings.ReplaceAll(inv.Number, "/", "-") + ".pdf"
+			files = append(files, archiveFile{name, inv.Document, inv.IssuedAt})
+		}
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, f := range files {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: f.modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Anonymize erases the personal data tied to an email. Orders and Etsy receipts keep
+// their numbers, items, totals, tax and payment references for accounting, while the
+// customer's name, email, tax codes, addresses (but their country) and notes are
+// overwritten, as are return reasons and the customer details in the notifications,
+// webhook events and audit entries of the export. The customer account is deleted with
+// its addresses, sessions and carts.
+// Issued invoices are kept as issued, as the law requires; their FatturaPA XML is
+// generated first so that it is never rebuilt from the anonymized order. Paid orders
+// not yet shipped block the erasure with ErrOpenOrders.
+func (s *Service) Anonymize(email string) (*Summary, error) {
+	export, err := s.Export(email)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range export.Orders {
+		if blocksErasure(&order) {
+			return nil, fmt.Errorf("%w: %s", ErrOpenOrders, order.OrderNumber)
+		}
+	}
+	for _, inv := range export.Invoices {
+		if len(inv.EInvoice) == 0 {
+			if _, _, err := s.invoices.EInvoice(inv.OrderID); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvoicePending, inv.Number, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, order := range export.Orders {
+			if err := tx.Unscoped().Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+				"customer_email":    AnonymizedEmail,
+				"customer_name":     AnonymizedName,
+				"customer_tax_code": "",
+				"customer_vat_id":   "",
+				"shipping_address":  scrubAddress(order.ShippingAddress),
+				"billing_address":   scrubAddress(order.BillingAddress),
+				"notes":             "",
+				"user_id":           nil,
+				"anonymized_at":     now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, receipt := range export.EtsyReceipts {
+			if err := tx.Unscoped().Model(&models.EtsyReceipt{}).Where("id = ?", receipt.ID).Updates(map[string]interface{}{
+				"buyer_email":        AnonymizedEmail,
+				"buyer_name":         AnonymizedName,
+				"shipping_address":   "",
+				"message_from_buyer": "",
+				"anonymized_at":      now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := scrubMentions(tx, export); err != nil {
+			return err
+		}
+
+		if export.Customer == nil {
+			return nil
+		}
+		customerID := export.Customer.ID
+		carts := tx.Model(&models.Cart{}).Select("id").Where("user_id = ?", customerID)
+		if err := tx.Where("cart_id IN (?)", carts).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", customerID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.CustomerAddress{}, &models.CustomerSession{}, &models.CustomerToken{}} {
+			if err := tx.Where("customer_id = ?", customerID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&models.Customer{}, customerID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return export.Summary(), nil
+}
+
+// scrubMentions clears the return reasons of the export and the customer details in its
+// notifications, webhook events and audit entries
+func scrubMentions(tx *gorm.DB, export *Export) error {
+	for _, ret := range export.Returns {
+		if err := tx.Model(&models.ReturnRequest{}).Where("id = ?", ret.ID).Update("reason", "").Error; err != nil {
+			return err
+		}
+	}
+
+	scrub := newScrubber(export.emails())
+	for _, notif := range export.Notifications {
+		message, payload := scrub.notification(notif.Message, notif.Payload)
+		if err := tx.Model(&models.Notification{}).Where("id = ?", notif.ID).Updates(map[string]interface{}{
+			"message": message,
+			"payload": nullableJSON(payload),
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, event := range export.WebhookEvents {
+		payload, _ := scrub.document(event.Payload)
+		if err := tx.Model(&models.WebhookEvent{}).Where("id = ?", event.ID).Update("payload", payload).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range export.AuditLogs {
+		diff, _ := scrub.document(entry.Diff)
+		if err := tx.Model(&models.AuditLog{}).Where("id = ?", entry.ID).Update("diff", nullableJSON(diff)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullableJSON stores an empty optional JSON column as NULL, which jsonb accepts
+func nullableJSON(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// findOrders finds the orders placed with the email, by the customer account, or
+// imported from the Etsy receipts
+func (s *Service) findOrders(email string, customer *models.Customer, receipts []models.EtsyReceipt) ([]models.Order, error) {
+	query := s.db.Unscoped().Where("LOWER(customer_email) = ?", email)
+	if customer != nil {
+		query = query.Or("user_id = ?", customer.ID)
+	}
+	var receiptOrders []uint
+	for _, receipt := range receipts {
+		if receipt.LocalOrderID != nil {
+			receiptOrders = append(receiptOrders, *receipt.LocalOrderID)
+		}
+	}
+	if len(receiptOrders) > 0 {
+		query = query.Or("id IN ?", receiptOrders)
+	}
+
+	var orders []models.Order
+	err := query.
+		Preload("Items").
+		Preload("Shipments.Items").
+		Preload("Events").
+		Preload("Refunds.Items").
+		Order("id ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+// blocksErasure checks if an order was paid but not yet shipped, so that its customer
+// data is still needed to fulfill it
+func blocksErasure(order *models.Order) bool {
+	if order.AnonymizedAt != nil {
+		return false
+	}
+	paid := order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusPartiallyRefunded
+	open := order.FulfillmentStatus == models.FulfillmentStatusUnfulfilled || order.FulfillmentStatus == models.FulfillmentStatusPartiallyFulfilled
+	return paid && open
+}
+
+// scrubAddress keeps only the country of a stored order address, which the VAT applied
+// depends on
+func scrubAddress(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	var address models.Address
+	if err := json.Unmarshal([]byte(raw), &address); err != nil {
+		return "{}"
+	}
+	data, err := json.Marshal(models.Address{Country: address.Country})
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// HashEmail identifies an email in the audit log without storing it
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(normalizeEmail(email)))
+	return hex.EncodeToString(sum[:])
+}
+
+// validateEmail checks a normalized email
+func validateEmail(email string) error {
+	return models.NewValidator().Required("email", email).Email("email", email).Errors()
+}
+
+// normalizeEmail lower-cases and trims an email, as customer accounts store it
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,109 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestScrubAddress(t *testing.T) {
+	raw := `{"street":"Via Roma 1","city":"Milano","state":"MI","zip_code":"20121","country":"IT"}`
+	got := scrubAddress(raw)
+	if got != `{"street":"","city":"","state":"","zip_code":"","country":"IT"}` {
+		t.Errorf("scrubAddress() = %s", got)
+	}
+	if got := scrubAddress(""); got != "" {
+		t.Errorf("scrubAddress(\"\") = %q", got)
+	}
+	if got := scrubAddress("Via Roma 1, Milano"); got != "{}" {
+		t.Errorf("scrubAddress(invalid) = %q, want {}", got)
+	}
+}
+
+func TestBlocksErasure(t *testing.T) {
+	tests := []struct {
+		payment     models.PaymentStatus
+		fulfillment models.FulfillmentStatus
+		want        bool
+	}{
+		{models.PaymentStatusPaid, models.FulfillmentStatusUnfulfilled, true},
+		{models.PaymentStatusPartiallyRefunded, models.FulfillmentStatusPartiallyFulfilled, true},
+		{models.PaymentStatusPaid, models.FulfillmentStatusFulfilled, false},
+		{models.PaymentStatusPending, models.FulfillmentStatusUnfulfilled, false},
+		{models.PaymentStatusRefunded, models.FulfillmentStatusUnfulfilled, false},
+		{models.PaymentStatusPaid, models.FulfillmentStatusCancelled, false},
+	}
+	for _, tt := range tests {
+		order := &models.Order{PaymentStatus: tt.payment, FulfillmentStatus: tt.fulfillment}
+		if got := blocksErasure(order); got != tt.want {
+			t.Errorf("blocksErasure(%s, %s) = %v, want %v", tt.payment, tt.fulfillment, got, tt.want)
+		}
+	}
+
+	now := time.Now()
+	order := &models.Order{PaymentStatus: models.PaymentStatusPaid, FulfillmentStatus: models.FulfillmentStatusUnfulfilled, AnonymizedAt: &now}
+	if blocksErasure(order) {
+		t.Errorf("blocksErasure() = true for an anonymized order")
+	}
+}
+
+func TestSummaryLeavesOutTheEmail(t *testing.T) {
+	export := &Export{
+		Email:        "mario@example.com",
+		Customer:     &models.Customer{ID: 7, Email: "mario@example.com"},
+		Orders:       []models.Order{{OrderNumber: "ORD-2025-00001"}},
+		EtsyReceipts: []models.EtsyReceipt{{EtsyReceiptID: 123}},
+	}
+
+	summary := export.Summary()
+	if summary.EmailHash != HashEmail(" Mario@Example.com ") || strings.Contains(summary.EmailHash, "@") {
+		t.Errorf("EmailHash = %s", summary.EmailHash)
+	}
+	if summary.CustomerID == nil || *summary.CustomerID != 7 {
+		t.Errorf("CustomerID = %v, want 7", summary.CustomerID)
+	}
+	if len(summary.Orders) != 1 || summary.Orders[0] != "ORD-2025-00001" || len(summary.EtsyReceipts) != 1 {
+		t.Errorf("Summary() = %+v", summary)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	export := &Export{
+		Email:      "mario@example.com",
+		ExportedAt: time.Now(),
+		Invoices: []models.Invoice{
+			{Number: "2025/0001", Document: []byte("%PDF-1.4")},
+			{Number: "2025/0002"}, // Not rendered, nothing to pack
+		},
+	}
+
+	archive, err := export.Archive()
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "data.json,invoices/2025-0001.pdf" {
+		t.Errorf("archive files = %v", names)
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	if err := validateEmail(normalizeEmail(" Mario@Example.com ")); err != nil {
+		t.Errorf("validateEmail() error = %v", err)
+	}
+	if err := validateEmail(""); err == nil {
+		t.Errorf("validateEmail(\"\") accepted an empty email")
+	}
+}
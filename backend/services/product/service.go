@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Naim0996/art-management-tool/backend/models"
 	"gorm.io/gorm"
@@ -52,11 +53,11 @@ func (s *Service) ListProducts(filters *ProductFilters) ([]models.EnhancedProduc
 		query = query.Where("LOWER(character_value) = LOWER(?)", filters.CharacterValue)
 	}
 
-	if filters.MinPrice > 0 {
+	if filters.MinPrice.IsPositive() {
 		query = query.Where("base_price >= ?", filters.MinPrice)
 	}
 
-	if filters.MaxPrice > 0 {
+	if filters.MaxPrice.IsPositive() {
 		query = query.Where("base_price <= ?", filters.MaxPrice)
 	}
 
@@ -69,7 +70,9 @@ func (s *Service) ListProducts(filters *ProductFilters) ([]models.EnhancedProduc
 	if filters.InStock {
 		// FIX: Usa una subquery per filtrare prodotti con almeno una variante in stock
 		// Questo funziona anche per prodotti senza varianti (non vengono esclusi)
-		query = query.Where("EXISTS (SELECT 1 FROM product_variants WHERE product_variants.product_id = products.id AND product_variants.stock > 0)")
+		// Stock held in carts is not available
+		query = query.Where(`EXISTS (SELECT 1 FROM product_variants WHERE product_variants.product_id = products.id AND product_variants.stock > COALESCE(
+			(SELECT SUM(quantity) FROM stock_reservations WHERE stock_reservations.variant_id = product_variants.id AND stock_reservations.expires_at > ?), 0))`, time.Now())
 	}
 
 	// Count total
@@ -214,6 +217,18 @@ func (s *Service) AddVariant(productID uint, variant *models.ProductVariant) err
 	return s.db.Create(variant).Error
 }
 
+// GetVariant gets a variant by ID
+func (s *Service) GetVariant(id uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := s.db.First(&variant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVariantNotFound
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
 // UpdateVariant updates a variant
 func (s *Service) UpdateVariant(id uint, updates *models.ProductVariant) error {
 	var variant models.ProductVariant
@@ -303,8 +318,8 @@ type ProductFilters struct {
 	CategoryID     uint
 	CharacterID    uint
 	CharacterValue string
-	MinPrice       float64
-	MaxPrice       float64
+	MinPrice       models.Money
+	MaxPrice       models.Money
 	Search         string
 	InStock        bool
 	Page           int
@@ -0,0 +1,89 @@
+//go:build integration
+
+package returns
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/database/testdb"
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/notification"
+	"github.com/Naim0996/art-management-tool/backend/services/numbering"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"github.com/Naim0996/art-management-tool/backend/services/product"
+	"github.com/Naim0996/art-management-tool/backend/services/tax"
+)
+
+func TestReturnApproveReceiveRefund(t *testing.T) {
+	db := testdb.Open(t)
+	orderNumbers, _ := numbering.NewGenerator(numbering.SequenceOrder, "ORD-{YYYY}-{SEQ:6}")
+	refundNumbers, _ := numbering.NewGenerator(numbering.SequenceRefund, "REF-{YYYY}-{SEQ:6}")
+	notifService := notification.NewService(db)
+	orderService := order.NewService(db, payment.NewRegistry(payment.NewMockProvider("mock", 50, false)),
+		tax.NewService(db, tax.Config{}), notifService, orderNumbers, refundNumbers)
+	s := NewService(db, orderService, product.NewService(db), notifService)
+
+	poster := testdb.CreateVariant(t, db, "POSTER-A2", 1)
+	mug := testdb.CreateVariant(t, db, "MUG", 1)
+	o := testdb.CreateOrder(t, db, models.Order{
+		OrderNumber:     "ORD-1",
+		PaymentStatus:   models.PaymentStatusPaid,
+		PaymentProvider: "mock",
+		PaymentIntentID: "mock_pi_paid",
+	}, poster, mug)
+	if err := orderService.CreateShipment(o.ID, &models.Shipment{Carrier: "UPS"}); err != nil {
+		t.Fatalf("CreateShipment() error = %v", err)
+	}
+
+	ret, err := s.Open(o.ID, []models.ReturnItem{
+		{OrderItemID: o.Items[0].ID, Quantity: 1},
+		{OrderItemID: o.Items[1].ID, Quantity: 1},
+	}, "Wrong size", OpenedByCustomer)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := s.Receive(ret.ID, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Receive() before approval: error = %v, want ErrInvalidTransition", err)
+	}
+	if _, err := s.Approve(ret.ID, "Send it back"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	// The mug arrived broken and is written off; the poster goes back on sale
+	ret, err = s.Receive(ret.ID, map[uint]models.ReturnDisposition{o.Items[1].ID: models.ReturnDispositionWriteOff})
+	if err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	if ret.Status != models.ReturnStatusReceived || ret.ReceivedAt == nil {
+		t.Errorf("return = %+v, want received", ret)
+	}
+	if _, err := s.Receive(ret.ID, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second Receive() error = %v, want ErrInvalidTransition", err)
+	}
+	assertStock := func(when string) {
+		t.Helper()
+		db.First(poster, poster.ID)
+		db.First(mug, mug.ID)
+		if poster.Stock != 1 || mug.Stock != 0 {
+			t.Errorf("%s: stock poster %d, mug %d, want 1 and 0", when, poster.Stock, mug.Stock)
+		}
+	}
+	assertStock("after receiving")
+
+	ret, err = s.Refund(ret.ID, nil)
+	if err != nil {
+		t.Fatalf("Refund() error = %v", err)
+	}
+	if ret.Status != models.ReturnStatusRefunded || ret.RefundID == nil {
+		t.Errorf("return = %+v, want refunded with its refund", ret)
+	}
+	assertStock("after refunding")
+
+	var got models.Order
+	db.Preload("Refunds").First(&got, o.ID)
+	if got.PaymentStatus != models.PaymentStatusRefunded || len(got.Refunds) != 1 || got.Refunds[0].Amount != models.EUR(5000) {
+		t.Errorf("order %s with refunds %+v, want refunded once in full", got.PaymentStatus, got.Refunds)
+	}
+}
@@ -0,0 +1,373 @@
+package returns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/notification"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/product"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReturnNotFound    = errors.New("return not found")
+	ErrInvalidReturn     = errors.New("invalid return")
+	ErrInvalidTransition = errors.New("invalid return status transition")
+)
+
+// OpenedByCustomer marks returns requested from the shop rather than by an admin
+const OpenedByCustomer = "customer"
+
+// transitions lists the return statuses reachable from each status.
+// Approved returns can still be rejected, e.g. when the goods never arrive.
+var transitions = map[models.ReturnStatus][]models.ReturnStatus{
+	models.ReturnStatusRequested: {models.ReturnStatusApproved, models.ReturnStatusRejected},
+	models.ReturnStatusApproved:  {models.ReturnStatusReceived, models.ReturnStatusRejected},
+	models.ReturnStatusReceived:  {models.ReturnStatusRefunded},
+	models.ReturnStatusRejected:  {},
+	models.ReturnStatusRefunded:  {},
+}
+
+// Service handles return requests (RMA)
+type Service struct {
+	db             *gorm.DB
+	orderService   *order.Service
+	productService *product.Service
+	notifService   *notification.Service
+}
+
+// NewService creates a new returns service
+func NewService(db *gorm.DB, orderService *order.Service, productService *product.Service, notifService *notification.Service) *Service {
+	return &Service{
+		db:             db,
+		orderService:   orderService,
+		productService: productService,
+		notifService:   notifService,
+	}
+}
+
+// Filters represents filters for return listing
+type Filters struct {
+	Status  models.ReturnStatus
+	OrderID uint
+}
+
+// List lists return requests, newest first
+func (s *Service) List(filters Filters) ([]models.ReturnRequest, error) {
+	query := s.db.Preload("Items")
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+	if filters.OrderID > 0 {
+		query = query.Where("order_id = ?", filters.OrderID)
+	}
+
+	var returns []models.ReturnRequest
+	if err := query.Order("created_at DESC, id DESC").Find(&returns).Error; err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+// Get gets a return request with its items
+func (s *Service) Get(id uint) (*models.ReturnRequest, error) {
+	var ret models.ReturnRequest
+	if err := s.db.Preload("Items").First(&ret, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReturnNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Open opens a return request for items of a shipped, paid order.
+// openedBy is the admin opening it, or OpenedByCustomer.
+func (s *Service) Open(orderID uint, items []models.ReturnItem, reason, openedBy string) (*models.ReturnRequest, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidReturn)
+	}
+
+	o, err := s.orderService.GetOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.PaymentStatus != models.PaymentStatusPaid && o.PaymentStatus != models.PaymentStatusPartiallyRefunded {
+		return nil, fmt.Errorf("%w: order %s is not paid", ErrInvalidReturn, o.OrderNumber)
+	}
+	if o.FulfillmentStatus == models.FulfillmentStatusUnfulfilled || o.FulfillmentStatus == models.FulfillmentStatusCancelled {
+		return nil, fmt.Errorf("%w: order %s has not shipped", ErrInvalidReturn, o.OrderNumber)
+	}
+
+	ret := models.ReturnRequest{
+		OrderID:  o.ID,
+		Status:   models.ReturnStatusRequested,
+		Reason:   reason,
+		OpenedBy: openedBy,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.ReturnRequest
+		if err := tx.Preload("Items").Where("order_id = ?", o.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		checked, err := returnItems(o, existing, items)
+		if err != nil {
+			return err
+		}
+
+		ret.RMANumber = fmt.Sprintf("RMA-%s-%d", o.OrderNumber, len(existing)+1)
+		ret.Items = checked
+		return tx.Create(&ret).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifService.CreateReturnNotification(ret.RMANumber, o.OrderNumber, ret.Status, reason)
+	return &ret, nil
+}
+
+// OpenForCustomer opens a return for a customer who proves the order is theirs with its
+// number and email address. A mismatch is reported as order not found.
+func (s *Service) OpenForCustomer(orderNumber, email string, items []models.ReturnItem, reason string) (*models.ReturnRequest, error) {
+	var o models.Order
+	if err := s.db.Select("id").
+		Where("order_number = ? AND LOWER(customer_email) = ?", strings.TrimSpace(orderNumber), strings.ToLower(strings.TrimSpace(email))).
+		First(&o).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, order.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return s.Open(o.ID, items, reason, OpenedByCustomer)
+}
+
+// Approve accepts a requested return; the customer can send the goods back
+func (s *Service) Approve(id uint, note string) (*models.ReturnRequest, error) {
+	return s.decide(id, models.ReturnStatusApproved, note)
+}
+
+// Reject turns a return down
+func (s *Service) Reject(id uint, note string) (*models.ReturnRequest, error) {
+	return s.decide(id, models.ReturnStatusRejected, note)
+}
+
+// decide moves a return to approved or rejected with the admin's note
+func (s *Service) decide(id uint, status models.ReturnStatus, note string) (*models.ReturnRequest, error) {
+	ret, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canTransition(ret.Status, status); err != nil {
+		return nil, err
+	}
+
+	ret.AdminNote = strings.TrimSpace(note)
+	if err := s.claim(ret, status, map[string]interface{}{"admin_note": ret.AdminNote}); err != nil {
+		return nil, err
+	}
+
+	s.notify(ret, ret.AdminNote)
+	return ret, nil
+}
+
+// Receive records that the goods of an approved return arrived. Each item is restocked
+// unless its disposition says it is written off.
+func (s *Service) Receive(id uint, dispositions map[uint]models.ReturnDisposition) (*models.ReturnRequest, error) {
+	ret, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canTransition(ret.Status, models.ReturnStatusReceived); err != nil {
+		return nil, err
+	}
+	if err := applyDispositions(ret, dispositions); err != nil {
+		return nil, err
+	}
+
+	o, err := s.orderService.GetOrder(ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	ret.ReceivedAt = &now
+	if err := s.claim(ret, models.ReturnStatusReceived, map[string]interface{}{"received_at": now}); err != nil {
+		return nil, err
+	}
+	for _, item := range ret.Items {
+		if err := s.db.Model(&item).Update("disposition", item.Disposition).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Restocking goes through the product service once the return is claimed, so that
+	// a concurrent receive cannot add the stock twice
+	for _, item := range ret.Items {
+		if item.Disposition != models.ReturnDispositionRestock {
+			continue
+		}
+		variantID := variantOf(o, item.OrderItemID)
+		if variantID == nil {
+			continue
+		}
+		if err := s.productService.UpdateInventory(*variantID, item.Quantity, "add"); err != nil {
+			return nil, fmt.Errorf("return %s received but restocking item %d failed: %w", ret.RMANumber, item.OrderItemID, err)
+		}
+	}
+
+	s.notify(ret, "")
+	return ret, nil
+}
+
+// Refund refunds the items of a received return through the order's payment provider.
+// Without an amount the refund is worth what the customer paid for the items.
+func (s *Service) Refund(id uint, amount *models.Money) (*models.ReturnRequest, error) {
+	ret, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := canTransition(ret.Status, models.ReturnStatusRefunded); err != nil {
+		return nil, err
+	}
+
+	req := &order.RefundRequest{
+		Amount:    amount,
+		Reason:    "Return " + ret.RMANumber,
+		Restocked: true,
+	}
+	for _, item := range ret.Items {
+		req.Items = append(req.Items, models.RefundItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+
+	refund, err := s.orderService.RefundOrder(ret.OrderID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ret.RefundID = &refund.ID
+	if err := s.claim(ret, models.ReturnStatusRefunded, map[string]interface{}{"refund_id": refund.ID}); err != nil {
+		return nil, err
+	}
+
+	s.notify(ret, fmt.Sprintf("Refunded %s", refund.Amount))
+	return ret, nil
+}
+
+// claim moves a return to a status with a conditional update, so that two admins acting
+// on the same return at once cannot both succeed
+func (s *Service) claim(ret *models.ReturnRequest, status models.ReturnStatus, fields map[string]interface{}) error {
+	fields["status"] = status
+	result := s.db.Model(&models.ReturnRequest{}).
+		Where("id = ? AND status = ?", ret.ID, ret.Status).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: return %s changed meanwhile", ErrInvalidTransition, ret.RMANumber)
+	}
+	ret.Status = status
+	return nil
+}
+
+// notify raises the notification of a return's new status
+func (s *Service) notify(ret *models.ReturnRequest, note string) {
+	var o models.Order
+	s.db.Select("order_number").First(&o, ret.OrderID)
+	s.notifService.CreateReturnNotification(ret.RMANumber, o.OrderNumber, ret.Status, note)
+}
+
+// canTransition checks that a return may move between two statuses
+func canTransition(from, to models.ReturnStatus) error {
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot change return status from %s to %s", ErrInvalidTransition, from, to)
+}
+
+// returnItems checks the requested quantities against what can still be returned of each
+// order item: not refunded and not part of another return that was not rejected
+func returnItems(o *models.Order, existing []models.ReturnRequest, requested []models.ReturnItem) ([]models.ReturnItem, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: no items to return", ErrInvalidReturn)
+	}
+
+	refunded := models.RefundedQuantities(o.Refunds)
+	remaining := make(map[uint]int, len(o.Items))
+	for _, item := range o.Items {
+		remaining[item.ID] = item.Quantity - refunded[item.ID]
+	}
+	for _, ret := range existing {
+		// Refunded returns are already counted by the order's refunds
+		if ret.Status == models.ReturnStatusRejected || ret.Status == models.ReturnStatusRefunded {
+			continue
+		}
+		for _, item := range ret.Items {
+			remaining[item.OrderItemID] -= item.Quantity
+		}
+	}
+
+	items := make([]models.ReturnItem, 0, len(requested))
+	for _, item := range requested {
+		left, ok := remaining[item.OrderItemID]
+		if !ok {
+			return nil, fmt.Errorf("%w: item %d does not belong to order %s", ErrInvalidReturn, item.OrderItemID, o.OrderNumber)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity of item %d must be positive", ErrInvalidReturn, item.OrderItemID)
+		}
+		if item.Quantity > left {
+			return nil, fmt.Errorf("%w: only %d of item %d can be returned", ErrInvalidReturn, max(left, 0), item.OrderItemID)
+		}
+		remaining[item.OrderItemID] = left - item.Quantity
+		items = append(items, models.ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+	}
+	return items, nil
+}
+
+// applyDispositions sets what happens to each returned item, keyed by order item.
+// Items without a disposition are restocked.
+func applyDispositions(ret *models.ReturnRequest, dispositions map[uint]models.ReturnDisposition) error {
+	known := make(map[uint]bool, len(ret.Items))
+	for i := range ret.Items {
+		item := &ret.Items[i]
+		known[item.OrderItemID] = true
+
+		disposition, ok := dispositions[item.OrderItemID]
+		switch {
+		case !ok:
+			item.Disposition = models.ReturnDispositionRestock
+		case disposition == models.ReturnDispositionRestock, disposition == models.ReturnDispositionWriteOff:
+			item.Disposition = disposition
+		default:
+			return fmt.Errorf("%w: unknown disposition %q", ErrInvalidReturn, disposition)
+		}
+	}
+
+	for orderItemID := range dispositions {
+		if !known[orderItemID] {
+			return fmt.Errorf("%w: item %d is not part of return %s", ErrInvalidReturn, orderItemID, ret.RMANumber)
+		}
+	}
+	return nil
+}
+
+// variantOf returns the variant of an order item, nil when it has none
+func variantOf(o *models.Order, orderItemID uint) *uint {
+	for _, item := range o.Items {
+		if item.ID == orderItemID {
+			return item.VariantID
+		}
+	}
+	return nil
+}
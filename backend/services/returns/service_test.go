@@ -0,0 +1,89 @@
+package returns
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestReturnItems(t *testing.T) {
+	order := &models.Order{
+		OrderNumber: "ORD-1",
+		Items:       []models.OrderItem{{ID: 1, Quantity: 3}, {ID: 2, Quantity: 1}},
+		Refunds: []models.Refund{
+			{Items: []models.RefundItem{{OrderItemID: 1, Quantity: 1}}},
+		},
+	}
+	existing := []models.ReturnRequest{
+		{Status: models.ReturnStatusApproved, Items: []models.ReturnItem{{OrderItemID: 1, Quantity: 1}}},
+		{Status: models.ReturnStatusRejected, Items: []models.ReturnItem{{OrderItemID: 2, Quantity: 1}}},
+	}
+
+	items, err := returnItems(order, existing, []models.ReturnItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 2, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("returnItems() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("items = %+v", items)
+	}
+
+	invalid := [][]models.ReturnItem{
+		nil,
+		{{OrderItemID: 1, Quantity: 2}}, // One refunded and one in an open return
+		{{OrderItemID: 9, Quantity: 1}}, // Not in the order
+		{{OrderItemID: 2, Quantity: 0}},
+	}
+	for _, requested := range invalid {
+		if _, err := returnItems(order, existing, requested); !errors.Is(err, ErrInvalidReturn) {
+			t.Errorf("returnItems(%+v) error = %v, want ErrInvalidReturn", requested, err)
+		}
+	}
+}
+
+func TestApplyDispositions(t *testing.T) {
+	ret := &models.ReturnRequest{
+		RMANumber: "RMA-ORD-1-1",
+		Items:     []models.ReturnItem{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 2, Quantity: 1}},
+	}
+
+	if err := applyDispositions(ret, map[uint]models.ReturnDisposition{2: models.ReturnDispositionWriteOff}); err != nil {
+		t.Fatalf("applyDispositions() error = %v", err)
+	}
+	if ret.Items[0].Disposition != models.ReturnDispositionRestock || ret.Items[1].Disposition != models.ReturnDispositionWriteOff {
+		t.Errorf("dispositions = %+v", ret.Items)
+	}
+
+	if err := applyDispositions(ret, map[uint]models.ReturnDisposition{1: "recycle"}); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("unknown disposition: error = %v", err)
+	}
+	if err := applyDispositions(ret, map[uint]models.ReturnDisposition{3: models.ReturnDispositionRestock}); !errors.Is(err, ErrInvalidReturn) {
+		t.Errorf("unknown item: error = %v", err)
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	allowed := [][2]models.ReturnStatus{
+		{models.ReturnStatusRequested, models.ReturnStatusApproved},
+		{models.ReturnStatusRequested, models.ReturnStatusRejected},
+		{models.ReturnStatusApproved, models.ReturnStatusReceived},
+		{models.ReturnStatusReceived, models.ReturnStatusRefunded},
+	}
+	for _, tt := range allowed {
+		if err := canTransition(tt[0], tt[1]); err != nil {
+			t.Errorf("%s -> %s: error = %v", tt[0], tt[1], err)
+		}
+	}
+
+	denied := [][2]models.ReturnStatus{
+		{models.ReturnStatusRequested, models.ReturnStatusReceived},
+		{models.ReturnStatusReceived, models.ReturnStatusRejected},
+		{models.ReturnStatusRefunded, models.ReturnStatusApproved},
+		{models.ReturnStatusRejected, models.ReturnStatusApproved},
+	}
+	for _, tt := range denied {
+		if err := canTransition(tt[0], tt[1]); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: error = %v, want ErrInvalidTransition", tt[0], tt[1], err)
+		}
+	}
+}
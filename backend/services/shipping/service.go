@@ -0,0 +1,375 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrZoneNotFound        = errors.New("shipping zone not found")
+	ErrMethodNotFound      = errors.New("shipping method not found")
+	ErrMethodUnavailable   = errors.New("shipping method not available for this cart")
+	ErrNoShippingAvailable = errors.New("no shipping method available for this country")
+	ErrInvalidZone         = errors.New("invalid shipping zone")
+	ErrInvalidMethod       = errors.New("invalid shipping method")
+)
+
+// Service handles shipping zones, methods and quotes
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a new shipping service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Quote is the price of a shipping method for a cart
+type Quote struct {
+	MethodID    uint         `json:"method_id"`
+	Code        string       `json:"code"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Price       models.Money `json:"price"`
+	Free        bool         `json:"free"` // The free shipping threshold was reached
+}
+
+// Measures are the cart quantities rate brackets are based on
+type Measures struct {
+	WeightGrams int64
+	Items       int64
+	Subtotal    models.Money
+}
+
+// MeasureCart works out the weight, item count and subtotal of a cart
+func MeasureCart(cart *models.Cart) Measures {
+	m := Measures{Subtotal: models.NewMoney(0, models.DefaultCurrency)}
+	for _, item := range cart.Items {
+		quantity := int64(item.Quantity)
+		m.Items += quantity
+		m.Subtotal = m.Subtotal.Add(item.CalculateTotal())
+		if item.Product != nil {
+			m.WeightGrams += int64(item.Product.WeightGrams) * quantity
+		}
+	}
+	return m
+}
+
+// Quote lists the shipping methods available for a cart shipped to a country, cheapest first
+func (s *Service) Quote(cart *models.Cart, country string) ([]Quote, error) {
+	zone, err := s.zoneFor(country)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return []Quote{}, nil
+	}
+
+	measures := MeasureCart(cart)
+	quotes := make([]Quote, 0, len(zone.Methods))
+	for i := range zone.Methods {
+		if quote, ok := quoteMethod(&zone.Methods[i], measures); ok {
+			quotes = append(quotes, quote)
+		}
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Price.Cmp(quotes[j].Price) < 0
+	})
+	return quotes, nil
+}
+
+// Select returns the quote of the chosen method, or the cheapest one when methodID is zero.
+// It returns nil when no shipping method is configured at all, so the shop keeps working
+// before shipping is set up.
+func (s *Service) Select(cart *models.Cart, country string, methodID uint) (*Quote, error) {
+	quotes, err := s.Quote(cart, country)
+	if err != nil {
+		return nil, err
+	}
+
+	if methodID != 0 {
+		for i := range quotes {
+			if quotes[i].MethodID == methodID {
+				return &quotes[i], nil
+			}
+		}
+		return nil, ErrMethodUnavailable
+	}
+
+	if len(quotes) > 0 {
+		return &quotes[0], nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.ShippingMethod{}).Where("active = ?", true).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrNoShippingAvailable
+	}
+	return nil, nil
+}
+
+// zoneFor finds the zone of a country with its active methods, falling back to the
+// fallback zone. It returns nil when no zone matches.
+func (s *Service) zoneFor(country string) (*models.ShippingZone, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+
+	var zones []models.ShippingZone
+	if err := s.db.
+		Preload("Methods", func(db *gorm.DB) *gorm.DB {
+			return db.Where("active = ?", true).Order("position ASC, id ASC")
+		}).
+		Preload("Methods.Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_value ASC")
+		}).
+		Order("id ASC").
+		Find(&zones).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shipping zones: %w", err)
+	}
+
+	var fallback *models.ShippingZone
+	for i := range zones {
+		if zones[i].HasCountry(country) {
+			return &zones[i], nil
+		}
+		if zones[i].Fallback && fallback == nil {
+			fallback = &zones[i]
+		}
+	}
+	return fallback, nil
+}
+
+// quoteMethod prices a method for the cart measures
+func quoteMethod(method *models.ShippingMethod, m Measures) (Quote, bool) {
+	var value int64
+	switch method.RateBasis {
+	case models.ShippingRateByWeight:
+		value = m.WeightGrams
+	case models.ShippingRateBySubtotal:
+		value = m.Subtotal.Amount
+	default:
+		value = m.Items
+	}
+
+	price, ok := method.PriceFor(value)
+	if !ok {
+		return Quote{}, false
+	}
+
+	quote := Quote{
+		MethodID:    method.ID,
+		Code:        method.Code,
+		Name:        method.Name,
+		Description: method.Description,
+		Price:       price,
+	}
+
+	threshold := method.FreeShippingThreshold
+	if threshold.IsPositive() && m.Subtotal.Cmp(threshold) >= 0 {
+		quote.Price = models.NewMoney(0, price.Currency)
+		quote.Free = true
+	}
+	return quote, true
+}
+
+// ListZones lists the shipping zones with their methods and rates
+func (s *Service) ListZones() ([]models.ShippingZone, error) {
+	var zones []models.ShippingZone
+	if err := s.db.
+		Preload("Methods", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).
+		Preload("Methods.Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("min_value ASC")
+		}).
+		Order("name ASC").
+		Find(&zones).Error; err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// GetZone retrieves a zone by ID
+func (s *Service) GetZone(id uint) (*models.ShippingZone, error) {
+	var zone models.ShippingZone
+	if err := s.db.First(&zone, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// SaveZone validates and creates or updates a zone
+func (s *Service) SaveZone(zone *models.ShippingZone) error {
+	zone.Name = strings.TrimSpace(zone.Name)
+	if zone.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidZone)
+	}
+
+	countries := make([]string, 0, len(zone.Countries))
+	for _, c := range zone.Countries {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if len(c) != 2 {
+			return fmt.Errorf("%w: %q is not an ISO 3166-1 alpha-2 code", ErrInvalidZone, c)
+		}
+		countries = append(countries, c)
+	}
+	zone.Countries = countries
+
+	// A country can only belong to one zone, otherwise quotes would depend on zone order
+	var others []models.ShippingZone
+	if err := s.db.Where("id <> ?", zone.ID).Find(&others).Error; err != nil {
+		return err
+	}
+	for _, other := range others {
+		for _, c := range zone.Countries {
+			if other.HasCountry(c) {
+				return fmt.Errorf("%w: %s already belongs to zone %q", ErrInvalidZone, c, other.Name)
+			}
+		}
+	}
+
+	return s.db.Omit("Methods").Save(zone).Error
+}
+
+// DeleteZone deletes a zone with its methods and rates
+func (s *Service) DeleteZone(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var methodIDs []uint
+		if err := tx.Model(&models.ShippingMethod{}).Where("zone_id = ?", id).Pluck("id", &methodIDs).Error; err != nil {
+			return err
+		}
+		if len(methodIDs) > 0 {
+			if err := tx.Where("method_id IN ?", methodIDs).Delete(&models.ShippingRate{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("zone_id = ?", id).Delete(&models.ShippingMethod{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.ShippingZone{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrZoneNotFound
+		}
+		return nil
+	})
+}
+
+// GetMethod retrieves a method by ID with its rates
+func (s *Service) GetMethod(id uint) (*models.ShippingMethod, error) {
+	var method models.ShippingMethod
+	if err := s.db.Preload("Rates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("min_value ASC")
+	}).First(&method, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMethodNotFound
+		}
+		return nil, err
+	}
+	return &method, nil
+}
+
+// SaveMethod validates and creates or updates a method, replacing its rates
+func (s *Service) SaveMethod(method *models.ShippingMethod) error {
+	if err := validateMethod(method); err != nil {
+		return err
+	}
+	if _, err := s.GetZone(method.ZoneID); err != nil {
+		if errors.Is(err, ErrZoneNotFound) {
+			return fmt.Errorf("%w: zone %d does not exist", ErrInvalidMethod, method.ZoneID)
+		}
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rates").Save(method).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("method_id = ?", method.ID).Delete(&models.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		for i := range method.Rates {
+			method.Rates[i].ID = 0
+			method.Rates[i].MethodID = method.ID
+		}
+		if len(method.Rates) > 0 {
+			if err := tx.Create(&method.Rates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteMethod deletes a method with its rates
+func (s *Service) DeleteMethod(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("method_id = ?", id).Delete(&models.ShippingRate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.ShippingMethod{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMethodNotFound
+		}
+		return nil
+	})
+}
+
+// validateMethod checks a method and its rate brackets
+func validateMethod(method *models.ShippingMethod) error {
+	method.Code = strings.ToLower(strings.TrimSpace(method.Code))
+	if method.Code == "" || strings.TrimSpace(method.Name) == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidMethod)
+	}
+	if method.RateBasis == "" {
+		method.RateBasis = models.ShippingRateByItems
+	}
+	if !method.RateBasis.IsValid() {
+		return fmt.Errorf("%w: unknown rate basis %q", ErrInvalidMethod, method.RateBasis)
+	}
+	if method.Currency == "" {
+		method.Currency = models.DefaultCurrency
+	}
+	method.Currency = strings.ToUpper(method.Currency)
+	if method.FreeShippingThreshold.IsNegative() {
+		return fmt.Errorf("%w: free shipping threshold cannot be negative", ErrInvalidMethod)
+	}
+	if len(method.Rates) == 0 {
+		return fmt.Errorf("%w: at least one rate is required", ErrInvalidMethod)
+	}
+
+	sort.SliceStable(method.Rates, func(i, j int) bool {
+		return method.Rates[i].MinValue < method.Rates[j].MinValue
+	})
+	for i, rate := range method.Rates {
+		if rate.MinValue < 0 || rate.Price.IsNegative() {
+			return fmt.Errorf("%w: rate %d has a negative bound or price", ErrInvalidMethod, i+1)
+		}
+		if rate.MaxValue != 0 && rate.MaxValue <= rate.MinValue {
+			return fmt.Errorf("%w: rate %d must have max_value greater than min_value", ErrInvalidMethod, i+1)
+		}
+		if i > 0 {
+			previous := method.Rates[i-1]
+			if previous.MaxValue == 0 || previous.MaxValue > rate.MinValue {
+				return fmt.Errorf("%w: rates %d and %d overlap", ErrInvalidMethod, i, i+1)
+			}
+		}
+	}
+	return nil
+}
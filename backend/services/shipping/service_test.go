@@ -0,0 +1,88 @@
+package shipping
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+func TestQuoteMethodByWeight(t *testing.T) {
+	method := &models.ShippingMethod{
+		ID:        1,
+		Code:      "standard",
+		RateBasis: models.ShippingRateByWeight,
+		Currency:  "EUR",
+		Rates: []models.ShippingRate{
+			{MinValue: 0, MaxValue: 1000, Price: models.EUR(590)},
+			{MinValue: 1000, MaxValue: 5000, Price: models.EUR(990)},
+		},
+	}
+
+	tests := []struct {
+		weight int64
+		want   int64
+		ok     bool
+	}{
+		{0, 590, true},
+		{999, 590, true},
+		{1000, 990, true},
+		{5000, 0, false}, // Above the last bracket
+	}
+
+	for _, tt := range tests {
+		quote, ok := quoteMethod(method, Measures{WeightGrams: tt.weight, Subtotal: models.EUR(2000)})
+		if ok != tt.ok || (ok && quote.Price.Amount != tt.want) {
+			t.Errorf("weight %d: got %d, %v, want %d, %v", tt.weight, quote.Price.Amount, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestQuoteMethodFreeShipping(t *testing.T) {
+	method := &models.ShippingMethod{
+		RateBasis:             models.ShippingRateByItems,
+		FreeShippingThreshold: models.EUR(5000),
+		Currency:              "EUR",
+		Rates:                 []models.ShippingRate{{MinValue: 1, Price: models.EUR(700)}},
+	}
+
+	if quote, _ := quoteMethod(method, Measures{Items: 2, Subtotal: models.EUR(4999)}); quote.Free || quote.Price.Amount != 700 {
+		t.Errorf("below threshold: %+v", quote)
+	}
+	if quote, _ := quoteMethod(method, Measures{Items: 2, Subtotal: models.EUR(5000)}); !quote.Free || !quote.Price.IsZero() {
+		t.Errorf("at threshold: %+v", quote)
+	}
+}
+
+func TestValidateMethod(t *testing.T) {
+	valid := func() *models.ShippingMethod {
+		return &models.ShippingMethod{
+			Code: "Express",
+			Name: "Express",
+			Rates: []models.ShippingRate{
+				{MinValue: 3, Price: models.EUR(1500)},
+				{MinValue: 0, MaxValue: 3, Price: models.EUR(1200)},
+			},
+		}
+	}
+
+	method := valid()
+	if err := validateMethod(method); err != nil {
+		t.Fatalf("validateMethod() error = %v", err)
+	}
+	if method.Code != "express" || method.RateBasis != models.ShippingRateByItems || method.Rates[0].MinValue != 0 {
+		t.Errorf("method not normalized: %+v", method)
+	}
+
+	overlapping := valid()
+	overlapping.Rates[1].MaxValue = 5
+	if err := validateMethod(overlapping); !errors.Is(err, ErrInvalidMethod) {
+		t.Errorf("overlapping rates: error = %v", err)
+	}
+
+	noRates := valid()
+	noRates.Rates = nil
+	if err := validateMethod(noRates); !errors.Is(err, ErrInvalidMethod) {
+		t.Errorf("no rates: error = %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package tax
+
+import "github.com/Naim0996/art-management-tool/backend/models"
+
+// euRates holds the standard and printed-book VAT rates of the EU member states in
+// basis points. They seed an empty rate table; admins maintain the table afterwards.
+var euRates = []struct {
+	country  string
+	standard int64
+	reduced  int64
+}{
+	{"AT", 2000, 1000},
+	{"BE", 2100, 600},
+	{"BG", 2000, 900},
+	{"CY", 1900, 500},
+	{"CZ", 2100, 0},
+	{"DE", 1900, 700},
+	{"DK", 2500, 2500},
+	{"EE", 2400, 1300},
+	{"ES", 2100, 400},
+	{"FI", 2550, 1400},
+	{"FR", 2000, 550},
+	{"GR", 2400, 600},
+	{"HR", 2500, 500},
+	{"HU", 2700, 500},
+	{"IE", 2300, 0},
+	{"IT", 2200, 400},
+	{"LT", 2100, 900},
+	{"LU", 1700, 300},
+	{"LV", 2100, 1200},
+	{"MT", 1800, 500},
+	{"NL", 2100, 900},
+	{"PL", 2300, 500},
+	{"PT", 2300, 600},
+	{"RO", 2100, 1100},
+	{"SE", 2500, 600},
+	{"SI", 2200, 500},
+	{"SK", 2300, 500},
+}
+
+// DefaultRates returns the EU VAT rates for every tax class
+func DefaultRates() []models.TaxRate {
+	rates := make([]models.TaxRate, 0, len(euRates)*2)
+	for _, r := range euRates {
+		rates = append(rates,
+			models.TaxRate{Country: r.country, TaxClass: models.TaxClassStandard, Rate: r.standard, Name: "Standard VAT"},
+			models.TaxRate{Country: r.country, TaxClass: models.TaxClassReduced, Rate: r.reduced, Name: "Reduced VAT (books)"},
+		)
+	}
+	return rates
+}
@@ -0,0 +1,298 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRateNotFound    = errors.New("tax rate not found")
+	ErrDuplicateRate   = errors.New("a tax rate already exists for this country and tax class")
+	ErrInvalidCountry  = errors.New("country must be an ISO 3166-1 alpha-2 code")
+	ErrInvalidTaxClass = errors.New("invalid tax class")
+	ErrInvalidRate     = errors.New("rate must be between 0 and 10000 basis points")
+)
+
+// basisPoints is the denominator of rates: 10000 basis points = 100%
+const basisPoints = 10000
+
+// Config holds the tax engine configuration
+type Config struct {
+	PricesIncludeTax bool   // Catalog prices are gross (VAT included) when true
+	OriginCountry    string // Country the shop sells from, used when no destination is known
+}
+
+// Service calculates VAT and manages the tax rate table
+type Service struct {
+	db     *gorm.DB
+	config Config
+}
+
+// NewService creates a new tax service
+func NewService(db *gorm.DB, config Config) *Service {
+	config.OriginCountry = strings.ToUpper(strings.TrimSpace(config.OriginCountry))
+	if config.OriginCountry == "" {
+		config.OriginCountry = "IT"
+	}
+	return &Service{db: db, config: config}
+}
+
+// PricesIncludeTax reports whether catalog prices include VAT
+func (s *Service) PricesIncludeTax() bool {
+	return s.config.PricesIncludeTax
+}
+
+// Line is an amount to tax: a cart or order line total
+type Line struct {
+	TaxClass models.TaxClass
+	Amount   models.Money // Gross when prices include tax, net otherwise
+}
+
+// LineTax is the tax worked out for a single line
+type LineTax struct {
+	TaxClass models.TaxClass `json:"tax_class"`
+	Rate     int64           `json:"rate"`     // Basis points
+	Discount models.Money    `json:"discount"` // Share of the order discount
+	Net      models.Money    `json:"net"`
+	Tax      models.Money    `json:"tax"`
+	Gross    models.Money    `json:"gross"`
+}
+
+// Result is the tax breakdown of a set of lines
+type Result struct {
+	Country          string       `json:"country"`
+	PricesIncludeTax bool         `json:"prices_include_tax"`
+	Lines            []LineTax    `json:"lines"`
+	Subtotal         models.Money `json:"subtotal"` // Sum of the line amounts, as displayed
+	Discount         models.Money `json:"discount"`
+	Net              models.Money `json:"net"`
+	Tax              models.Money `json:"tax"`
+	Total            models.Money `json:"total"` // Amount to charge
+}
+
+// Calculate works out the VAT for the lines shipped to a country.
+// The discount is spread over the lines in proportion to their amount, so VAT is
+// charged on what the customer actually pays. An empty country means the origin country.
+func (s *Service) Calculate(country string, lines []Line, discount models.Money) (*Result, error) {
+	country = s.destination(country)
+	if !isCountryCode(country) {
+		return nil, ErrInvalidCountry
+	}
+
+	rates, err := s.ratesFor(country)
+	if err != nil {
+		return nil, err
+	}
+
+	result := compute(rates, lines, discount, s.config.PricesIncludeTax)
+	result.Country = country
+	return result, nil
+}
+
+// destination normalizes a destination country, falling back to the origin country
+func (s *Service) destination(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return s.config.OriginCountry
+	}
+	return country
+}
+
+// ratesFor loads the rates of a country keyed by tax class
+func (s *Service) ratesFor(country string) (map[models.TaxClass]int64, error) {
+	var rows []models.TaxRate
+	if err := s.db.Where("country = ?", country).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tax rates: %w", err)
+	}
+
+	rates := make(map[models.TaxClass]int64, len(rows))
+	for _, row := range rows {
+		rates[row.TaxClass] = row.Rate
+	}
+	return rates, nil
+}
+
+// compute applies the rates to the lines.
+// A class without a rate uses the standard rate; a country without rates (outside the
+// EU) is not charged VAT.
+func compute(rates map[models.TaxClass]int64, lines []Line, discount models.Money, pricesIncludeTax bool) *Result {
+	currency := discount.Currency
+	subtotal := models.Money{}
+	for _, line := range lines {
+		subtotal = subtotal.Add(line.Amount)
+		if currency == "" {
+			currency = line.Amount.Currency
+		}
+	}
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	zero := models.NewMoney(0, currency)
+	discount = discount.WithCurrency(currency).Min(subtotal.WithCurrency(currency))
+	if discount.IsNegative() {
+		discount = zero
+	}
+
+	result := &Result{
+		PricesIncludeTax: pricesIncludeTax,
+		Lines:            make([]LineTax, len(lines)),
+		Subtotal:         subtotal.WithCurrency(currency),
+		Discount:         discount,
+		Net:              zero,
+		Tax:              zero,
+		Total:            zero,
+	}
+
+	remaining := discount
+	for i, line := range lines {
+		// The last line takes what is left so the shares add up to the discount
+		share := remaining
+		if i < len(lines)-1 {
+			share = discount.MulRatio(line.Amount.Amount, subtotal.Amount)
+			remaining = remaining.Sub(share)
+		}
+		taxable := line.Amount.WithCurrency(currency).Sub(share)
+
+		class := line.TaxClass
+		if class == "" {
+			class = models.TaxClassStandard
+		}
+		rate, ok := rates[class]
+		if !ok {
+			rate = rates[models.TaxClassStandard]
+		}
+
+		lineTax := LineTax{TaxClass: class, Rate: rate, Discount: share}
+		if pricesIncludeTax {
+			lineTax.Gross = taxable
+			lineTax.Tax = taxable.MulRatio(rate, basisPoints+rate)
+			lineTax.Net = taxable.Sub(lineTax.Tax)
+		} else {
+			lineTax.Net = taxable
+			lineTax.Tax = taxable.MulRatio(rate, basisPoints)
+			lineTax.Gross = taxable.Add(lineTax.Tax)
+		}
+
+		result.Lines[i] = lineTax
+		result.Net = result.Net.Add(lineTax.Net)
+		result.Tax = result.Tax.Add(lineTax.Tax)
+		result.Total = result.Total.Add(lineTax.Gross)
+	}
+
+	return result
+}
+
+// ListRates lists the tax rates, optionally for a single country
+func (s *Service) ListRates(country string) ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+
+	query := s.db.Model(&models.TaxRate{})
+	if country != "" {
+		query = query.Where("country = ?", strings.ToUpper(country))
+	}
+
+	if err := query.Order("country ASC, tax_class ASC").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// GetRate retrieves a tax rate by ID
+func (s *Service) GetRate(id uint) (*models.TaxRate, error) {
+	var rate models.TaxRate
+	if err := s.db.First(&rate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRateNotFound
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// CreateRate validates and stores a new tax rate
+func (s *Service) CreateRate(rate *models.TaxRate) error {
+	if err := s.validate(rate); err != nil {
+		return err
+	}
+	return s.db.Create(rate).Error
+}
+
+// UpdateRate validates and saves a modified tax rate
+func (s *Service) UpdateRate(rate *models.TaxRate) error {
+	if err := s.validate(rate); err != nil {
+		return err
+	}
+	return s.db.Save(rate).Error
+}
+
+// DeleteRate deletes a tax rate
+func (s *Service) DeleteRate(id uint) error {
+	result := s.db.Delete(&models.TaxRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRateNotFound
+	}
+	return nil
+}
+
+// validate normalizes a rate and checks it against the table
+func (s *Service) validate(rate *models.TaxRate) error {
+	rate.Country = strings.ToUpper(strings.TrimSpace(rate.Country))
+	if !isCountryCode(rate.Country) {
+		return ErrInvalidCountry
+	}
+	if rate.TaxClass == "" {
+		rate.TaxClass = models.TaxClassStandard
+	}
+	if !rate.TaxClass.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidTaxClass, rate.TaxClass)
+	}
+	if rate.Rate < 0 || rate.Rate > basisPoints {
+		return ErrInvalidRate
+	}
+
+	var count int64
+	if err := s.db.Model(&models.TaxRate{}).
+		Where("country = ? AND tax_class = ? AND id <> ?", rate.Country, rate.TaxClass, rate.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateRate
+	}
+	return nil
+}
+
+// EnsureDefaultRates fills an empty rate table with the EU VAT rates
+func (s *Service) EnsureDefaultRates() error {
+	var count int64
+	if err := s.db.Model(&models.TaxRate{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	rates := DefaultRates()
+	return s.db.Create(&rates).Error
+}
+
+// isCountryCode reports whether s looks like an upper-case ISO 3166-1 alpha-2 code
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+)
+
+var italianRates = map[models.TaxClass]int64{
+	models.TaxClassStandard: 2200,
+	models.TaxClassReduced:  400,
+}
+
+func TestComputeTaxExclusive(t *testing.T) {
+	lines := []Line{
+		{TaxClass: models.TaxClassStandard, Amount: models.EUR(10000)},
+		{TaxClass: models.TaxClassReduced, Amount: models.EUR(1500)},
+	}
+
+	result := compute(italianRates, lines, models.EUR(0), false)
+
+	if result.Lines[0].Tax.Amount != 2200 || result.Lines[1].Tax.Amount != 60 {
+		t.Errorf("line taxes = %d, %d, want 2200, 60", result.Lines[0].Tax.Amount, result.Lines[1].Tax.Amount)
+	}
+	if result.Net.Amount != 11500 || result.Tax.Amount != 2260 || result.Total.Amount != 13760 {
+		t.Errorf("totals = net %d, tax %d, total %d", result.Net.Amount, result.Tax.Amount, result.Total.Amount)
+	}
+}
+
+func TestComputeTaxInclusive(t *testing.T) {
+	lines := []Line{{TaxClass: models.TaxClassStandard, Amount: models.EUR(12200)}}
+
+	result := compute(italianRates, lines, models.EUR(0), true)
+
+	if result.Tax.Amount != 2200 || result.Net.Amount != 10000 || result.Total.Amount != 12200 {
+		t.Errorf("totals = net %d, tax %d, total %d", result.Net.Amount, result.Tax.Amount, result.Total.Amount)
+	}
+}
+
+func TestComputeSpreadsDiscount(t *testing.T) {
+	lines := []Line{
+		{TaxClass: models.TaxClassStandard, Amount: models.EUR(3000)},
+		{TaxClass: models.TaxClassReduced, Amount: models.EUR(1000)},
+	}
+
+	result := compute(italianRates, lines, models.EUR(1000), false)
+
+	if result.Lines[0].Discount.Amount != 750 || result.Lines[1].Discount.Amount != 250 {
+		t.Errorf("discount shares = %d, %d, want 750, 250", result.Lines[0].Discount.Amount, result.Lines[1].Discount.Amount)
+	}
+	// 22% of 22.50 + 4% of 7.50
+	if result.Tax.Amount != 495+30 {
+		t.Errorf("tax = %d, want 525", result.Tax.Amount)
+	}
+	if result.Total.Amount != 3000+525 {
+		t.Errorf("total = %d, want 3525", result.Total.Amount)
+	}
+}
+
+func TestComputeDiscountSharesAddUp(t *testing.T) {
+	lines := []Line{
+		{Amount: models.EUR(333)},
+		{Amount: models.EUR(333)},
+		{Amount: models.EUR(334)},
+	}
+
+	result := compute(italianRates, lines, models.EUR(100), true)
+
+	var sum int64
+	for _, line := range result.Lines {
+		sum += line.Discount.Amount
+	}
+	if sum != 100 {
+		t.Errorf("discount shares add up to %d, want 100", sum)
+	}
+}
+
+func TestComputeFallbacks(t *testing.T) {
+	standardOnly := map[models.TaxClass]int64{models.TaxClassStandard: 2500}
+	lines := []Line{{TaxClass: models.TaxClassReduced, Amount: models.EUR(1000)}}
+
+	if result := compute(standardOnly, lines, models.EUR(0), false); result.Lines[0].Rate != 2500 {
+		t.Errorf("missing class rate = %d, want the standard rate 2500", result.Lines[0].Rate)
+	}
+
+	if result := compute(map[models.TaxClass]int64{}, lines, models.EUR(0), false); !result.Tax.IsZero() {
+		t.Errorf("country without rates charged %d tax, want 0", result.Tax.Amount)
+	}
+}
@@ -0,0 +1,410 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Naim0996/art-management-tool/backend/models"
+	"github.com/Naim0996/art-management-tool/backend/services/order"
+	"github.com/Naim0996/art-management-tool/backend/services/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrEventNotFound = errors.New("webhook event not found")
+	ErrInvalidEvent  = errors.New("invalid webhook event")
+	ErrEventBusy     = errors.New("webhook event is being processed")
+)
+
+// Providers whose webhook events are stored and processed
+const (
+	ProviderStripe = "stripe"
+	ProviderPayPal = "paypal"
+)
+
+// retryBatchSize limits how many failed events one retry run reprocesses
+const retryBatchSize = 50
+
+// staleProcessingAfter is how long an event may stay in processing before it is
+// considered abandoned (e.g. the server stopped mid-processing) and retried
+const staleProcessingAfter = 10 * time.Minute
+
+// RetryPolicy controls how failed events are retried
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 8,
+		BaseDelay:   time.Minute,
+		MaxDelay:    6 * time.Hour,
+	}
+}
+
+// Backoff returns the delay before the next attempt after the given number of attempts.
+// The delay doubles with each attempt, up to MaxDelay.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Service stores payment webhook events and processes them against orders
+type Service struct {
+	db           *gorm.DB
+	orderService *order.Service
+	payments     *payment.Registry
+	policy       RetryPolicy
+	now          func() time.Time
+}
+
+// NewService creates a new webhook service
+func NewService(db *gorm.DB, orderService *order.Service, payments *payment.Registry, policy RetryPolicy) *Service {
+	return &Service{
+		db:           db,
+		orderService: orderService,
+		payments:     payments,
+		policy:       policy,
+		now:          time.Now,
+	}
+}
+
+// envelope holds the fields shared by Stripe and PayPal events
+type envelope struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`       // Stripe
+	EventType string `json:"event_type"` // PayPal
+}
+
+// parseEnvelope extracts the provider event ID and type from a raw event
+func parseEnvelope(payload []byte) (id, eventType string, err error) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	eventType = env.Type
+	if eventType == "" {
+		eventType = env.EventType
+	}
+
+	if env.ID == "" || eventType == "" {
+		return "", "", fmt.Errorf("%w: missing event id or type", ErrInvalidEvent)
+	}
+	return env.ID, eventType, nil
+}
+
+// Receive stores a verified webhook event and processes it.
+// Redelivered events are dropped and reported as duplicates. Processing errors are
+// recorded on the event for the retry worker rather than returned, so callers
+// only need to fail the request when the event could not be stored.
+func (s *Service) Receive(provider string, payload []byte) (event *models.WebhookEvent, duplicate bool, err error) {
+	eventID, eventType, err := parseEnvelope(payload)
+	if err != nil {
+		return nil, false, err
+	}
+
+	event = &models.WebhookEvent{
+		Provider:  provider,
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   string(payload),
+		Status:    models.WebhookEventStatusPending,
+	}
+
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, true, nil
+	}
+
+	if err := s.process(event, models.WebhookEventStatusPending); err != nil {
+		log.Printf("Failed to process %s webhook %s (%s): %v", provider, eventID, eventType, err)
+	}
+
+	return event, false, nil
+}
+
+// RetryFailed reprocesses failed events whose backoff has elapsed.
+// It is meant to run periodically from the scheduler.
+func (s *Service) RetryFailed(ctx context.Context) error {
+	now := s.now()
+
+	// Events left in processing by an interrupted run are retried like failed ones
+	if err := s.db.Model(&models.WebhookEvent{}).
+		Where("status = ? AND updated_at < ?", models.WebhookEventStatusProcessing, now.Add(-staleProcessingAfter)).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookEventStatusFailed,
+			"last_error":      "processing interrupted",
+			"next_attempt_at": now,
+		}).Error; err != nil {
+		return err
+	}
+
+	var events []models.WebhookEvent
+	if err := s.db.Where("status = ? AND next_attempt_at <= ? AND attempts < ?",
+		models.WebhookEventStatusFailed, now, s.policy.MaxAttempts).
+		Order("next_attempt_at ASC").
+		Limit(retryBatchSize).
+		Find(&events).Error; err != nil {
+		return err
+	}
+
+	failures := 0
+	for i := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.process(&events[i], models.WebhookEventStatusFailed); err != nil {
+			failures++
+			log.Printf("Retry of %s webhook %s failed (attempt %d): %v",
+				events[i].Provider, events[i].EventID, events[i].Attempts, err)
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d webhook events failed again", failures, len(events))
+	}
+	return nil
+}
+
+// Replay reprocesses a stored event regardless of its status, for admin use.
+// The processing error, if any, is returned and also recorded on the event.
+func (s *Service) Replay(id uint) (*models.WebhookEvent, error) {
+	event, err := s.GetEvent(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status == models.WebhookEventStatusProcessing {
+		return nil, ErrEventBusy
+	}
+
+	processErr := s.process(event,
+		models.WebhookEventStatusPending,
+		models.WebhookEventStatusProcessed,
+		models.WebhookEventStatusIgnored,
+		models.WebhookEventStatusFailed,
+	)
+	return event, processErr
+}
+
+// process claims the event if it is in one of the given statuses, dispatches it
+// and records the outcome
+func (s *Service) process(event *models.WebhookEvent, from ...models.WebhookEventStatus) error {
+	// Claim the event so the retry worker and a replay never process it concurrently
+	result := s.db.Model(&models.WebhookEvent{}).
+		Where("id = ? AND status IN ?", event.ID, from).
+		Updates(map[string]interface{}{
+			"status":   models.WebhookEventStatusProcessing,
+			"attempts": gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEventBusy
+	}
+	event.Attempts++
+
+	handled, err := s.dispatch(event)
+
+	now := s.now()
+	updates := map[string]interface{}{}
+	switch {
+	case err != nil:
+		event.Status = models.WebhookEventStatusFailed
+		event.LastError = err.Error()
+		event.NextAttemptAt = nil
+		if event.Attempts < s.policy.MaxAttempts {
+			next := now.Add(s.policy.Backoff(event.Attempts))
+			event.NextAttemptAt = &next
+		}
+		updates["last_error"] = event.LastError
+	case handled:
+		event.Status = models.WebhookEventStatusProcessed
+		event.ProcessedAt = &now
+		event.NextAttemptAt = nil
+		event.LastError = ""
+		updates["last_error"] = ""
+	default:
+		event.Status = models.WebhookEventStatusIgnored
+		event.ProcessedAt = &now
+		event.NextAttemptAt = nil
+	}
+	updates["status"] = event.Status
+	updates["processed_at"] = event.ProcessedAt
+	updates["next_attempt_at"] = event.NextAttemptAt
+
+	if saveErr := s.db.Model(&models.WebhookEvent{}).Where("id = ?", event.ID).Updates(updates).Error; saveErr != nil {
+		return fmt.Errorf("failed to record webhook event outcome: %w", saveErr)
+	}
+
+	return err
+}
+
+// dispatch applies an event to orders. It reports false for event types that are not handled.
+func (s *Service) dispatch(event *models.WebhookEvent) (bool, error) {
+	switch event.Provider {
+	case ProviderStripe:
+		return s.dispatchStripe(event)
+	case ProviderPayPal:
+		return s.dispatchPayPal(event)
+	default:
+		return false, fmt.Errorf("%w: unknown provider %s", ErrInvalidEvent, event.Provider)
+	}
+}
+
+// dispatchStripe handles Stripe payment intent events
+func (s *Service) dispatchStripe(event *models.WebhookEvent) (bool, error) {
+	var payload struct {
+		Data struct {
+			Object struct {
+				ID               string `json:"id"`
+				Status           string `json:"status"`
+				LastPaymentError *struct {
+					Message string `json:"message"`
+				} `json:"last_payment_error"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	intent := payload.Data.Object
+
+	switch event.EventType {
+	case "payment_intent.succeeded":
+		return true, s.orderService.HandlePaymentSuccess(ProviderStripe, intent.ID)
+
+	case "payment_intent.payment_failed":
+		reason := "Payment failed"
+		if intent.LastPaymentError != nil && intent.LastPaymentError.Message != "" {
+			reason = intent.LastPaymentError.Message
+		} else if intent.Status != "" {
+			reason = intent.Status
+		}
+		return true, s.orderService.HandlePaymentFailed(ProviderStripe, intent.ID, reason)
+	}
+
+	return false, nil
+}
+
+// dispatchPayPal handles PayPal checkout and capture events.
+// Orders are stored with the PayPal order ID; capture events reference it
+// through supplementary_data.related_ids.order_id.
+func (s *Service) dispatchPayPal(event *models.WebhookEvent) (bool, error) {
+	var payload struct {
+		Resource struct {
+			ID                string `json:"id"`
+			Status            string `json:"status"`
+			SupplementaryData struct {
+				RelatedIDs struct {
+					OrderID string `json:"order_id"`
+				} `json:"related_ids"`
+			} `json:"supplementary_data"`
+		} `json:"resource"`
+	}
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	resource := payload.Resource
+
+	switch event.EventType {
+	case "CHECKOUT.ORDER.APPROVED":
+		// The buyer approved the order: capture it. Completion arrives as PAYMENT.CAPTURE.COMPLETED
+		provider, err := s.payments.ByName(ProviderPayPal)
+		if err != nil {
+			return true, err
+		}
+		return true, provider.ConfirmPayment(resource.ID)
+
+	case "PAYMENT.CAPTURE.COMPLETED":
+		return true, s.orderService.HandlePaymentSuccess(ProviderPayPal, resource.SupplementaryData.RelatedIDs.OrderID)
+
+	case "PAYMENT.CAPTURE.DENIED", "PAYMENT.CAPTURE.DECLINED":
+		reason := "Payment failed"
+		if resource.Status != "" {
+			reason = "Payment capture " + resource.Status
+		}
+		return true, s.orderService.HandlePaymentFailed(ProviderPayPal, resource.SupplementaryData.RelatedIDs.OrderID, reason)
+	}
+
+	return false, nil
+}
+
+// EventFilters represents filters for listing webhook events
+type EventFilters struct {
+	Provider  string
+	Status    models.WebhookEventStatus
+	EventType string
+	Page      int
+	PerPage   int
+}
+
+// DefaultFilters returns default webhook event filters
+func DefaultFilters() *EventFilters {
+	return &EventFilters{
+		Page:    1,
+		PerPage: 50,
+	}
+}
+
+// List lists webhook events with filters, newest first
+func (s *Service) List(filters *EventFilters) ([]models.WebhookEvent, int64, error) {
+	var events []models.WebhookEvent
+	var total int64
+
+	query := s.db.Model(&models.WebhookEvent{})
+
+	if filters.Provider != "" {
+		query = query.Where("provider = ?", filters.Provider)
+	}
+
+	if filters.Status != "" {
+		query = query.Where("status = ?", filters.Status)
+	}
+
+	if filters.EventType != "" {
+		query = query.Where("event_type = ?", filters.EventType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (filters.Page - 1) * filters.PerPage
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(filters.PerPage).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// GetEvent gets a webhook event by ID
+func (s *Service) GetEvent(id uint) (*models.WebhookEvent, error) {
+	var event models.WebhookEvent
+	if err := s.db.First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return &event, nil
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 8, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{20, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantID   string
+		wantType string
+		wantErr  bool
+	}{
+		{"stripe", `{"id":"evt_1","type":"payment_intent.succeeded"}`, "evt_1", "payment_intent.succeeded", false},
+		{"paypal", `{"id":"WH-1","event_type":"PAYMENT.CAPTURE.COMPLETED"}`, "WH-1", "PAYMENT.CAPTURE.COMPLETED", false},
+		{"missing id", `{"type":"payment_intent.succeeded"}`, "", "", true},
+		{"missing type", `{"id":"evt_1"}`, "", "", true},
+		{"not json", `nope`, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, eventType, err := parseEnvelope([]byte(tt.payload))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEvent) {
+					t.Errorf("expected ErrInvalidEvent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tt.wantID || eventType != tt.wantType {
+				t.Errorf("parseEnvelope() = %q, %q, want %q, %q", id, eventType, tt.wantID, tt.wantType)
+			}
+		})
+	}
+}